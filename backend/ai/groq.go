@@ -121,5 +121,5 @@ func (p *GroqProvider) IsAvailable() bool {
 
 // GetModelName returns the model name
 func (p *GroqProvider) GetModelName() string {
-	return "groq-llama-3.3-70b"
+	return GroqModelName
 }
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// ModelInfo describes an AI model by the identifier stored in ai_responses.model_name
+// (what a provider's GetModelName returns)
+type ModelInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"` // Display name
+	Provider string `json:"provider"`
+	Color    string `json:"color"`
+}
+
+// GroqModelName is the stored identifier of the Groq model
+const GroqModelName = "groq-llama-3.3-70b"
+
+// OpenRouterModelName is the stored identifier of an OpenRouter model
+func OpenRouterModelName(modelID string) string {
+	return "openrouter-" + modelID
+}
+
+// knownModels holds the display name and colour of the models the app queries, by stored identifier
+var knownModels = func() map[string]ModelInfo {
+	known := map[string]ModelInfo{}
+	for _, m := range OpenRouterModels {
+		id := OpenRouterModelName(m.ID)
+		known[id] = ModelInfo{ID: id, Name: m.Name, Provider: m.Provider, Color: m.Color}
+	}
+	for _, m := range []ModelInfo{
+		{ID: GroqModelName, Name: "Groq Llama 3.3", Provider: "Groq", Color: "#f55036"},
+		{ID: "gemini-1.5-flash", Name: "Gemini 1.5 Flash", Provider: "Google", Color: "#4285f4"},
+		{ID: OpenRouterModelName("google/gemini-2.0-flash-001"), Name: "Gemini 2.0 Flash", Provider: "Google", Color: "#4285f4"},
+		{ID: "gpt-3.5-turbo", Name: "GPT-3.5 Turbo", Provider: "OpenAI", Color: "#10a37f"},
+		{ID: "gpt-4", Name: "GPT-4", Provider: "OpenAI", Color: "#10a37f"},
+		{ID: "gpt-4-turbo", Name: "GPT-4 Turbo", Provider: "OpenAI", Color: "#10a37f"},
+	} {
+		known[m.ID] = m
+	}
+	return known
+}()
+
+// fallbackColors are assigned to models without a known colour, by a hash of their identifier
+var fallbackColors = []string{"#8b5cf6", "#ec4899", "#14b8a6", "#f59e0b", "#84cc16", "#06b6d4", "#a855f7", "#ef4444"}
+
+// LookupModel returns the display information of a stored model identifier. Unknown models
+// are shown under their identifier with a colour that stays the same across calls.
+func LookupModel(modelName string) ModelInfo {
+	if info, ok := knownModels[modelName]; ok {
+		return info
+	}
+
+	info := ModelInfo{ID: modelName, Name: modelName, Provider: "Unknown"}
+	if strings.HasPrefix(modelName, "ollama/") {
+		info.Provider = "Ollama"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(modelName))
+	info.Color = fallbackColors[h.Sum32()%uint32(len(fallbackColors))]
+	return info
+}
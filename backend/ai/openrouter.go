@@ -142,7 +142,7 @@ func (p *OpenRouterProvider) IsAvailable() bool {
 
 // GetModelName returns the model name
 func (p *OpenRouterProvider) GetModelName() string {
-	return "openrouter-" + p.model
+	return OpenRouterModelName(p.model)
 }
 
 // GetAPIKey returns the API key (for multi-model comparison service)
@@ -0,0 +1,64 @@
+// Command mention-eval runs the mention detector over the labelled corpus and reports
+// precision/recall/F1 per field. It exits with status 1 when a field's F1 falls below
+// the baseline, so detector changes can be gated in CI.
+//
+// Usage (from backend/):
+//
+//	go run ./cmd/mention-eval [-corpus path] [-baseline path] [-errors] [-json]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/services"
+)
+
+func main() {
+	corpusPath := flag.String("corpus", "services/testdata/mention_eval/corpus.json", "labelled corpus (JSON array of cases)")
+	baselinePath := flag.String("baseline", "services/testdata/mention_eval/baseline.json", "minimum F1 per field; empty disables the gate")
+	tolerance := flag.Float64("tolerance", 0.001, "allowed F1 drop below the baseline")
+	showErrors := flag.Bool("errors", false, "list every disagreement with the labels")
+	asJSON := flag.Bool("json", false, "print the full report as JSON")
+	flag.Parse()
+
+	cases, err := services.LoadEvalCorpus(*corpusPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to load corpus: %v", err)
+	}
+
+	report := services.EvaluateMentionDetector(cases)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("❌ Failed to encode report: %v", err)
+		}
+	} else {
+		fmt.Print(report.Summary())
+		if *showErrors {
+			fmt.Println()
+			for _, e := range report.Errors {
+				fmt.Printf("%s: %s %s expected=%s got=%s\n", e.CaseID, e.Field, e.Entity, e.Expected, e.Got)
+			}
+		}
+	}
+
+	if *baselinePath == "" {
+		return
+	}
+	baseline, err := services.LoadEvalBaseline(*baselinePath)
+	if err != nil {
+		log.Fatalf("❌ Failed to load baseline: %v", err)
+	}
+	if failures := report.Regressions(baseline, *tolerance); len(failures) > 0 {
+		for _, f := range failures {
+			fmt.Fprintf(os.Stderr, "❌ Regression: %s\n", f)
+		}
+		os.Exit(1)
+	}
+}
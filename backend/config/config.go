@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds all configuration for the application
@@ -18,6 +20,18 @@ type Config struct {
 	GeminiKey     string
 	GroqKey       string
 	OpenRouterKey string
+
+	// ClassifierProvider selects the provider used for LLM-as-judge mention
+	// classification ("gemini", "groq", "openai", "openrouter", "ollama").
+	// Empty disables the classifier and only rule-based labels are stored.
+	ClassifierProvider string
+
+	// Background scheduler for brands' scheduled analyses and email alerts. Only one instance
+	// sharing a database may run it: at startup it fails every run still queued or running.
+	SchedulerEnabled     bool
+	SchedulerConcurrency int           // Scheduled analyses running at once
+	SchedulerMaxJitter   time.Duration // Upper bound of each brand's start delay, spreading runs due together
+	SchedulerDrainTime   time.Duration // How long shutdown waits for in-progress runs
 }
 
 // Load reads configuration from environment variables
@@ -35,6 +49,13 @@ func Load() *Config {
 		GeminiKey:     getEnv("GEMINI_API_KEY", ""),
 		GroqKey:       getEnv("GROQ_API_KEY", ""),
 		OpenRouterKey: getEnv("OPENROUTER_API_KEY", ""),
+
+		ClassifierProvider: getEnv("CLASSIFIER_PROVIDER", ""),
+
+		SchedulerEnabled:     getEnvBool("SCHEDULER_ENABLED", true),
+		SchedulerConcurrency: getEnvInt("SCHEDULER_CONCURRENCY", 2),
+		SchedulerMaxJitter:   getEnvDuration("SCHEDULER_MAX_JITTER", 2*time.Minute),
+		SchedulerDrainTime:   getEnvDuration("SCHEDULER_DRAIN_TIMEOUT", 5*time.Minute),
 	}
 }
 
@@ -50,3 +71,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool retrieves a boolean environment variable ("true", "1", "false"...) or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if v, err := strconv.ParseBool(getEnv(key, "")); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+// getEnvInt retrieves a positive integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if v, err := strconv.Atoi(getEnv(key, "")); err == nil && v > 0 {
+		return v
+	}
+	return defaultValue
+}
+
+// getEnvDuration retrieves a duration environment variable ("90s", "5m") or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if v, err := time.ParseDuration(getEnv(key, "")); err == nil && v >= 0 {
+		return v
+	}
+	return defaultValue
+}
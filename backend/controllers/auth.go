@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"os"
 	"strings"
@@ -226,3 +227,22 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMiddleware requires the X-Admin-Token header to match ADMIN_TOKEN.
+// Admin routes are disabled when ADMIN_TOKEN is not set.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminToken := os.Getenv("ADMIN_TOKEN")
+		if adminToken == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin endpoints are disabled"})
+			c.Abort()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(adminToken)) != 1 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid admin token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
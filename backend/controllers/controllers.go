@@ -1,7 +1,11 @@
 package controllers
 
 import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -10,6 +14,7 @@ import (
 
 	"github.com/Sneh16Shah/ai-visibility-tracker/db"
 	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
 	"github.com/Sneh16Shah/ai-visibility-tracker/services"
 	"github.com/gin-gonic/gin"
 )
@@ -214,152 +219,1337 @@ func RemoveCompetitor(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Competitor removed successfully"})
 }
 
+// GetSuggestedCompetitors returns untracked names that AI responses list next to the brand.
+// Accepting one is a regular AddCompetitor call with the suggested name.
+func GetSuggestedCompetitors(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	discovery := services.NewCompetitorDiscovery()
+	suggestions, err := discovery.SuggestCompetitors(brandID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to discover competitors", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
+// ============================================
+// Alias Controllers
+// ============================================
+
+// GetAliases returns all aliases for a brand
+func GetAliases(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	repo := db.NewBrandRepository()
+	aliases, err := repo.GetAliases(brandID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch aliases", "details": err.Error()})
+		return
+	}
+
+	if aliases == nil {
+		aliases = []models.BrandAlias{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+}
+
+// AddAlias adds an alias to a brand
+func AddAlias(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.AddAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	repo := db.NewBrandRepository()
+	alias, err := repo.AddAlias(brandID, req.Alias)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add alias", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// RemoveAlias removes an alias
+func RemoveAlias(c *gin.Context) {
+	aliasID, err := strconv.Atoi(c.Param("aliasId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias ID"})
+		return
+	}
+
+	repo := db.NewBrandRepository()
+	if err := repo.RemoveAlias(aliasID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove alias", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alias removed successfully"})
+}
+
+// GetProducts returns all products tracked under a brand
+func GetProducts(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	repo := db.NewProductRepository()
+	products, err := repo.GetByBrandID(brandID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products", "details": err.Error()})
+		return
+	}
+
+	if products == nil {
+		products = []models.Product{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"products": products})
+}
+
+// AddProduct adds a product (with optional aliases) to a brand
+func AddProduct(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.AddProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	repo := db.NewProductRepository()
+	product, err := repo.Create(brandID, req.Name, req.Aliases)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, product)
+}
+
+// RemoveProduct removes a product from a brand
+func RemoveProduct(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	repo := db.NewProductRepository()
+	if err := repo.Delete(brandID, productID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove product", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product removed successfully"})
+}
+
+// AddProductAlias adds an alias to a product
+func AddProductAlias(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req models.AddAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	repo := db.NewProductRepository()
+	alias, err := repo.AddAlias(productID, req.Alias)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product alias", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// RemoveProductAlias removes an alias from a product
+func RemoveProductAlias(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	aliasID, err := strconv.Atoi(c.Param("aliasId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias ID"})
+		return
+	}
+
+	repo := db.NewProductRepository()
+	if err := repo.RemoveAlias(productID, aliasID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove product alias", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product alias removed successfully"})
+}
+
+// GetProductMetrics returns per-product visibility scores and trends for a brand
+func GetProductMetrics(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	products, err := metricsCalc.GetProductMetrics(brandID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product metrics", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"products": products, "days": days})
+}
+
+// GetCompetitorMetrics returns each competitor's composite score trend
+func GetCompetitorMetrics(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	competitors, err := metricsCalc.GetCompetitorTrends(brandID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch competitor metrics", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"competitors": competitors, "days": days})
+}
+
+// GetModelMetrics returns each AI model's score trend
+func GetModelMetrics(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	trends, err := metricsCalc.GetModelTrends(brandID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch model metrics", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"models": trends, "days": days})
+}
+
+// GetCategoryDrilldown returns the brand's metrics and trend within each prompt category
+func GetCategoryDrilldown(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	categories, err := metricsCalc.GetCategoryDrilldown(brandID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category metrics", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories, "days": days})
+}
+
+// GetPromptDrilldown returns the brand's metrics and trend within each prompt, optionally of one category
+func GetPromptDrilldown(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	prompts, err := metricsCalc.GetPromptDrilldown(brandID, days, c.Query("category"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch prompt metrics", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"prompts": prompts, "days": days})
+}
+
+// GetPromptResponses returns the stored AI responses to one prompt with their mentions.
+// Responses are replaced on every run, so these are the latest run's.
+func GetPromptResponses(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+	promptID, err := strconv.Atoi(c.Param("promptId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prompt ID"})
+		return
+	}
+
+	repo := db.NewAIResponseRepository()
+	responses, err := repo.GetByBrandAndPromptID(brandID, promptID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch responses", "details": err.Error()})
+		return
+	}
+
+	if responses == nil {
+		responses = []models.AIResponse{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"responses": responses})
+}
+
+// GetVisibilityRanking ranks the brand and its competitors by composite score in the latest run
+func GetVisibilityRanking(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	ranking, err := metricsCalc.GetVisibilityRanking(brandID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No metrics found for brand", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+// GetScoreChange reports whether the score change between two snapshots is statistically
+// significant. Without from/to snapshot IDs the latest snapshot is compared with the previous one.
+func GetScoreChange(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	fromID, _ := strconv.Atoi(c.Query("from"))
+	toID, _ := strconv.Atoi(c.Query("to"))
+	if (fromID == 0) != (toID == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both from and to snapshot IDs are required"})
+		return
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	change, err := metricsCalc.CompareSnapshots(brandID, fromID, toID)
+	if errors.Is(err, services.ErrSnapshotNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not enough snapshots to compare", "details": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare snapshots", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// GetPeriodReport compares a brand's metrics between a period and the one before it.
+// period=week|month|quarter picks the calendar period containing date (default today);
+// from/to pick a custom range instead. format=csv or format=json downloads the report.
+func GetPeriodReport(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	period := c.DefaultQuery("period", models.PeriodMonth)
+	var current, previous models.ReportPeriod
+	if c.Query("from") != "" || c.Query("to") != "" {
+		period = models.PeriodCustom
+		from, fromErr := parseQueryTime(c.Query("from"), false)
+		to, toErr := parseQueryTime(c.Query("to"), true)
+		if err = errors.Join(fromErr, toErr); err == nil {
+			current, previous, err = services.CustomReportPeriods(from, to)
+		}
+	} else {
+		date := time.Now()
+		if value := c.Query("date"); value != "" {
+			date, err = parseQueryTime(value, false)
+		}
+		if err == nil {
+			current, previous, err = services.ReportPeriods(period, date)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report period", "details": err.Error()})
+		return
+	}
+
+	format := c.Query("format")
+	if format != "" && format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+		return
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	report, err := metricsCalc.GeneratePeriodReport(brandID, period, current, previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build period report", "details": err.Error()})
+		return
+	}
+
+	if format == "" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	filename := fmt.Sprintf("%s_%s_report_%s_vs_%s.%s", report.BrandName, period,
+		current.From.Format("2006-01-02"), previous.From.Format("2006-01-02"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "json" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	var csvContent strings.Builder
+	if err := writePeriodReportCSV(&csvContent, report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write CSV", "details": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.String(http.StatusOK, csvContent.String())
+}
+
+// writePeriodReportCSV writes one row per compared metric: the brand's, then each competitor's and model's
+func writePeriodReportCSV(w io.Writer, report *models.PeriodReport) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"Section", "Subject", "Metric", "Previous", "Current", "Delta", "Relative Delta", "P-Value", "Significant"})
+
+	row := func(section, subject string, d models.MetricDelta) {
+		relative, pValue := "", ""
+		if d.RelativeDelta != nil {
+			relative = strconv.FormatFloat(*d.RelativeDelta, 'f', 4, 64)
+		}
+		if d.PValue != nil {
+			pValue = strconv.FormatFloat(*d.PValue, 'f', 4, 64)
+		}
+		out.Write([]string{
+			section, subject, d.Metric,
+			strconv.FormatFloat(d.Previous, 'f', 4, 64),
+			strconv.FormatFloat(d.Current, 'f', 4, 64),
+			strconv.FormatFloat(d.Delta, 'f', 4, 64),
+			relative, pValue, strconv.FormatBool(d.Significant),
+		})
+	}
+
+	for _, d := range report.Metrics {
+		row("brand", report.BrandName, d)
+	}
+	for _, comp := range report.Competitors {
+		row("competitor", comp.CompetitorName, comp.ShareOfVoice)
+		row("competitor", comp.CompetitorName, comp.VisibilityScore)
+	}
+	for _, m := range report.Models {
+		row("model", m.ModelName, m.VisibilityScore)
+		row("model", m.ModelName, m.MentionRate)
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// GetForecast projects a brand's weekly visibility score for the next N weeks
+func GetForecast(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+	weeks, _ := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(services.DefaultForecastWeeks)))
+	history, _ := strconv.Atoi(c.DefaultQuery("history", strconv.Itoa(services.DefaultForecastHistoryWeeks)))
+
+	metricsCalc := services.NewMetricsCalculator()
+	forecast, err := metricsCalc.ForecastVisibility(brandID, weeks, history)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidForecast) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid forecast", "details": err.Error()})
+		return
+	}
+	if errors.Is(err, scoring.ErrInsufficientHistory) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Not enough history to forecast", "details": fmt.Sprintf("needs runs in at least %d different weeks", scoring.MinForecastPoints)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build forecast", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, forecast)
+}
+
+// GetBrandBenchmark returns a brand's percentile within the benchmark of its industry
+func GetBrandBenchmark(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	benchmark, err := metricsCalc.GetBrandBenchmark(brandID, getUserID(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+	if errors.Is(err, services.ErrNoIndustry) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set the brand's industry to benchmark it"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build benchmark", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, benchmark)
+}
+
+// GetIndustryBenchmark returns the anonymized score distribution of an industry
+func GetIndustryBenchmark(c *gin.Context) {
+	metricsCalc := services.NewMetricsCalculator()
+	benchmark, err := metricsCalc.GetIndustryBenchmark(c.Query("industry"), getUserID(c))
+	if errors.Is(err, services.ErrNoIndustry) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "industry is required"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build benchmark", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, benchmark)
+}
+
+// GetScheduledRuns lists the next scheduled analyses and the outcomes of the latest ones
+func GetScheduledRuns(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	overview, err := services.GetScheduleOverview(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled runs", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// GetPortfolio returns the latest standing of every brand the user owns as a sortable leaderboard
+func GetPortfolio(c *gin.Context) {
+	userID := getUserID(c)
+	sortBy := c.DefaultQuery("sort", services.PortfolioSortScore)
+	order := c.DefaultQuery("order", "desc")
+	if order != "asc" && order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+	format := c.Query("format")
+	if format != "" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv"})
+		return
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	entries, err := metricsCalc.GetPortfolio(userID, c.Query("industry"), sortBy, order == "desc")
+	if errors.Is(err, services.ErrInvalidPortfolioSort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort", "details": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build portfolio", "details": err.Error()})
+		return
+	}
+
+	if format == "" {
+		c.JSON(http.StatusOK, gin.H{"brands": entries, "count": len(entries)})
+		return
+	}
+
+	var csvContent strings.Builder
+	if err := writePortfolioCSV(&csvContent, entries); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write CSV", "details": err.Error()})
+		return
+	}
+	filename := fmt.Sprintf("portfolio_%s.csv", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv")
+	c.String(http.StatusOK, csvContent.String())
+}
+
+// writePortfolioCSV writes one row per brand in leaderboard order
+func writePortfolioCSV(w io.Writer, entries []models.PortfolioEntry) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"Rank", "Brand", "Industry", "Visibility Score", "Week Change", "Confidence",
+		"Top Competitor", "Top Competitor Score", "Alert Threshold", "Open Anomalies", "Alert State", "Last Run"})
+
+	for i, e := range entries {
+		change, lastRun := "", ""
+		if e.WeekChange != nil {
+			change = strconv.FormatFloat(*e.WeekChange, 'f', 2, 64)
+		}
+		if e.LastRunAt != nil {
+			lastRun = e.LastRunAt.Format(time.RFC3339)
+		}
+		out.Write([]string{
+			strconv.Itoa(i + 1), e.BrandName, e.Industry,
+			strconv.FormatFloat(e.VisibilityScore, 'f', 2, 64),
+			change, e.ConfidenceLevel,
+			e.TopCompetitor, strconv.FormatFloat(e.TopCompetitorScore, 'f', 2, 64),
+			strconv.FormatFloat(e.AlertThreshold, 'f', 2, 64),
+			strconv.Itoa(e.OpenAnomalies), e.AlertState, lastRun,
+		})
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// UpdateAlertSettings updates alert threshold, forecast alerts and the analysis schedule
+// (disabled, daily, weekly or a cron expression in an IANA time zone) of a brand
+func UpdateAlertSettings(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.AlertSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+	if req.ForecastAlertWeeks < 0 || req.ForecastAlertWeeks > services.MaxForecastWeeks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("forecast_alert_weeks must be between 0 and %d", services.MaxForecastWeeks)})
+		return
+	}
+	if err := services.NormalizeSchedule(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule", "details": err.Error()})
+		return
+	}
+
+	repo := db.NewBrandRepository()
+	brand, err := repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+	brand.ScheduleFrequency, brand.ScheduleCron, brand.ScheduleTimezone = req.ScheduleFrequency, req.ScheduleCron, req.ScheduleTimezone
+	nextRunAt, err := services.NextScheduledRun(*brand, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule", "details": err.Error()})
+		return
+	}
+
+	if err := repo.UpdateAlertSettings(id, req, nextRunAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert settings updated successfully", "settings": req, "next_run_at": nextRunAt})
+}
+
+// UpdateSentimentSource chooses whether rule-based or LLM-classified labels drive the score
+func UpdateSentimentSource(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.UpdateSentimentSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	if req.SentimentSource != services.SentimentSourceRules && req.SentimentSource != services.SentimentSourceLLM {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sentiment_source must be 'rules' or 'llm'"})
+		return
+	}
+
+	repo := db.NewBrandRepository()
+	if err := repo.UpdateSentimentSource(brandID, req.SentimentSource); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sentiment source", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sentiment source updated successfully"})
+}
+
+// GetScoringProfile returns the brand's active scoring profile and its saved versions
+func GetScoringProfile(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	active, err := services.GetScoringProfile(brandID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scoring profile", "details": err.Error()})
+		return
+	}
+
+	versions, err := db.NewScoringProfileRepository().GetVersions(brandID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scoring profile versions", "details": err.Error()})
+		return
+	}
+	if versions == nil {
+		versions = []models.ScoringProfile{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"active": active, "versions": versions})
+}
+
+// UpdateScoringProfile saves a new scoring profile version, optionally rescoring history with it
+func UpdateScoringProfile(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.ScoringProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	if _, err := db.NewBrandRepository().GetByID(brandID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	profile, err := services.SaveScoringProfile(brandID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidScoringProfile) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save scoring profile", "details": err.Error()})
+		return
+	}
+
+	response := gin.H{"profile": profile}
+	if req.RecomputeHistory {
+		report, err := services.NewMetricsCalculator().RescoreHistory(brandID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Profile saved but rescoring history failed", "details": err.Error()})
+			return
+		}
+		response["rescore"] = report
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RescoreHistory recomputes a brand's stored snapshots under its active scoring profile
+func RescoreHistory(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	if _, err := db.NewBrandRepository().GetByID(brandID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	report, err := services.NewMetricsCalculator().RescoreHistory(brandID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rescore history", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ============================================
+// Insights Controllers
+// ============================================
+
+// GetInsights returns saved competitor insights for a brand
+func GetInsights(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	repo := db.NewBrandRepository()
+	insights, updatedAt, err := repo.GetInsights(brandID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"insights":   insights,
+		"updated_at": updatedAt,
+	})
+}
+
+// SaveInsights saves competitor insights for a brand
+func SaveInsights(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.UpdateInsightsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	repo := db.NewBrandRepository()
+	if err := repo.SaveInsights(brandID, req.Insights); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save insights", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Insights saved successfully"})
+}
+
+// GetAspectSentiment returns aspect-level sentiment per entity over time for a brand
+func GetAspectSentiment(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	points, err := metricsCalc.GetAspectSentiment(brandID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch aspect sentiment", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aspects": points, "days": days})
+}
+
+// GetCitedDomains returns the top cited domains for a brand and its competitors
+func GetCitedDomains(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 {
+		limit = 10
+	}
+
+	metricsCalc := services.NewMetricsCalculator()
+	report, err := metricsCalc.GetCitedDomains(brandID, days, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cited domains", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// BackfillMentions re-runs mention detection over a brand's stored responses
+func BackfillMentions(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.BackfillRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+			return
+		}
+	}
+
+	var from, to time.Time
+	if req.From != "" {
+		if from, err = time.Parse("2006-01-02", req.From); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if req.To != "" {
+		if to, err = time.Parse("2006-01-02", req.To); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		to = to.Add(24*time.Hour - time.Nanosecond) // Include the whole day
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	if _, err := db.NewBrandRepository().GetByID(brandID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	report, err := services.NewMentionBackfill().Run(c.Request.Context(), brandID, from, to, req.DryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backfill failed", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // ============================================
-// Alias Controllers
+// Fact Sheet Controllers
 // ============================================
 
-// GetAliases returns all aliases for a brand
-func GetAliases(c *gin.Context) {
+// GetBrandFacts returns the verified fact sheet for a brand
+func GetBrandFacts(c *gin.Context) {
 	brandID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
 		return
 	}
 
-	repo := db.NewBrandRepository()
-	aliases, err := repo.GetAliases(brandID)
+	repo := db.NewFactRepository()
+	facts, err := repo.GetByBrandID(brandID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch aliases", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facts", "details": err.Error()})
 		return
 	}
 
-	if aliases == nil {
-		aliases = []models.BrandAlias{}
+	if facts == nil {
+		facts = []models.BrandFact{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+	c.JSON(http.StatusOK, gin.H{"facts": facts})
 }
 
-// AddAlias adds an alias to a brand
-func AddAlias(c *gin.Context) {
+// AddBrandFact adds a verified fact to a brand's fact sheet
+func AddBrandFact(c *gin.Context) {
 	brandID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
 		return
 	}
 
-	var req models.AddAliasRequest
+	var req models.BrandFactRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
 		return
 	}
 
-	repo := db.NewBrandRepository()
-	alias, err := repo.AddAlias(brandID, req.Alias)
+	if !services.IsValidFactType(req.FactType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fact_type must be one of pricing, feature, integration, headquarters, founded"})
+		return
+	}
+
+	repo := db.NewFactRepository()
+	fact, err := repo.Create(brandID, req.FactType, req.Label, req.Value)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add alias", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add fact", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, alias)
+	c.JSON(http.StatusCreated, fact)
 }
 
-// RemoveAlias removes an alias
-func RemoveAlias(c *gin.Context) {
-	aliasID, err := strconv.Atoi(c.Param("aliasId"))
+// UpdateBrandFact updates a verified fact
+func UpdateBrandFact(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
 		return
 	}
 
-	repo := db.NewBrandRepository()
-	if err := repo.RemoveAlias(aliasID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove alias", "details": err.Error()})
+	factID, err := strconv.Atoi(c.Param("factId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fact ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Alias removed successfully"})
+	var req models.BrandFactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	if !services.IsValidFactType(req.FactType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fact_type must be one of pricing, feature, integration, headquarters, founded"})
+		return
+	}
+
+	repo := db.NewFactRepository()
+	fact, err := repo.Update(brandID, factID, req.FactType, req.Label, req.Value)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fact not found", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fact)
 }
 
-// UpdateAlertSettings updates alert threshold and schedule frequency for a brand
-func UpdateAlertSettings(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+// DeleteBrandFact removes a verified fact
+func DeleteBrandFact(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
 		return
 	}
 
-	var req struct {
-		AlertThreshold    float64 `json:"alert_threshold"`
-		ScheduleFrequency string  `json:"schedule_frequency"`
+	factID, err := strconv.Atoi(c.Param("factId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fact ID"})
+		return
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+
+	repo := db.NewFactRepository()
+	if err := repo.Delete(brandID, factID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete fact", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fact deleted successfully"})
+}
+
+// GetMisinformationFindings returns claims in AI responses that contradict the fact sheet
+func GetMisinformationFindings(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
 		return
 	}
 
-	// Validate schedule frequency
-	validFrequencies := map[string]bool{"disabled": true, "daily": true, "weekly": true}
-	if !validFrequencies[req.ScheduleFrequency] {
-		req.ScheduleFrequency = "disabled"
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
 	}
 
-	repo := db.NewBrandRepository()
-	if err := repo.UpdateAlertSettings(id, req.AlertThreshold, req.ScheduleFrequency); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings", "details": err.Error()})
+	repo := db.NewFactRepository()
+	findings, err := repo.GetFindings(brandID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch misinformation findings", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Alert settings updated successfully"})
+	if findings == nil {
+		findings = []models.MisinformationFinding{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"findings": findings, "days": days})
 }
 
-// ============================================
-// Insights Controllers
-// ============================================
+// GetAnomalies lists a brand's detected anomalies over the last N days.
+// status=open (default) leaves out acknowledged anomalies; status=all includes them.
+func GetAnomalies(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
 
-// GetInsights returns saved competitor insights for a brand
-func GetInsights(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	status := c.DefaultQuery("status", "open")
+	if status != "open" && status != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open or all"})
+		return
+	}
+
+	repo := db.NewAnomalyRepository()
+	anomalies, err := repo.GetByBrandID(brandID, time.Now().AddDate(0, 0, -days), status == "open")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch anomalies", "details": err.Error()})
+		return
+	}
+
+	if anomalies == nil {
+		anomalies = []models.Anomaly{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"anomalies": anomalies, "days": days, "status": status})
+}
+
+// AcknowledgeAnomaly marks an anomaly as seen
+func AcknowledgeAnomaly(c *gin.Context) {
 	brandID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
 		return
 	}
 
-	repo := db.NewBrandRepository()
-	insights, updatedAt, err := repo.GetInsights(brandID)
+	anomalyID, err := strconv.Atoi(c.Param("anomalyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly ID"})
+		return
+	}
+
+	repo := db.NewAnomalyRepository()
+	anomaly, err := repo.Acknowledge(brandID, anomalyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anomaly not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to acknowledge anomaly", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+// GetAnnotations returns a brand's timeline annotations, optionally within from/to
+func GetAnnotations(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	repo := db.NewAnnotationRepository()
+	var annotations []models.Annotation
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to := time.Time{}, time.Now()
+		var fromErr, toErr error
+		if value := c.Query("from"); value != "" {
+			from, fromErr = parseQueryTime(value, false)
+		}
+		if value := c.Query("to"); value != "" {
+			to, toErr = parseQueryTime(value, true)
+		}
+		if err := errors.Join(fromErr, toErr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid range", "details": err.Error()})
+			return
+		}
+		annotations, err = repo.GetBetween(brandID, from, to)
+	} else {
+		annotations, err = repo.GetByBrandID(brandID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch annotations", "details": err.Error()})
+		return
+	}
+
+	if annotations == nil {
+		annotations = []models.Annotation{}
+	}
+	c.JSON(http.StatusOK, gin.H{"annotations": annotations, "count": len(annotations)})
+}
+
+// CreateAnnotation marks a marketing event on a brand's timeline
+func CreateAnnotation(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	var req models.AnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+	annotation, err := services.NewAnnotation(brandID, req)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid annotation", "details": err.Error()})
+		return
+	}
+
+	if _, err := db.NewBrandRepository().GetByID(brandID); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"insights":   insights,
-		"updated_at": updatedAt,
-	})
+	created, err := db.NewAnnotationRepository().Create(annotation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create annotation", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
 }
 
-// SaveInsights saves competitor insights for a brand
-func SaveInsights(c *gin.Context) {
+// UpdateAnnotation replaces an annotation's date, title, type and URL
+func UpdateAnnotation(c *gin.Context) {
 	brandID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
 		return
 	}
 
-	var req models.UpdateInsightsRequest
+	annotationID, err := strconv.Atoi(c.Param("annotationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid annotation ID"})
+		return
+	}
+
+	var req models.AnnotationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
 		return
 	}
+	annotation, err := services.NewAnnotation(brandID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid annotation", "details": err.Error()})
+		return
+	}
+	annotation.ID = annotationID
 
-	repo := db.NewBrandRepository()
-	if err := repo.SaveInsights(brandID, req.Insights); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save insights", "details": err.Error()})
+	updated, err := db.NewAnnotationRepository().Update(annotation)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Annotation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update annotation", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Insights saved successfully"})
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteAnnotation removes an annotation from a brand's timeline
+func DeleteAnnotation(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+
+	annotationID, err := strconv.Atoi(c.Param("annotationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid annotation ID"})
+		return
+	}
+
+	err = db.NewAnnotationRepository().Delete(brandID, annotationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Annotation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete annotation", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Annotation deleted successfully"})
+}
+
+// GetAnnotationImpact compares the brand's metrics in the window before and after each annotation
+func GetAnnotationImpact(c *gin.Context) {
+	brandID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid brand ID"})
+		return
+	}
+	window, _ := strconv.Atoi(c.DefaultQuery("window", strconv.Itoa(services.DefaultImpactWindowDays)))
+
+	metricsCalc := services.NewMetricsCalculator()
+	impacts, err := metricsCalc.GetAnnotationImpact(brandID, window)
+	if errors.Is(err, services.ErrInvalidAnnotation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid impact window", "details": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build impact report", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"impacts": impacts, "window_days": window})
 }
 
 // ============================================
@@ -616,25 +1806,78 @@ func RunCompareModels(c *gin.Context) {
 // Metrics Controllers
 // ============================================
 
-// GetMetrics returns metrics for a brand
+// parseMetricsQuery reads the time range, granularity, aggregation and filter of a metrics
+// request. from/to take YYYY-MM-DD (to inclusive) or RFC3339; without them the range is the
+// last "days" days, defaultDays unless given.
+func parseMetricsQuery(c *gin.Context, defaultDays int) (models.MetricsQuery, error) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultDays)))
+	if err != nil || days <= 0 {
+		return models.MetricsQuery{}, fmt.Errorf("%w: days must be a positive number", services.ErrInvalidMetricsQuery)
+	}
+	q := services.NewMetricsQuery(days)
+	q.Granularity = c.DefaultQuery("granularity", models.GranularityRun)
+	q.Aggregation = c.DefaultQuery("aggregation", models.AggregationLast)
+	q.Model = c.Query("model")
+	q.Category = c.Query("category")
+
+	if from := c.Query("from"); from != "" {
+		if q.From, err = parseQueryTime(from, false); err != nil {
+			return models.MetricsQuery{}, fmt.Errorf("%w: from: %v", services.ErrInvalidMetricsQuery, err)
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if q.To, err = parseQueryTime(to, true); err != nil {
+			return models.MetricsQuery{}, fmt.Errorf("%w: to: %v", services.ErrInvalidMetricsQuery, err)
+		}
+	}
+	return q, services.ValidateMetricsQuery(q)
+}
+
+// parseQueryTime parses an RFC3339 time or a local YYYY-MM-DD date, which endOfDay
+// extends to the last instant of that day
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", value)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
+
+// GetMetrics returns a brand's metric series for a time range, granularity, aggregation
+// and optional model or prompt category filter
 func GetMetrics(c *gin.Context) {
 	brandID, _ := strconv.Atoi(c.Query("brand_id"))
 	if brandID == 0 {
 		brandID = 1 // Default for demo
 	}
 
-	repo := db.NewMetricRepository()
-	metrics, err := repo.GetTrendsByBrandID(brandID, 30)
+	q, err := parseMetricsQuery(c, 30)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch metrics", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid metrics query", "details": err.Error()})
 		return
 	}
 
-	if metrics == nil {
-		metrics = []models.MetricSnapshot{}
+	metrics, err := services.NewMetricsCalculator().QueryMetrics(brandID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch metrics", "details": err.Error()})
+		return
+	}
+	annotations, err := db.NewAnnotationRepository().GetBetween(brandID, q.From, q.To)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch annotations", "details": err.Error()})
+		return
+	}
+	if annotations == nil {
+		annotations = []models.Annotation{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"metrics": metrics})
+	c.JSON(http.StatusOK, gin.H{"metrics": metrics, "annotations": annotations, "query": q})
 }
 
 // GetDashboardData returns aggregated dashboard data
@@ -644,11 +1887,17 @@ func GetDashboardData(c *gin.Context) {
 		brandID = 1 // Default for demo
 	}
 
+	q, err := parseMetricsQuery(c, 7)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid metrics query", "details": err.Error()})
+		return
+	}
+
 	log.Printf("📊 GetDashboardData: Fetching data for brand %d", brandID)
 
 	// Use the full metrics calculator to get all dashboard data including model visibility
 	metricsCalc := services.NewMetricsCalculator()
-	dashboardData, err := metricsCalc.GetDashboardMetrics(brandID)
+	dashboardData, err := metricsCalc.GetDashboardMetrics(brandID, q)
 	if err != nil {
 		log.Printf("📊 GetDashboardData: Error getting metrics: %v", err)
 		c.JSON(http.StatusOK, getDemoData())
@@ -677,7 +1926,8 @@ func getDemoData() models.DashboardData {
 		CitationShare:     0,
 		TotalMentions:     0,
 		SentimentScore:    0,
-		Trends:            []models.MetricSnapshot{},
+		Trends:            []models.MetricPoint{},
+		Annotations:       []models.Annotation{},
 		CitationBreakdown: []models.CitationBreakdown{},
 		CompetitorData:    []models.CompetitorMetrics{},
 	}
@@ -709,9 +1959,13 @@ func ExportCSV(c *gin.Context) {
 		return
 	}
 
-	// Get metrics history (up to 365 days)
-	metricsRepo := db.NewMetricRepository()
-	snapshots, err := metricsRepo.GetTrendsByBrandID(brandID, 365)
+	// Get metrics history (last 365 days unless a range is given)
+	q, err := parseMetricsQuery(c, 365)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid metrics query", "details": err.Error()})
+		return
+	}
+	points, err := services.NewMetricsCalculator().QueryMetrics(brandID, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get metrics"})
 		return
@@ -719,13 +1973,19 @@ func ExportCSV(c *gin.Context) {
 
 	// Build CSV
 	var csvContent strings.Builder
-	csvContent.WriteString("Date,Visibility Score,Citation Share,Total Mentions,Positive,Neutral,Negative\n")
+	csvContent.WriteString("Date,Runs,Visibility Score,Citation Share,Total Mentions,Positive,Neutral,Negative\n")
 
-	for _, s := range snapshots {
-		line := fmt.Sprintf("%s,%.1f,%.1f,%d,%d,%d,%d\n",
-			s.CreatedAt.Format("2006-01-02 15:04"),
+	for _, s := range points {
+		// Model and category series have no citation share
+		citationShare := ""
+		if q.Model == "" && q.Category == "" {
+			citationShare = fmt.Sprintf("%.1f", s.CitationShare)
+		}
+		line := fmt.Sprintf("%s,%d,%.1f,%s,%d,%d,%d,%d\n",
+			s.PeriodStart.Format("2006-01-02 15:04"),
+			s.Runs,
 			s.VisibilityScore,
-			s.CitationShare,
+			citationShare,
 			s.MentionCount,
 			s.PositiveCount,
 			s.NeutralCount,
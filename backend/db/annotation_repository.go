@@ -0,0 +1,122 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// AnnotationRepository handles timeline annotation database operations
+type AnnotationRepository struct {
+	db *sql.DB
+}
+
+// NewAnnotationRepository creates a new annotation repository
+func NewAnnotationRepository() *AnnotationRepository {
+	return &AnnotationRepository{db: DB}
+}
+
+// annotationColumns is the column list shared by annotation queries (see scanAnnotation)
+const annotationColumns = `id, brand_id, annotation_date, title, type, COALESCE(url, ''), created_at, updated_at`
+
+// scanAnnotation scans a row selected with annotationColumns
+func scanAnnotation(row rowScanner) (models.Annotation, error) {
+	var a models.Annotation
+	err := row.Scan(&a.ID, &a.BrandID, &a.Date, &a.Title, &a.Type, &a.URL, &a.CreatedAt, &a.UpdatedAt)
+	return a, err
+}
+
+// Create stores an annotation
+func (r *AnnotationRepository) Create(a *models.Annotation) (*models.Annotation, error) {
+	result, err := r.db.Exec(
+		"INSERT INTO annotations (brand_id, annotation_date, title, type, url) VALUES (?, ?, ?, ?, ?)",
+		a.BrandID, a.Date, a.Title, a.Type, nullableString(a.URL),
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(a.BrandID, int(id))
+}
+
+// GetByID returns one of a brand's annotations (sql.ErrNoRows when it does not exist)
+func (r *AnnotationRepository) GetByID(brandID, id int) (*models.Annotation, error) {
+	a, err := scanAnnotation(r.db.QueryRow("SELECT "+annotationColumns+" FROM annotations WHERE id = ? AND brand_id = ?", id, brandID))
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetBetween returns a brand's annotations dated within [from, to], oldest first
+func (r *AnnotationRepository) GetBetween(brandID int, from, to time.Time) ([]models.Annotation, error) {
+	return r.query(`
+		SELECT `+annotationColumns+`
+		FROM annotations
+		WHERE brand_id = ? AND annotation_date BETWEEN DATE(?) AND ?
+		ORDER BY annotation_date ASC, id ASC`,
+		brandID, from, to,
+	)
+}
+
+// GetByBrandID returns every annotation of a brand, oldest first
+func (r *AnnotationRepository) GetByBrandID(brandID int) ([]models.Annotation, error) {
+	return r.query(`
+		SELECT `+annotationColumns+`
+		FROM annotations
+		WHERE brand_id = ?
+		ORDER BY annotation_date ASC, id ASC`,
+		brandID,
+	)
+}
+
+// Update replaces an annotation's date, title, type and URL (sql.ErrNoRows when it does not exist)
+func (r *AnnotationRepository) Update(a *models.Annotation) (*models.Annotation, error) {
+	_, err := r.db.Exec(
+		"UPDATE annotations SET annotation_date = ?, title = ?, type = ?, url = ? WHERE id = ? AND brand_id = ?",
+		a.Date, a.Title, a.Type, nullableString(a.URL), a.ID, a.BrandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(a.BrandID, a.ID)
+}
+
+// Delete removes one of a brand's annotations (sql.ErrNoRows when it does not exist)
+func (r *AnnotationRepository) Delete(brandID, id int) error {
+	result, err := r.db.Exec("DELETE FROM annotations WHERE id = ? AND brand_id = ?", id, brandID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// query runs an annotation query and scans every row
+func (r *AnnotationRepository) query(query string, args ...interface{}) ([]models.Annotation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var annotations []models.Annotation
+	for rows.Next() {
+		a, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// AnomalyRepository handles detected anomaly database operations
+type AnomalyRepository struct {
+	db *sql.DB
+}
+
+// NewAnomalyRepository creates a new anomaly repository
+func NewAnomalyRepository() *AnomalyRepository {
+	return &AnomalyRepository{db: DB}
+}
+
+// anomalyColumns is the column list shared by anomaly queries (see scanAnomaly)
+const anomalyColumns = `id, brand_id, COALESCE(metric_snapshot_id, 0), kind, scope, subject,
+	value, baseline, z_score, COALESCE(message, ''), detected_at, acknowledged_at`
+
+// scanAnomaly scans a row selected with anomalyColumns
+func scanAnomaly(row rowScanner) (models.Anomaly, error) {
+	var a models.Anomaly
+	var acknowledgedAt sql.NullTime
+	err := row.Scan(&a.ID, &a.BrandID, &a.MetricSnapshotID, &a.Kind, &a.Scope, &a.Subject,
+		&a.Value, &a.Baseline, &a.ZScore, &a.Message, &a.DetectedAt, &acknowledgedAt)
+	if acknowledgedAt.Valid {
+		a.AcknowledgedAt = &acknowledgedAt.Time
+	}
+	return a, err
+}
+
+// Create stores an anomaly. An anomaly already raised for the same run, kind and subject
+// is skipped; the returned flag tells whether a row was inserted.
+func (r *AnomalyRepository) Create(a *models.Anomaly) (bool, error) {
+	var metricSnapshotID sql.NullInt64
+	if a.MetricSnapshotID > 0 {
+		metricSnapshotID = sql.NullInt64{Int64: int64(a.MetricSnapshotID), Valid: true}
+	}
+	result, err := r.db.Exec(
+		`INSERT IGNORE INTO anomalies (brand_id, metric_snapshot_id, kind, scope, subject, value, baseline, z_score, message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.BrandID, metricSnapshotID, a.Kind, a.Scope, a.Subject, a.Value, a.Baseline, a.ZScore, a.Message,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetByBrandID returns a brand's anomalies detected since a date, newest first.
+// With openOnly set, acknowledged anomalies are left out.
+func (r *AnomalyRepository) GetByBrandID(brandID int, since time.Time, openOnly bool) ([]models.Anomaly, error) {
+	query := "SELECT " + anomalyColumns + " FROM anomalies WHERE brand_id = ? AND detected_at >= ?"
+	if openOnly {
+		query += " AND acknowledged_at IS NULL"
+	}
+	query += " ORDER BY detected_at DESC, id DESC"
+
+	rows, err := r.db.Query(query, brandID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []models.Anomaly
+	for rows.Next() {
+		a, err := scanAnomaly(rows)
+		if err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, nil
+}
+
+// CountOpenByUserID counts the unacknowledged anomalies of each of a user's brands, by brand ID
+func (r *AnomalyRepository) CountOpenByUserID(userID int) (map[int]int, error) {
+	rows, err := r.db.Query(`
+		SELECT a.brand_id, COUNT(*)
+		FROM anomalies a
+		JOIN brands b ON b.id = a.brand_id
+		WHERE b.user_id = ? AND a.acknowledged_at IS NULL
+		GROUP BY a.brand_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[int]int{}
+	for rows.Next() {
+		var brandID, count int
+		if err := rows.Scan(&brandID, &count); err != nil {
+			return nil, err
+		}
+		counts[brandID] = count
+	}
+	return counts, nil
+}
+
+// Acknowledge marks a brand's anomaly as seen (sql.ErrNoRows when it does not exist).
+// Acknowledging twice keeps the first acknowledgement time.
+func (r *AnomalyRepository) Acknowledge(brandID, id int) (*models.Anomaly, error) {
+	_, err := r.db.Exec(
+		"UPDATE anomalies SET acknowledged_at = COALESCE(acknowledged_at, NOW()) WHERE id = ? AND brand_id = ?",
+		id, brandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := scanAnomaly(r.db.QueryRow("SELECT "+anomalyColumns+" FROM anomalies WHERE id = ? AND brand_id = ?", id, brandID))
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// AspectRepository handles aspect sentiment database operations
+type AspectRepository struct {
+	db *sql.DB
+}
+
+// NewAspectRepository creates a new aspect repository
+func NewAspectRepository() *AspectRepository {
+	return &AspectRepository{db: DB}
+}
+
+// Create stores an aspect sentiment row for a mention
+func (r *AspectRepository) Create(aspect *models.MentionAspect) error {
+	_, err := r.db.Exec(
+		"INSERT INTO mention_aspects (brand_id, mention_id, entity_name, entity_type, aspect, sentiment, snippet, model_name) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		aspect.BrandID, aspect.MentionID, aspect.EntityName, aspect.EntityType, aspect.Aspect, aspect.Sentiment, aspect.Snippet, aspect.ModelName,
+	)
+	return err
+}
+
+// GetDailyAggregates returns per-entity, per-aspect sentiment counts grouped by day
+func (r *AspectRepository) GetDailyAggregates(brandID int, since time.Time) ([]models.AspectSentimentPoint, error) {
+	rows, err := r.db.Query(`
+		SELECT entity_name, entity_type, aspect, DATE_FORMAT(created_at, '%Y-%m-%d') AS day,
+			SUM(sentiment = 'positive'), SUM(sentiment = 'neutral'), SUM(sentiment = 'negative')
+		FROM mention_aspects
+		WHERE brand_id = ? AND created_at >= ?
+		GROUP BY entity_name, entity_type, aspect, day
+		ORDER BY day ASC, entity_type ASC, entity_name ASC, aspect ASC`,
+		brandID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []models.AspectSentimentPoint
+	for rows.Next() {
+		var p models.AspectSentimentPoint
+		if err := rows.Scan(&p.EntityName, &p.EntityType, &p.Aspect, &p.Date, &p.Positive, &p.Neutral, &p.Negative); err != nil {
+			return nil, err
+		}
+		if total := p.Positive + p.Neutral + p.Negative; total > 0 {
+			p.NetScore = float64(p.Positive-p.Negative) / float64(total)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// MentionRewrite is the re-detected data for one stored response
+type MentionRewrite struct {
+	Response         models.AIResponse
+	Mentions         []models.Mention
+	Aspects          [][]models.MentionAspect // Aspects[i] belong to Mentions[i]
+	Citations        []models.ResponseCitation
+	CitationMentions []int // Index into Mentions for each citation, -1 when unattributed
+}
+
+// ReplaceForResponses swaps the mentions, aspects and citations of each response for the
+// re-detected ones in a single transaction. Rows keep the response's timestamp so
+// aspect and citation history stays on the day the response was collected.
+func (r *MentionRepository) ReplaceForResponses(rewrites []MentionRewrite) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, rw := range rewrites {
+		if err := replaceResponseMentions(tx, rw); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("response %d: %w", rw.Response.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// replaceResponseMentions rewrites one response inside the transaction
+func replaceResponseMentions(tx *sql.Tx, rw MentionRewrite) error {
+	responseID := rw.Response.ID
+
+	// 1. Drop derived rows of the old mentions
+	if _, err := tx.Exec("DELETE FROM mention_aspects WHERE mention_id IN (SELECT id FROM mentions WHERE ai_response_id = ?)", responseID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM response_citations WHERE ai_response_id = ?", responseID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM mentions WHERE ai_response_id = ?", responseID); err != nil {
+		return err
+	}
+
+	// 2. Insert the new mentions
+	mentionIDs := make([]int, len(rw.Mentions))
+	for i, m := range rw.Mentions {
+		var aiSentiment, aiRationale, classifierModel, productName sql.NullString
+		var aiConfidence sql.NullFloat64
+		var aiIsRecommendation sql.NullBool
+		var productID sql.NullInt64
+		if m.AISentiment != "" {
+			aiSentiment = sql.NullString{String: m.AISentiment, Valid: true}
+			aiConfidence = sql.NullFloat64{Float64: m.AIConfidence, Valid: true}
+			aiIsRecommendation = sql.NullBool{Bool: m.AIIsRecommendation, Valid: true}
+			aiRationale = sql.NullString{String: m.AIRationale, Valid: true}
+			classifierModel = sql.NullString{String: m.ClassifierModel, Valid: true}
+		}
+		if m.ProductID > 0 {
+			productID = sql.NullInt64{Int64: int64(m.ProductID), Valid: true}
+			productName = sql.NullString{String: m.ProductName, Valid: true}
+		}
+
+		result, err := tx.Exec(
+			`INSERT INTO mentions (ai_response_id, entity_name, entity_type, sentiment, context_snippet, position,
+				is_recommendation, position_rank, list_length, ai_sentiment, ai_confidence, ai_is_recommendation,
+				ai_rationale, classifier_model, product_id, product_name, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			responseID, m.EntityName, m.EntityType, m.Sentiment, m.ContextSnippet, m.Position,
+			m.IsRecommendation, m.PositionRank, m.ListLength, aiSentiment, aiConfidence, aiIsRecommendation,
+			aiRationale, classifierModel, productID, productName, rw.Response.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		mentionIDs[i] = int(id)
+	}
+
+	// 3. Insert aspects linked to the new mention IDs
+	for i, aspects := range rw.Aspects {
+		for _, a := range aspects {
+			_, err := tx.Exec(
+				"INSERT INTO mention_aspects (brand_id, mention_id, entity_name, entity_type, aspect, sentiment, snippet, model_name, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				rw.Response.BrandID, mentionIDs[i], a.EntityName, a.EntityType, a.Aspect, a.Sentiment, a.Snippet, rw.Response.ModelName, rw.Response.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// 4. Insert citations linked to the nearest new mention
+	for i, c := range rw.Citations {
+		var mentionID sql.NullInt64
+		var entityType sql.NullString
+		if idx := rw.CitationMentions[i]; idx >= 0 && idx < len(mentionIDs) {
+			mentionID = sql.NullInt64{Int64: int64(mentionIDs[idx]), Valid: true}
+			entityType = sql.NullString{String: rw.Mentions[idx].EntityType, Valid: true}
+			c.EntityName = rw.Mentions[idx].EntityName
+		}
+		_, err := tx.Exec(
+			"INSERT INTO response_citations (brand_id, ai_response_id, mention_id, url, domain, source_name, position, entity_name, entity_type, model_name, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			rw.Response.BrandID, responseID, mentionID, c.URL, c.Domain, c.SourceName,
+			c.Position, c.EntityName, entityType, rw.Response.ModelName, rw.Response.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -62,16 +62,20 @@ func (r *BrandRepository) Create(userID int, req models.CreateBrandRequest) (*mo
 func (r *BrandRepository) GetByID(id int) (*models.Brand, error) {
 	brand := &models.Brand{}
 	var competitorInsights sql.NullString
+	var nextRunAt sql.NullTime
 	err := r.db.QueryRow(
-		"SELECT id, user_id, name, industry, COALESCE(alert_threshold, 0), COALESCE(schedule_frequency, 'disabled'), COALESCE(competitor_insights, ''), created_at, updated_at FROM brands WHERE id = ?",
+		"SELECT id, user_id, name, industry, COALESCE(alert_threshold, 0), COALESCE(forecast_alert_weeks, 0), COALESCE(schedule_frequency, 'disabled'), COALESCE(schedule_cron, ''), COALESCE(schedule_timezone, ''), next_run_at, COALESCE(last_scheduled_run, '1970-01-01'), COALESCE(sentiment_source, 'rules'), COALESCE(competitor_insights, ''), created_at, updated_at FROM brands WHERE id = ?",
 		id,
-	).Scan(&brand.ID, &brand.UserID, &brand.Name, &brand.Industry, &brand.AlertThreshold, &brand.ScheduleFrequency, &competitorInsights, &brand.CreatedAt, &brand.UpdatedAt)
+	).Scan(&brand.ID, &brand.UserID, &brand.Name, &brand.Industry, &brand.AlertThreshold, &brand.ForecastAlertWeeks, &brand.ScheduleFrequency, &brand.ScheduleCron, &brand.ScheduleTimezone, &nextRunAt, &brand.LastScheduledRun, &brand.SentimentSource, &competitorInsights, &brand.CreatedAt, &brand.UpdatedAt)
 	if competitorInsights.Valid {
 		brand.CompetitorInsights = competitorInsights.String
 	}
 	if err != nil {
 		return nil, err
 	}
+	if nextRunAt.Valid {
+		brand.NextRunAt = &nextRunAt.Time
+	}
 
 	// Get aliases
 	aliasRows, err := r.db.Query("SELECT id, brand_id, alias, created_at FROM brand_aliases WHERE brand_id = ?", id)
@@ -103,13 +107,20 @@ func (r *BrandRepository) GetByID(id int) (*models.Brand, error) {
 		brand.Competitors = append(brand.Competitors, comp)
 	}
 
+	// Get products (with their aliases)
+	products, err := NewProductRepository().GetByBrandID(id)
+	if err != nil {
+		return nil, err
+	}
+	brand.Products = products
+
 	return brand, nil
 }
 
 // GetAll retrieves all brands for a user
 func (r *BrandRepository) GetAll(userID int) ([]models.Brand, error) {
 	rows, err := r.db.Query(
-		"SELECT id, user_id, name, industry, COALESCE(alert_threshold, 0), COALESCE(schedule_frequency, 'disabled'), COALESCE(competitor_insights, ''), created_at, updated_at FROM brands WHERE user_id = ?",
+		"SELECT id, user_id, name, industry, COALESCE(alert_threshold, 0), COALESCE(forecast_alert_weeks, 0), COALESCE(schedule_frequency, 'disabled'), COALESCE(schedule_cron, ''), COALESCE(schedule_timezone, ''), next_run_at, COALESCE(last_scheduled_run, '1970-01-01'), COALESCE(sentiment_source, 'rules'), COALESCE(competitor_insights, ''), created_at, updated_at FROM brands WHERE user_id = ?",
 		userID,
 	)
 	if err != nil {
@@ -121,44 +132,61 @@ func (r *BrandRepository) GetAll(userID int) ([]models.Brand, error) {
 	for rows.Next() {
 		var brand models.Brand
 		var competitorInsights sql.NullString
-		if err := rows.Scan(&brand.ID, &brand.UserID, &brand.Name, &brand.Industry, &brand.AlertThreshold, &brand.ScheduleFrequency, &competitorInsights, &brand.CreatedAt, &brand.UpdatedAt); err != nil {
+		var nextRunAt sql.NullTime
+		if err := rows.Scan(&brand.ID, &brand.UserID, &brand.Name, &brand.Industry, &brand.AlertThreshold, &brand.ForecastAlertWeeks, &brand.ScheduleFrequency, &brand.ScheduleCron, &brand.ScheduleTimezone, &nextRunAt, &brand.LastScheduledRun, &brand.SentimentSource, &competitorInsights, &brand.CreatedAt, &brand.UpdatedAt); err != nil {
 			return nil, err
 		}
 		if competitorInsights.Valid {
 			brand.CompetitorInsights = competitorInsights.String
 		}
+		if nextRunAt.Valid {
+			brand.NextRunAt = &nextRunAt.Time
+		}
 
-		// Get aliases for this brand
-		aliasRows, err := r.db.Query("SELECT id, brand_id, alias, created_at FROM brand_aliases WHERE brand_id = ?", brand.ID)
-		if err != nil {
+		brands = append(brands, brand)
+	}
+	rows.Close()
+
+	// Aliases and competitors of every brand of the user, one query each
+	byID := map[int]*models.Brand{}
+	for i := range brands {
+		byID[brands[i].ID] = &brands[i]
+	}
+
+	aliasRows, err := r.db.Query(
+		"SELECT a.id, a.brand_id, a.alias, a.created_at FROM brand_aliases a JOIN brands b ON b.id = a.brand_id WHERE b.user_id = ? ORDER BY a.id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer aliasRows.Close()
+	for aliasRows.Next() {
+		var alias models.BrandAlias
+		if err := aliasRows.Scan(&alias.ID, &alias.BrandID, &alias.Alias, &alias.CreatedAt); err != nil {
 			return nil, err
 		}
-		for aliasRows.Next() {
-			var alias models.BrandAlias
-			if err := aliasRows.Scan(&alias.ID, &alias.BrandID, &alias.Alias, &alias.CreatedAt); err != nil {
-				aliasRows.Close()
-				return nil, err
-			}
+		if brand, ok := byID[alias.BrandID]; ok {
 			brand.Aliases = append(brand.Aliases, alias)
 		}
-		aliasRows.Close()
+	}
 
-		// Get competitors for this brand
-		compRows, err := r.db.Query("SELECT id, brand_id, name, created_at FROM competitors WHERE brand_id = ?", brand.ID)
-		if err != nil {
+	compRows, err := r.db.Query(
+		"SELECT c.id, c.brand_id, c.name, c.created_at FROM competitors c JOIN brands b ON b.id = c.brand_id WHERE b.user_id = ? ORDER BY c.id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer compRows.Close()
+	for compRows.Next() {
+		var comp models.Competitor
+		if err := compRows.Scan(&comp.ID, &comp.BrandID, &comp.Name, &comp.CreatedAt); err != nil {
 			return nil, err
 		}
-		for compRows.Next() {
-			var comp models.Competitor
-			if err := compRows.Scan(&comp.ID, &comp.BrandID, &comp.Name, &comp.CreatedAt); err != nil {
-				compRows.Close()
-				return nil, err
-			}
+		if brand, ok := byID[comp.BrandID]; ok {
 			brand.Competitors = append(brand.Competitors, comp)
 		}
-		compRows.Close()
-
-		brands = append(brands, brand)
 	}
 
 	return brands, nil
@@ -166,8 +194,36 @@ func (r *BrandRepository) GetAll(userID int) ([]models.Brand, error) {
 
 // GetAllBrands retrieves ALL brands (for scheduler/admin)
 func (r *BrandRepository) GetAllBrands() ([]models.Brand, error) {
+	return r.scheduleQuery("")
+}
+
+// GetScheduleByID retrieves one brand with its alert and schedule settings
+func (r *BrandRepository) GetScheduleByID(brandID int) (*models.Brand, error) {
+	brands, err := r.scheduleQuery("WHERE id = ?", brandID)
+	if err != nil {
+		return nil, err
+	}
+	if len(brands) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &brands[0], nil
+}
+
+// GetDueBrands retrieves the brands whose next scheduled run is at or before now
+func (r *BrandRepository) GetDueBrands(now time.Time) ([]models.Brand, error) {
+	return r.scheduleQuery("WHERE COALESCE(schedule_frequency, 'disabled') <> 'disabled' AND next_run_at <= ? ORDER BY next_run_at", now)
+}
+
+// GetUpcomingRuns retrieves the brands with a scheduled run, soonest first
+func (r *BrandRepository) GetUpcomingRuns(limit int) ([]models.Brand, error) {
+	return r.scheduleQuery("WHERE COALESCE(schedule_frequency, 'disabled') <> 'disabled' AND next_run_at IS NOT NULL ORDER BY next_run_at LIMIT ?", limit)
+}
+
+// scheduleQuery retrieves brands with their alert and schedule settings, without aliases or competitors
+func (r *BrandRepository) scheduleQuery(condition string, args ...interface{}) ([]models.Brand, error) {
 	rows, err := r.db.Query(
-		"SELECT id, user_id, name, industry, COALESCE(alert_threshold, 0), COALESCE(schedule_frequency, ''), COALESCE(last_scheduled_run, '1970-01-01'), created_at, updated_at FROM brands",
+		"SELECT id, user_id, name, industry, COALESCE(alert_threshold, 0), COALESCE(forecast_alert_weeks, 0), COALESCE(schedule_frequency, ''), COALESCE(schedule_cron, ''), COALESCE(schedule_timezone, ''), next_run_at, COALESCE(last_scheduled_run, '1970-01-01'), COALESCE(last_alert_state, ''), last_alert_sent_at, created_at, updated_at FROM brands "+condition,
+		args...,
 	)
 	if err != nil {
 		return nil, err
@@ -177,28 +233,92 @@ func (r *BrandRepository) GetAllBrands() ([]models.Brand, error) {
 	var brands []models.Brand
 	for rows.Next() {
 		var brand models.Brand
-		if err := rows.Scan(&brand.ID, &brand.UserID, &brand.Name, &brand.Industry, &brand.AlertThreshold, &brand.ScheduleFrequency, &brand.LastScheduledRun, &brand.CreatedAt, &brand.UpdatedAt); err != nil {
+		var nextRunAt, lastAlertSentAt sql.NullTime
+		if err := rows.Scan(&brand.ID, &brand.UserID, &brand.Name, &brand.Industry, &brand.AlertThreshold, &brand.ForecastAlertWeeks, &brand.ScheduleFrequency, &brand.ScheduleCron, &brand.ScheduleTimezone, &nextRunAt, &brand.LastScheduledRun, &brand.LastAlertState, &lastAlertSentAt, &brand.CreatedAt, &brand.UpdatedAt); err != nil {
 			return nil, err
 		}
+		if nextRunAt.Valid {
+			brand.NextRunAt = &nextRunAt.Time
+		}
+		if lastAlertSentAt.Valid {
+			brand.LastAlertSentAt = &lastAlertSentAt.Time
+		}
 		brands = append(brands, brand)
 	}
 	return brands, nil
 }
 
-// UpdateLastScheduledRun updates the last scheduled run time for a brand
-func (r *BrandRepository) UpdateLastScheduledRun(brandID int, runTime time.Time) error {
+// ClaimScheduledRun records a scheduled run and when the next one is due (nil when none is),
+// provided the brand is still due at dueAt. It reports false when another instance or a
+// schedule change moved the run first.
+func (r *BrandRepository) ClaimScheduledRun(brandID int, dueAt, runTime time.Time, nextRunAt *time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE brands SET last_scheduled_run = ?, next_run_at = ? WHERE id = ? AND next_run_at = ?",
+		runTime, nextRunAt, brandID, dueAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// ClaimAlertState moves a brand's alert state on from the one it was read with, recording when an
+// email was sent (nil keeps the previous time). It reports false when another check changed the
+// state first.
+func (r *BrandRepository) ClaimAlertState(brand models.Brand, state string, sentAt *time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		`UPDATE brands SET last_alert_state = ?, last_alert_sent_at = COALESCE(?, last_alert_sent_at)
+		WHERE id = ? AND last_alert_state <=> ? AND last_alert_sent_at <=> ?`,
+		state, sentAt, brand.ID, nullableString(brand.LastAlertState), brand.LastAlertSentAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// ReleaseAlertState puts back the alert state a brand was read with after a claim of state at
+// sentAt whose email failed. A later claim is left alone.
+func (r *BrandRepository) ReleaseAlertState(previous models.Brand, state string, sentAt time.Time) error {
 	_, err := r.db.Exec(
-		"UPDATE brands SET last_scheduled_run = ? WHERE id = ?",
-		runTime, brandID,
+		`UPDATE brands SET last_alert_state = ?, last_alert_sent_at = ?
+		WHERE id = ? AND last_alert_state = ? AND last_alert_sent_at = ?`,
+		nullableString(previous.LastAlertState), previous.LastAlertSentAt, previous.ID, state, sentAt,
 	)
 	return err
 }
 
-// UpdateAlertSettings updates alert threshold and schedule for a brand
-func (r *BrandRepository) UpdateAlertSettings(brandID int, threshold float64, frequency string) error {
+// UpdateAlertSettings updates alert threshold, forecast alert horizon and schedule for a brand,
+// with the time of the next scheduled run the schedule gives (nil when disabled)
+func (r *BrandRepository) UpdateAlertSettings(brandID int, settings models.AlertSettings, nextRunAt *time.Time) error {
 	_, err := r.db.Exec(
-		"UPDATE brands SET alert_threshold = ?, schedule_frequency = ? WHERE id = ?",
-		threshold, frequency, brandID,
+		`UPDATE brands SET alert_threshold = ?, forecast_alert_weeks = ?, schedule_frequency = ?,
+			schedule_cron = ?, schedule_timezone = ?, next_run_at = ?
+		WHERE id = ?`,
+		settings.AlertThreshold, settings.ForecastAlertWeeks, settings.ScheduleFrequency,
+		nullableString(settings.ScheduleCron), nullableString(settings.ScheduleTimezone), nextRunAt, brandID,
+	)
+	return err
+}
+
+// nullableString stores an empty string as NULL
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// UpdateSentimentSource sets which mention labels ("rules" or "llm") drive the composite score
+func (r *BrandRepository) UpdateSentimentSource(brandID int, source string) error {
+	_, err := r.db.Exec(
+		"UPDATE brands SET sentiment_source = ? WHERE id = ?",
+		source, brandID,
 	)
 	return err
 }
@@ -227,31 +347,101 @@ func (r *BrandRepository) Delete(id int) error {
 		return err
 	}
 
-	// 2. Delete AI responses
+	// 2. Delete aspect sentiment history (kept independently of mentions)
+	_, err = r.db.Exec("DELETE FROM mention_aspects WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// 3. Delete citation history (kept independently of responses)
+	_, err = r.db.Exec("DELETE FROM response_citations WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// 4. Delete misinformation findings (reference brand facts)
+	_, err = r.db.Exec("DELETE FROM misinformation_findings WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// 5. Delete the brand's fact sheet
+	_, err = r.db.Exec("DELETE FROM brand_facts WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// 6. Delete product snapshots, aliases and products
+	_, err = r.db.Exec("DELETE FROM product_snapshots WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("DELETE FROM product_aliases WHERE product_id IN (SELECT id FROM products WHERE brand_id = ?)", id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("DELETE FROM products WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// 7. Delete AI responses
 	_, err = r.db.Exec("DELETE FROM ai_responses WHERE brand_id = ?", id)
 	if err != nil {
 		return err
 	}
 
-	// 3. Delete metric snapshots
+	// 8. Delete anomalies, then competitor, model, category, prompt and brand metric snapshots
+	_, err = r.db.Exec("DELETE FROM anomalies WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("DELETE FROM prompt_snapshots WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("DELETE FROM category_snapshots WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("DELETE FROM model_snapshots WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("DELETE FROM competitor_snapshots WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
 	_, err = r.db.Exec("DELETE FROM metric_snapshots WHERE brand_id = ?", id)
 	if err != nil {
 		return err
 	}
 
-	// 4. Delete brand aliases
+	// 9. Delete timeline annotations
+	_, err = r.db.Exec("DELETE FROM annotations WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// 10. Delete scoring profile versions
+	_, err = r.db.Exec("DELETE FROM scoring_profiles WHERE brand_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// 11. Delete brand aliases
 	_, err = r.db.Exec("DELETE FROM brand_aliases WHERE brand_id = ?", id)
 	if err != nil {
 		return err
 	}
 
-	// 5. Delete competitors
+	// 12. Delete competitors
 	_, err = r.db.Exec("DELETE FROM competitors WHERE brand_id = ?", id)
 	if err != nil {
 		return err
 	}
 
-	// 6. Finally delete the brand itself
+	// 13. Finally delete the brand itself
 	_, err = r.db.Exec("DELETE FROM brands WHERE id = ?", id)
 	return err
 }
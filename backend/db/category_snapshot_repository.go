@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// CategorySnapshotRepository handles per-category score snapshot database operations
+type CategorySnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewCategorySnapshotRepository creates a new category snapshot repository
+func NewCategorySnapshotRepository() *CategorySnapshotRepository {
+	return &CategorySnapshotRepository{db: DB}
+}
+
+// categorySnapshotColumns is the column list shared by category snapshot queries (see scanCategorySnapshot)
+const categorySnapshotColumns = `id, brand_id, COALESCE(metric_snapshot_id, 0), category,
+	visibility_score, mention_count, responses_with_brand,
+	normalized_mention_rate, weighted_position_score, recommendation_rate, relative_sentiment_index,
+	response_count, COALESCE(scoring_profile_version, 0), snapshot_date`
+
+// scanCategorySnapshot scans a row selected with categorySnapshotColumns
+func scanCategorySnapshot(row rowScanner) (models.CategorySnapshot, error) {
+	var s models.CategorySnapshot
+	err := row.Scan(&s.ID, &s.BrandID, &s.MetricSnapshotID, &s.Category,
+		&s.VisibilityScore, &s.MentionCount, &s.ResponsesWithBrand,
+		&s.NormalizedMentionRate, &s.WeightedPositionScore, &s.RecommendationRate, &s.RelativeSentimentIndex,
+		&s.ResponseCount, &s.ScoringProfileVersion, &s.SnapshotDate)
+	return s, err
+}
+
+// Create stores a category snapshot
+func (r *CategorySnapshotRepository) Create(snapshot *models.CategorySnapshot) error {
+	return insertCategorySnapshot(r.db, snapshot)
+}
+
+// insertCategorySnapshot inserts a category snapshot on the database or within a transaction
+func insertCategorySnapshot(ex execer, snapshot *models.CategorySnapshot) error {
+	var metricSnapshotID sql.NullInt64
+	if snapshot.MetricSnapshotID > 0 {
+		metricSnapshotID = sql.NullInt64{Int64: int64(snapshot.MetricSnapshotID), Valid: true}
+	}
+	_, err := ex.Exec(
+		`INSERT INTO category_snapshots (
+			brand_id, metric_snapshot_id, category, visibility_score, mention_count, responses_with_brand,
+			normalized_mention_rate, weighted_position_score, recommendation_rate, relative_sentiment_index,
+			response_count, scoring_profile_version, snapshot_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.BrandID, metricSnapshotID, snapshot.Category, snapshot.VisibilityScore, snapshot.MentionCount, snapshot.ResponsesWithBrand,
+		snapshot.NormalizedMentionRate, snapshot.WeightedPositionScore, snapshot.RecommendationRate, snapshot.RelativeSentimentIndex,
+		snapshot.ResponseCount, snapshot.ScoringProfileVersion, snapshot.SnapshotDate,
+	)
+	return err
+}
+
+// GetBetween returns a brand's snapshots of one category created within [from, to], oldest first
+func (r *CategorySnapshotRepository) GetBetween(brandID int, category string, from, to time.Time) ([]models.CategorySnapshot, error) {
+	return r.query(`
+		SELECT `+categorySnapshotColumns+`
+		FROM category_snapshots
+		WHERE brand_id = ? AND category = ? AND snapshot_date BETWEEN ? AND ?
+		ORDER BY snapshot_date ASC, id ASC`,
+		brandID, category, from, to,
+	)
+}
+
+// query runs a snapshot query and scans every row
+func (r *CategorySnapshotRepository) query(query string, args ...interface{}) ([]models.CategorySnapshot, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.CategorySnapshot
+	for rows.Next() {
+		s, err := scanCategorySnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// CitationRepository handles response citation database operations
+type CitationRepository struct {
+	db *sql.DB
+}
+
+// NewCitationRepository creates a new citation repository
+func NewCitationRepository() *CitationRepository {
+	return &CitationRepository{db: DB}
+}
+
+// Create stores a citation extracted from an AI response
+func (r *CitationRepository) Create(citation *models.ResponseCitation) error {
+	var mentionID sql.NullInt64
+	if citation.MentionID > 0 {
+		mentionID = sql.NullInt64{Int64: int64(citation.MentionID), Valid: true}
+	}
+	var entityType sql.NullString
+	if citation.EntityType != "" {
+		entityType = sql.NullString{String: citation.EntityType, Valid: true}
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO response_citations (brand_id, ai_response_id, mention_id, url, domain, source_name, position, entity_name, entity_type, model_name) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		citation.BrandID, citation.AIResponseID, mentionID, citation.URL, citation.Domain, citation.SourceName,
+		citation.Position, citation.EntityName, entityType, citation.ModelName,
+	)
+	return err
+}
+
+// GetByResponseID returns all citations extracted from one response
+func (r *CitationRepository) GetByResponseID(aiResponseID int) ([]models.ResponseCitation, error) {
+	rows, err := r.db.Query(
+		`SELECT id, brand_id, ai_response_id, COALESCE(mention_id, 0), COALESCE(url, ''), COALESCE(domain, ''),
+			COALESCE(source_name, ''), COALESCE(position, 0), COALESCE(entity_name, ''), COALESCE(entity_type, ''),
+			COALESCE(model_name, ''), created_at
+		FROM response_citations WHERE ai_response_id = ? ORDER BY position`,
+		aiResponseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var citations []models.ResponseCitation
+	for rows.Next() {
+		var c models.ResponseCitation
+		if err := rows.Scan(&c.ID, &c.BrandID, &c.AIResponseID, &c.MentionID, &c.URL, &c.Domain,
+			&c.SourceName, &c.Position, &c.EntityName, &c.EntityType, &c.ModelName, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		citations = append(citations, c)
+	}
+	return citations, nil
+}
+
+// GetTopDomains returns the most cited domains for a brand since a date
+func (r *CitationRepository) GetTopDomains(brandID int, since time.Time, limit int) ([]models.CitedDomain, error) {
+	rows, err := r.db.Query(`
+		SELECT domain, COUNT(*), COUNT(DISTINCT ai_response_id)
+		FROM response_citations
+		WHERE brand_id = ? AND created_at >= ? AND domain <> ''
+		GROUP BY domain
+		ORDER BY COUNT(*) DESC, domain ASC
+		LIMIT ?`,
+		brandID, since, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []models.CitedDomain
+	for rows.Next() {
+		var d models.CitedDomain
+		if err := rows.Scan(&d.Domain, &d.Citations, &d.Responses); err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+// GetDomainsByEntity returns cited domain counts split per nearby brand/competitor,
+// ordered by entity and then by citation count
+func (r *CitationRepository) GetDomainsByEntity(brandID int, since time.Time) ([]models.CitedDomain, error) {
+	rows, err := r.db.Query(`
+		SELECT domain, entity_name, entity_type, COUNT(*), COUNT(DISTINCT ai_response_id)
+		FROM response_citations
+		WHERE brand_id = ? AND created_at >= ? AND domain <> '' AND entity_name <> ''
+		GROUP BY entity_name, entity_type, domain
+		ORDER BY entity_type ASC, entity_name ASC, COUNT(*) DESC, domain ASC`,
+		brandID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []models.CitedDomain
+	for rows.Next() {
+		var d models.CitedDomain
+		if err := rows.Scan(&d.Domain, &d.EntityName, &d.EntityType, &d.Citations, &d.Responses); err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// CompetitorSnapshotRepository handles competitor score snapshot database operations
+type CompetitorSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewCompetitorSnapshotRepository creates a new competitor snapshot repository
+func NewCompetitorSnapshotRepository() *CompetitorSnapshotRepository {
+	return &CompetitorSnapshotRepository{db: DB}
+}
+
+// competitorSnapshotColumns is the column list shared by competitor snapshot queries (see scanCompetitorSnapshot)
+const competitorSnapshotColumns = `cs.id, cs.brand_id, cs.competitor_id, c.name, COALESCE(cs.metric_snapshot_id, 0),
+	cs.visibility_score, cs.mention_count, cs.positive_count, cs.neutral_count, cs.negative_count,
+	cs.normalized_mention_rate, cs.weighted_position_score, cs.recommendation_rate, cs.relative_sentiment_index,
+	cs.response_count, COALESCE(cs.scoring_profile_version, 0), cs.snapshot_date`
+
+// scanCompetitorSnapshot scans a row selected with competitorSnapshotColumns
+func scanCompetitorSnapshot(row rowScanner) (models.CompetitorSnapshot, error) {
+	var s models.CompetitorSnapshot
+	err := row.Scan(&s.ID, &s.BrandID, &s.CompetitorID, &s.CompetitorName, &s.MetricSnapshotID,
+		&s.VisibilityScore, &s.MentionCount, &s.PositiveCount, &s.NeutralCount, &s.NegativeCount,
+		&s.NormalizedMentionRate, &s.WeightedPositionScore, &s.RecommendationRate, &s.RelativeSentimentIndex,
+		&s.ResponseCount, &s.ScoringProfileVersion, &s.SnapshotDate)
+	return s, err
+}
+
+// Create stores a competitor snapshot
+func (r *CompetitorSnapshotRepository) Create(snapshot *models.CompetitorSnapshot) error {
+	return insertCompetitorSnapshot(r.db, snapshot)
+}
+
+// insertCompetitorSnapshot inserts a competitor snapshot on the database or within a transaction
+func insertCompetitorSnapshot(ex execer, snapshot *models.CompetitorSnapshot) error {
+	var metricSnapshotID sql.NullInt64
+	if snapshot.MetricSnapshotID > 0 {
+		metricSnapshotID = sql.NullInt64{Int64: int64(snapshot.MetricSnapshotID), Valid: true}
+	}
+	_, err := ex.Exec(
+		`INSERT INTO competitor_snapshots (
+			brand_id, competitor_id, metric_snapshot_id, visibility_score, mention_count,
+			positive_count, neutral_count, negative_count,
+			normalized_mention_rate, weighted_position_score, recommendation_rate, relative_sentiment_index,
+			response_count, scoring_profile_version, snapshot_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.BrandID, snapshot.CompetitorID, metricSnapshotID, snapshot.VisibilityScore, snapshot.MentionCount,
+		snapshot.PositiveCount, snapshot.NeutralCount, snapshot.NegativeCount,
+		snapshot.NormalizedMentionRate, snapshot.WeightedPositionScore, snapshot.RecommendationRate, snapshot.RelativeSentimentIndex,
+		snapshot.ResponseCount, snapshot.ScoringProfileVersion, snapshot.SnapshotDate,
+	)
+	return err
+}
+
+// GetSince returns the competitor snapshots of a brand since a date, oldest first
+func (r *CompetitorSnapshotRepository) GetSince(brandID int, since time.Time) ([]models.CompetitorSnapshot, error) {
+	return r.query(`
+		SELECT `+competitorSnapshotColumns+`
+		FROM competitor_snapshots cs
+		JOIN competitors c ON c.id = cs.competitor_id
+		WHERE cs.brand_id = ? AND cs.snapshot_date >= ?
+		ORDER BY cs.snapshot_date ASC, cs.id ASC`,
+		brandID, since,
+	)
+}
+
+// GetBetween returns the competitor snapshots of a brand taken within [from, to], oldest first
+func (r *CompetitorSnapshotRepository) GetBetween(brandID int, from, to time.Time) ([]models.CompetitorSnapshot, error) {
+	return r.query(`
+		SELECT `+competitorSnapshotColumns+`
+		FROM competitor_snapshots cs
+		JOIN competitors c ON c.id = cs.competitor_id
+		WHERE cs.brand_id = ? AND cs.snapshot_date BETWEEN ? AND ?
+		ORDER BY cs.snapshot_date ASC, cs.id ASC`,
+		brandID, from, to,
+	)
+}
+
+// GetByMetricSnapshotID returns the competitor snapshots stored in the same run as a brand snapshot
+func (r *CompetitorSnapshotRepository) GetByMetricSnapshotID(metricSnapshotID int) ([]models.CompetitorSnapshot, error) {
+	return r.query(`
+		SELECT `+competitorSnapshotColumns+`
+		FROM competitor_snapshots cs
+		JOIN competitors c ON c.id = cs.competitor_id
+		WHERE cs.metric_snapshot_id = ?
+		ORDER BY cs.id ASC`,
+		metricSnapshotID,
+	)
+}
+
+// GetLatestRunByUserID returns the competitor snapshots of the latest run of each of a user's brands
+func (r *CompetitorSnapshotRepository) GetLatestRunByUserID(userID int) ([]models.CompetitorSnapshot, error) {
+	return r.getLatestRuns("b.user_id = ?", userID)
+}
+
+// GetLatestRunByIndustry returns the competitor snapshots of the latest run of every brand of an
+// industry, across all users. Industries match as in MetricRepository.GetLatestByIndustry.
+func (r *CompetitorSnapshotRepository) GetLatestRunByIndustry(industry string) ([]models.CompetitorSnapshot, error) {
+	return r.getLatestRuns("LOWER(TRIM(b.industry)) = LOWER(TRIM(?))", industry)
+}
+
+// getLatestRuns returns the competitor snapshots of the latest run of each brand matching a condition on brands b
+func (r *CompetitorSnapshotRepository) getLatestRuns(condition string, args ...interface{}) ([]models.CompetitorSnapshot, error) {
+	return r.query(`
+		SELECT `+competitorSnapshotColumns+`
+		FROM competitor_snapshots cs
+		JOIN competitors c ON c.id = cs.competitor_id
+		WHERE cs.metric_snapshot_id IN (
+			SELECT MAX(m.id) FROM metric_snapshots m
+			JOIN brands b ON b.id = m.brand_id
+			WHERE `+condition+`
+			GROUP BY m.brand_id
+		)
+		ORDER BY cs.id ASC`,
+		args...,
+	)
+}
+
+// query runs a snapshot query and scans every row
+func (r *CompetitorSnapshotRepository) query(query string, args ...interface{}) ([]models.CompetitorSnapshot, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.CompetitorSnapshot
+	for rows.Next() {
+		s, err := scanCompetitorSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
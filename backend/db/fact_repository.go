@@ -0,0 +1,140 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// FactRepository handles brand fact sheet and misinformation finding database operations
+type FactRepository struct {
+	db *sql.DB
+}
+
+// NewFactRepository creates a new fact repository
+func NewFactRepository() *FactRepository {
+	return &FactRepository{db: DB}
+}
+
+// GetByBrandID returns the verified facts for a brand
+func (r *FactRepository) GetByBrandID(brandID int) ([]models.BrandFact, error) {
+	rows, err := r.db.Query(
+		"SELECT id, brand_id, fact_type, COALESCE(label, ''), value, created_at, updated_at FROM brand_facts WHERE brand_id = ? ORDER BY fact_type, id",
+		brandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facts []models.BrandFact
+	for rows.Next() {
+		var f models.BrandFact
+		if err := rows.Scan(&f.ID, &f.BrandID, &f.FactType, &f.Label, &f.Value, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		facts = append(facts, f)
+	}
+	return facts, nil
+}
+
+// GetByID returns a single fact
+func (r *FactRepository) GetByID(id int) (*models.BrandFact, error) {
+	var f models.BrandFact
+	err := r.db.QueryRow(
+		"SELECT id, brand_id, fact_type, COALESCE(label, ''), value, created_at, updated_at FROM brand_facts WHERE id = ?",
+		id,
+	).Scan(&f.ID, &f.BrandID, &f.FactType, &f.Label, &f.Value, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Create adds a fact to a brand's fact sheet
+func (r *FactRepository) Create(brandID int, factType, label, value string) (*models.BrandFact, error) {
+	result, err := r.db.Exec(
+		"INSERT INTO brand_facts (brand_id, fact_type, label, value) VALUES (?, ?, ?, ?)",
+		brandID, factType, label, value,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(int(id))
+}
+
+// Update changes a fact, scoped to its brand
+func (r *FactRepository) Update(brandID, factID int, factType, label, value string) (*models.BrandFact, error) {
+	result, err := r.db.Exec(
+		"UPDATE brand_facts SET fact_type = ?, label = ?, value = ? WHERE id = ? AND brand_id = ?",
+		factType, label, value, factID, brandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		// MySQL reports 0 rows when nothing changed, so confirm the fact exists
+		fact, err := r.GetByID(factID)
+		if err != nil {
+			return nil, err
+		}
+		if fact.BrandID != brandID {
+			return nil, sql.ErrNoRows
+		}
+	}
+	return r.GetByID(factID)
+}
+
+// Delete removes a fact, scoped to its brand
+func (r *FactRepository) Delete(brandID, factID int) error {
+	_, err := r.db.Exec("DELETE FROM brand_facts WHERE id = ? AND brand_id = ?", factID, brandID)
+	return err
+}
+
+// CreateFinding stores a misinformation finding
+func (r *FactRepository) CreateFinding(finding *models.MisinformationFinding) error {
+	var factID sql.NullInt64
+	if finding.FactID > 0 {
+		factID = sql.NullInt64{Int64: int64(finding.FactID), Valid: true}
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO misinformation_findings (brand_id, ai_response_id, fact_id, fact_type, claim, expected, severity, snippet, model_name, detection_method) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		finding.BrandID, finding.AIResponseID, factID, finding.FactType, finding.Claim, finding.Expected,
+		finding.Severity, finding.Snippet, finding.ModelName, finding.DetectionMethod,
+	)
+	return err
+}
+
+// GetFindings returns a brand's misinformation findings since a date, newest first
+func (r *FactRepository) GetFindings(brandID int, since time.Time) ([]models.MisinformationFinding, error) {
+	rows, err := r.db.Query(
+		`SELECT id, brand_id, ai_response_id, COALESCE(fact_id, 0), fact_type, COALESCE(claim, ''), COALESCE(expected, ''),
+			severity, COALESCE(snippet, ''), COALESCE(model_name, ''), detection_method, created_at
+		FROM misinformation_findings
+		WHERE brand_id = ? AND created_at >= ?
+		ORDER BY created_at DESC, id DESC`,
+		brandID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []models.MisinformationFinding
+	for rows.Next() {
+		var f models.MisinformationFinding
+		if err := rows.Scan(&f.ID, &f.BrandID, &f.AIResponseID, &f.FactID, &f.FactType, &f.Claim, &f.Expected,
+			&f.Severity, &f.Snippet, &f.ModelName, &f.DetectionMethod, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
@@ -0,0 +1,16 @@
+-- Migration: Add LLM-as-judge mention classification
+-- Stores the classifier verdict next to the rule-based sentiment/recommendation labels
+
+USE ai_visibility_tracker;
+
+-- Classifier output per mention (NULL when the classifier did not run)
+ALTER TABLE mentions
+ADD COLUMN IF NOT EXISTS ai_sentiment ENUM('positive', 'neutral', 'negative') NULL,
+ADD COLUMN IF NOT EXISTS ai_confidence DECIMAL(5,4) NULL,
+ADD COLUMN IF NOT EXISTS ai_is_recommendation BOOLEAN NULL,
+ADD COLUMN IF NOT EXISTS ai_rationale TEXT NULL,
+ADD COLUMN IF NOT EXISTS classifier_model VARCHAR(100) NULL;
+
+-- Which label set drives the composite score for a brand
+ALTER TABLE brands
+ADD COLUMN IF NOT EXISTS sentiment_source ENUM('rules', 'llm') DEFAULT 'rules';
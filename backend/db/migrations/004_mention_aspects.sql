@@ -0,0 +1,22 @@
+-- Migration: Add aspect-based sentiment per mention
+-- Aspects keep brand_id and their own timestamp so perception history survives
+-- when a new analysis run replaces the stored responses and mentions
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS mention_aspects (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    mention_id INT NULL,
+    entity_name VARCHAR(255) NOT NULL,
+    entity_type ENUM('brand', 'competitor') NOT NULL,
+    aspect ENUM('pricing', 'ease_of_use', 'support', 'integrations', 'performance', 'security') NOT NULL,
+    sentiment ENUM('positive', 'neutral', 'negative') DEFAULT 'neutral',
+    snippet TEXT,
+    model_name VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (mention_id) REFERENCES mentions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_aspects_brand_date ON mention_aspects(brand_id, created_at);
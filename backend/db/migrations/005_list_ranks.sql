@@ -0,0 +1,8 @@
+-- Migration: Track list rank for every entity
+-- position_rank now holds the entity's rank in the response's recommended list
+-- (brands and competitors alike); list_length is the size of that list
+
+USE ai_visibility_tracker;
+
+ALTER TABLE mentions
+ADD COLUMN IF NOT EXISTS list_length INT DEFAULT 0;
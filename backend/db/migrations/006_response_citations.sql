@@ -0,0 +1,25 @@
+-- Migration: Store URLs, domains and named sources cited in AI responses
+-- Rows keep brand_id and survive when a new run replaces the stored responses,
+-- so the cited-domain report has history. ai_response_id/mention_id are plain
+-- references (no FK) so distinct-response counts stay correct after that cleanup.
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS response_citations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    ai_response_id INT NULL,
+    mention_id INT NULL,
+    url TEXT,
+    domain VARCHAR(255),
+    source_name VARCHAR(255),
+    position INT,
+    entity_name VARCHAR(255),
+    entity_type ENUM('brand', 'competitor') NULL,
+    model_name VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_citations_brand_date ON response_citations(brand_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_citations_response ON response_citations(ai_response_id);
@@ -0,0 +1,36 @@
+-- Migration: Add brand fact sheets and misinformation findings
+-- Findings keep brand_id and their own timestamp so they survive when a new
+-- analysis run replaces the stored responses
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS brand_facts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    fact_type ENUM('pricing', 'feature', 'integration', 'headquarters', 'founded') NOT NULL,
+    label VARCHAR(255) DEFAULT '',
+    value VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS misinformation_findings (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    ai_response_id INT NOT NULL,
+    fact_id INT NULL,
+    fact_type ENUM('pricing', 'feature', 'integration', 'headquarters', 'founded') NOT NULL,
+    claim TEXT,
+    expected TEXT,
+    severity ENUM('low', 'medium', 'high') DEFAULT 'medium',
+    snippet TEXT,
+    model_name VARCHAR(100),
+    detection_method ENUM('rules', 'llm') DEFAULT 'rules',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (fact_id) REFERENCES brand_facts(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_brand_facts_brand ON brand_facts(brand_id);
+CREATE INDEX IF NOT EXISTS idx_findings_brand_date ON misinformation_findings(brand_id, created_at);
@@ -0,0 +1,48 @@
+-- Migration: Add product-level tracking under a brand
+-- Mentions can be attributed to a product; product snapshots sit next to the
+-- brand-level metric snapshots
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS products (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_aliases (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    product_id INT NOT NULL,
+    alias VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+ALTER TABLE mentions
+    ADD COLUMN IF NOT EXISTS product_id INT NULL,
+    ADD COLUMN IF NOT EXISTS product_name VARCHAR(255) NULL;
+
+CREATE TABLE IF NOT EXISTS product_snapshots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    product_id INT NOT NULL,
+    visibility_score DECIMAL(5,2) DEFAULT 0,
+    mention_count INT DEFAULT 0,
+    positive_count INT DEFAULT 0,
+    neutral_count INT DEFAULT 0,
+    negative_count INT DEFAULT 0,
+    normalized_mention_rate DECIMAL(5,4) DEFAULT 0,
+    weighted_position_score DECIMAL(5,4) DEFAULT 0,
+    recommendation_rate DECIMAL(5,4) DEFAULT 0,
+    relative_sentiment_index DECIMAL(5,4) DEFAULT 0,
+    response_count INT DEFAULT 0,
+    snapshot_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_brand ON products(brand_id);
+CREATE INDEX IF NOT EXISTS idx_product_snapshots_product_date ON product_snapshots(product_id, snapshot_date);
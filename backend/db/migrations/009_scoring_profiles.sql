@@ -0,0 +1,32 @@
+-- Migration: Add per-brand scoring profiles
+-- A profile holds the composite score weights and the list position weights.
+-- Saving a profile adds a new version; snapshots record the version they were scored with.
+-- Brands without a profile use the built-in weights (version 0).
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS scoring_profiles (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    version INT NOT NULL,
+    weight_mention_rate DECIMAL(5,4) NOT NULL,
+    weight_position DECIMAL(5,4) NOT NULL,
+    weight_recommend DECIMAL(5,4) NOT NULL,
+    weight_sentiment DECIMAL(5,4) NOT NULL,
+    position_first DECIMAL(5,4) NOT NULL,
+    position_second DECIMAL(5,4) NOT NULL,
+    position_later DECIMAL(5,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_scoring_profile_version (brand_id, version),
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE
+);
+
+-- Rank counts let the position score be recomputed under new position weights.
+-- They are NULL for snapshots stored before this migration.
+ALTER TABLE metric_snapshots
+ADD COLUMN IF NOT EXISTS scoring_profile_version INT DEFAULT 0,
+ADD COLUMN IF NOT EXISTS rank_first_count INT NULL,
+ADD COLUMN IF NOT EXISTS rank_second_count INT NULL;
+
+ALTER TABLE product_snapshots
+ADD COLUMN IF NOT EXISTS scoring_profile_version INT DEFAULT 0;
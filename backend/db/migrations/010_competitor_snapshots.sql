@@ -0,0 +1,31 @@
+-- Migration: Add competitor composite score snapshots
+-- Each run scores every competitor with the brand's formula and profile; rows link
+-- to the brand snapshot of the same run so they can be ranked together
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS competitor_snapshots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    competitor_id INT NOT NULL,
+    metric_snapshot_id INT NULL,
+    visibility_score DECIMAL(5,2) DEFAULT 0,
+    mention_count INT DEFAULT 0,
+    positive_count INT DEFAULT 0,
+    neutral_count INT DEFAULT 0,
+    negative_count INT DEFAULT 0,
+    normalized_mention_rate DECIMAL(5,4) DEFAULT 0,
+    weighted_position_score DECIMAL(5,4) DEFAULT 0,
+    recommendation_rate DECIMAL(5,4) DEFAULT 0,
+    relative_sentiment_index DECIMAL(5,4) DEFAULT 0,
+    response_count INT DEFAULT 0,
+    scoring_profile_version INT DEFAULT 0,
+    snapshot_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE CASCADE,
+    FOREIGN KEY (metric_snapshot_id) REFERENCES metric_snapshots(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_competitor_snapshots_brand_date ON competitor_snapshots(brand_id, snapshot_date);
+CREATE INDEX IF NOT EXISTS idx_competitor_snapshots_metric ON competitor_snapshots(metric_snapshot_id);
@@ -0,0 +1,24 @@
+-- Migration: Add detected anomalies
+-- Anomalies flag unusual moves in the brand and competitor score series.
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS anomalies (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    metric_snapshot_id INT NULL,
+    kind VARCHAR(30) NOT NULL,     -- score_drop, score_spike, competitor_overtake
+    scope VARCHAR(20) NOT NULL,    -- brand, competitor
+    subject VARCHAR(255) NOT NULL DEFAULT '', -- Competitor name (empty for the brand)
+    value DECIMAL(8,4) DEFAULT 0,
+    baseline DECIMAL(8,4) DEFAULT 0,
+    z_score DECIMAL(8,4) DEFAULT 0,
+    message TEXT,
+    detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at TIMESTAMP NULL,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (metric_snapshot_id) REFERENCES metric_snapshots(id) ON DELETE SET NULL,
+    UNIQUE KEY uniq_anomaly_run (brand_id, metric_snapshot_id, kind, scope, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_anomalies_brand_detected ON anomalies(brand_id, detected_at);
@@ -0,0 +1,28 @@
+-- Migration: Add per-prompt-category snapshots
+-- Each run keeps the brand's score within every prompt category so metrics can be
+-- filtered by category
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS category_snapshots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    metric_snapshot_id INT NULL,
+    category VARCHAR(100) NOT NULL,
+    visibility_score DECIMAL(5,2) DEFAULT 0,
+    mention_count INT DEFAULT 0,
+    responses_with_brand INT DEFAULT 0,
+    normalized_mention_rate DECIMAL(5,4) DEFAULT 0,
+    weighted_position_score DECIMAL(5,4) DEFAULT 0,
+    recommendation_rate DECIMAL(5,4) DEFAULT 0,
+    relative_sentiment_index DECIMAL(5,4) DEFAULT 0,
+    response_count INT DEFAULT 0,
+    scoring_profile_version INT DEFAULT 0,
+    snapshot_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (metric_snapshot_id) REFERENCES metric_snapshots(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_snapshots_brand_date ON category_snapshots(brand_id, category, snapshot_date);
+CREATE INDEX IF NOT EXISTS idx_metric_snapshots_brand_created ON metric_snapshots(brand_id, created_at);
@@ -0,0 +1,27 @@
+-- Migration: Add per-prompt snapshots
+-- Each run keeps the brand's raw counts within every prompt's responses (with the prompt's
+-- category at the time) so prompts and categories can be drilled into over time
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS prompt_snapshots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    metric_snapshot_id INT NULL,
+    prompt_id INT NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    response_count INT DEFAULT 0,
+    responses_with_brand INT DEFAULT 0,
+    mention_count INT DEFAULT 0,
+    recommended_responses INT DEFAULT 0,
+    ranked_responses INT DEFAULT 0,    -- Responses where the brand appeared in a ranked list
+    rank_total INT DEFAULT 0,          -- Sum of the brand's best rank over those responses
+    sentiment_sum DECIMAL(10,4) DEFAULT 0, -- Sum of mention sentiment on the 1-5 scale
+    snapshot_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (prompt_id) REFERENCES prompts(id) ON DELETE CASCADE,
+    FOREIGN KEY (metric_snapshot_id) REFERENCES metric_snapshots(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_snapshots_brand_date ON prompt_snapshots(brand_id, snapshot_date);
@@ -0,0 +1,42 @@
+-- Migration: Add per-model snapshots and store model identifiers instead of display names
+-- Responses are replaced on every run, so each run keeps the brand's score within every
+-- AI model's responses to build per-model series from. Compare Models used to store each
+-- response under the model's display name, while the analysis service stores the
+-- provider's model identifier; rewrite the display names so a model's series groups every
+-- response under one name. Anomalies on these series use scope 'model' (kind model_silent
+-- when a model stops mentioning the brand).
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS model_snapshots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    metric_snapshot_id INT NULL,
+    model_name VARCHAR(100) NOT NULL,
+    visibility_score DECIMAL(5,2) DEFAULT 0,
+    mention_count INT DEFAULT 0,
+    responses_with_brand INT DEFAULT 0,
+    normalized_mention_rate DECIMAL(5,4) DEFAULT 0,
+    weighted_position_score DECIMAL(5,4) DEFAULT 0,
+    recommendation_rate DECIMAL(5,4) DEFAULT 0,
+    relative_sentiment_index DECIMAL(5,4) DEFAULT 0,
+    response_count INT DEFAULT 0,
+    scoring_profile_version INT DEFAULT 0,
+    snapshot_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE,
+    FOREIGN KEY (metric_snapshot_id) REFERENCES metric_snapshots(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_snapshots_brand_date ON model_snapshots(brand_id, snapshot_date);
+CREATE INDEX IF NOT EXISTS idx_model_snapshots_brand_model_date ON model_snapshots(brand_id, model_name, snapshot_date);
+CREATE INDEX IF NOT EXISTS idx_model_snapshots_metric ON model_snapshots(metric_snapshot_id);
+
+UPDATE ai_responses SET model_name = CASE model_name
+    WHEN 'Gemma 3 27B' THEN 'openrouter-google/gemma-3-27b-it:free'
+    WHEN 'Llama 3.3 70B' THEN 'openrouter-meta-llama/llama-3.3-70b-instruct:free'
+    WHEN 'Qwen3 Coder' THEN 'openrouter-qwen/qwen3-coder:free'
+    WHEN 'DeepSeek Chimera' THEN 'openrouter-tngtech/deepseek-r1t2-chimera:free'
+    WHEN 'Groq Llama 3.3' THEN 'groq-llama-3.3-70b'
+END
+WHERE model_name IN ('Gemma 3 27B', 'Llama 3.3 70B', 'Qwen3 Coder', 'DeepSeek Chimera', 'Groq Llama 3.3');
@@ -0,0 +1,8 @@
+-- Migration: Add forecast alerts
+-- A brand can also be alerted when its visibility forecast falls below its alert
+-- threshold within the next N weeks (0 = only alert on the latest score)
+
+USE ai_visibility_tracker;
+
+ALTER TABLE brands
+ADD COLUMN IF NOT EXISTS forecast_alert_weeks INT DEFAULT 0;
@@ -0,0 +1,19 @@
+-- Migration: Add timeline annotations
+-- Marketing events (content, launches, press...) marked on a brand's visibility timeline,
+-- so their effect on the score can be measured
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS annotations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    annotation_date DATE NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    type ENUM('content', 'launch', 'press', 'campaign', 'other') DEFAULT 'other',
+    url VARCHAR(2048) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_annotations_brand_date ON annotations(brand_id, annotation_date);
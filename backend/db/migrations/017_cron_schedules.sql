@@ -0,0 +1,19 @@
+-- Migration: Add cron schedules with a time zone per brand
+-- schedule_frequency 'cron' runs the brand's analysis on schedule_cron, evaluated in
+-- schedule_timezone (IANA name). next_run_at holds the exact time of the next
+-- scheduled run for every frequency.
+
+USE ai_visibility_tracker;
+
+ALTER TABLE brands
+ADD COLUMN IF NOT EXISTS schedule_cron VARCHAR(100) NULL,
+ADD COLUMN IF NOT EXISTS schedule_timezone VARCHAR(64) NULL,
+ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMP NULL;
+
+-- Existing daily and weekly schedules continue from their last run (or run on the next check)
+UPDATE brands SET next_run_at = COALESCE(DATE_ADD(last_scheduled_run, INTERVAL 1 DAY), NOW())
+WHERE schedule_frequency = 'daily' AND next_run_at IS NULL;
+UPDATE brands SET next_run_at = COALESCE(DATE_ADD(last_scheduled_run, INTERVAL 7 DAY), NOW())
+WHERE schedule_frequency = 'weekly' AND next_run_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_brands_next_run ON brands(next_run_at);
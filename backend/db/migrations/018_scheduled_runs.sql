@@ -0,0 +1,21 @@
+-- Migration: Add scheduled run history
+-- Every analysis the background scheduler starts is recorded with its outcome, so
+-- failed and skipped runs are visible instead of only logged
+
+USE ai_visibility_tracker;
+
+CREATE TABLE IF NOT EXISTS scheduled_runs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    brand_id INT NOT NULL,
+    scheduled_for TIMESTAMP NULL,
+    status ENUM('queued', 'running', 'succeeded', 'partial', 'failed', 'skipped') DEFAULT 'queued',
+    responses_run INT DEFAULT 0,
+    error TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_runs_created ON scheduled_runs(created_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_runs_status ON scheduled_runs(status);
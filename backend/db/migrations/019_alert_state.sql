@@ -0,0 +1,9 @@
+-- Migration: Remember each brand's last alert email
+-- last_alert_state is the condition the owner was last told about ('ok' once it cleared);
+-- an email is only sent when it changes or after last_alert_sent_at is a cooldown old.
+
+USE ai_visibility_tracker;
+
+ALTER TABLE brands
+ADD COLUMN IF NOT EXISTS last_alert_state VARCHAR(20) NULL,
+ADD COLUMN IF NOT EXISTS last_alert_sent_at TIMESTAMP NULL;
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// ModelSnapshotRepository handles per-model score snapshot database operations
+type ModelSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewModelSnapshotRepository creates a new model snapshot repository
+func NewModelSnapshotRepository() *ModelSnapshotRepository {
+	return &ModelSnapshotRepository{db: DB}
+}
+
+// modelSnapshotColumns is the column list shared by model snapshot queries (see scanModelSnapshot)
+const modelSnapshotColumns = `id, brand_id, COALESCE(metric_snapshot_id, 0), model_name,
+	visibility_score, mention_count, responses_with_brand,
+	normalized_mention_rate, weighted_position_score, recommendation_rate, relative_sentiment_index,
+	response_count, COALESCE(scoring_profile_version, 0), snapshot_date`
+
+// scanModelSnapshot scans a row selected with modelSnapshotColumns
+func scanModelSnapshot(row rowScanner) (models.ModelSnapshot, error) {
+	var s models.ModelSnapshot
+	err := row.Scan(&s.ID, &s.BrandID, &s.MetricSnapshotID, &s.ModelName,
+		&s.VisibilityScore, &s.MentionCount, &s.ResponsesWithBrand,
+		&s.NormalizedMentionRate, &s.WeightedPositionScore, &s.RecommendationRate, &s.RelativeSentimentIndex,
+		&s.ResponseCount, &s.ScoringProfileVersion, &s.SnapshotDate)
+	return s, err
+}
+
+// Create stores a model snapshot
+func (r *ModelSnapshotRepository) Create(snapshot *models.ModelSnapshot) error {
+	return insertModelSnapshot(r.db, snapshot)
+}
+
+// insertModelSnapshot inserts a model snapshot on the database or within a transaction
+func insertModelSnapshot(ex execer, snapshot *models.ModelSnapshot) error {
+	var metricSnapshotID sql.NullInt64
+	if snapshot.MetricSnapshotID > 0 {
+		metricSnapshotID = sql.NullInt64{Int64: int64(snapshot.MetricSnapshotID), Valid: true}
+	}
+	_, err := ex.Exec(
+		`INSERT INTO model_snapshots (
+			brand_id, metric_snapshot_id, model_name, visibility_score, mention_count, responses_with_brand,
+			normalized_mention_rate, weighted_position_score, recommendation_rate, relative_sentiment_index,
+			response_count, scoring_profile_version, snapshot_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.BrandID, metricSnapshotID, snapshot.ModelName, snapshot.VisibilityScore, snapshot.MentionCount, snapshot.ResponsesWithBrand,
+		snapshot.NormalizedMentionRate, snapshot.WeightedPositionScore, snapshot.RecommendationRate, snapshot.RelativeSentimentIndex,
+		snapshot.ResponseCount, snapshot.ScoringProfileVersion, snapshot.SnapshotDate,
+	)
+	return err
+}
+
+// GetSince returns the model snapshots of a brand since a date, oldest first
+func (r *ModelSnapshotRepository) GetSince(brandID int, since time.Time) ([]models.ModelSnapshot, error) {
+	return r.query(`
+		SELECT `+modelSnapshotColumns+`
+		FROM model_snapshots
+		WHERE brand_id = ? AND snapshot_date >= ?
+		ORDER BY snapshot_date ASC, id ASC`,
+		brandID, since,
+	)
+}
+
+// GetBetween returns a brand's snapshots of one model created within [from, to], oldest first
+func (r *ModelSnapshotRepository) GetBetween(brandID int, modelName string, from, to time.Time) ([]models.ModelSnapshot, error) {
+	return r.query(`
+		SELECT `+modelSnapshotColumns+`
+		FROM model_snapshots
+		WHERE brand_id = ? AND model_name = ? AND snapshot_date BETWEEN ? AND ?
+		ORDER BY snapshot_date ASC, id ASC`,
+		brandID, modelName, from, to,
+	)
+}
+
+// GetAllBetween returns a brand's snapshots of every model taken within [from, to], oldest first
+func (r *ModelSnapshotRepository) GetAllBetween(brandID int, from, to time.Time) ([]models.ModelSnapshot, error) {
+	return r.query(`
+		SELECT `+modelSnapshotColumns+`
+		FROM model_snapshots
+		WHERE brand_id = ? AND snapshot_date BETWEEN ? AND ?
+		ORDER BY snapshot_date ASC, id ASC`,
+		brandID, from, to,
+	)
+}
+
+// GetByMetricSnapshotID returns the model snapshots stored in the same run as a brand snapshot
+func (r *ModelSnapshotRepository) GetByMetricSnapshotID(metricSnapshotID int) ([]models.ModelSnapshot, error) {
+	return r.query(`
+		SELECT `+modelSnapshotColumns+`
+		FROM model_snapshots
+		WHERE metric_snapshot_id = ?
+		ORDER BY model_name ASC`,
+		metricSnapshotID,
+	)
+}
+
+// query runs a snapshot query and scans every row
+func (r *ModelSnapshotRepository) query(query string, args ...interface{}) ([]models.ModelSnapshot, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.ModelSnapshot
+	for rows.Next() {
+		s, err := scanModelSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
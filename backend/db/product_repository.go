@@ -0,0 +1,200 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// ProductRepository handles product database operations
+type ProductRepository struct {
+	db *sql.DB
+}
+
+// NewProductRepository creates a new product repository
+func NewProductRepository() *ProductRepository {
+	return &ProductRepository{db: DB}
+}
+
+// GetByBrandID retrieves all products of a brand with their aliases
+func (r *ProductRepository) GetByBrandID(brandID int) ([]models.Product, error) {
+	rows, err := r.db.Query("SELECT id, brand_id, name, created_at FROM products WHERE brand_id = ? ORDER BY id", brandID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	index := map[int]int{} // product ID -> position in products
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(&p.ID, &p.BrandID, &p.Name, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		index[p.ID] = len(products)
+		products = append(products, p)
+	}
+	if len(products) == 0 {
+		return products, nil
+	}
+
+	// Load every alias of the brand's products in one query
+	aliasRows, err := r.db.Query(`
+		SELECT pa.id, pa.product_id, pa.alias, pa.created_at
+		FROM product_aliases pa
+		JOIN products p ON p.id = pa.product_id
+		WHERE p.brand_id = ?
+		ORDER BY pa.id`,
+		brandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer aliasRows.Close()
+
+	for aliasRows.Next() {
+		var alias models.ProductAlias
+		if err := aliasRows.Scan(&alias.ID, &alias.ProductID, &alias.Alias, &alias.CreatedAt); err != nil {
+			return nil, err
+		}
+		if i, ok := index[alias.ProductID]; ok {
+			products[i].Aliases = append(products[i].Aliases, alias)
+		}
+	}
+
+	return products, nil
+}
+
+// GetByID retrieves a single product with its aliases
+func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
+	product := &models.Product{}
+	err := r.db.QueryRow("SELECT id, brand_id, name, created_at FROM products WHERE id = ?", id).
+		Scan(&product.ID, &product.BrandID, &product.Name, &product.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query("SELECT id, product_id, alias, created_at FROM product_aliases WHERE product_id = ? ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias models.ProductAlias
+		if err := rows.Scan(&alias.ID, &alias.ProductID, &alias.Alias, &alias.CreatedAt); err != nil {
+			return nil, err
+		}
+		product.Aliases = append(product.Aliases, alias)
+	}
+
+	return product, nil
+}
+
+// Create adds a product (and its aliases) to a brand
+func (r *ProductRepository) Create(brandID int, name string, aliases []string) (*models.Product, error) {
+	result, err := r.db.Exec("INSERT INTO products (brand_id, name) VALUES (?, ?)", brandID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	productID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, alias := range aliases {
+		if _, err := r.AddAlias(int(productID), alias); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.GetByID(int(productID))
+}
+
+// Delete removes a product, scoped to its brand
+func (r *ProductRepository) Delete(brandID, productID int) error {
+	_, err := r.db.Exec("DELETE FROM products WHERE id = ? AND brand_id = ?", productID, brandID)
+	return err
+}
+
+// AddAlias adds an alias to a product
+func (r *ProductRepository) AddAlias(productID int, alias string) (*models.ProductAlias, error) {
+	result, err := r.db.Exec("INSERT INTO product_aliases (product_id, alias) VALUES (?, ?)", productID, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	aliasID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProductAlias{
+		ID:        int(aliasID),
+		ProductID: productID,
+		Alias:     alias,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// RemoveAlias removes an alias from a product
+func (r *ProductRepository) RemoveAlias(productID, aliasID int) error {
+	_, err := r.db.Exec("DELETE FROM product_aliases WHERE id = ? AND product_id = ?", aliasID, productID)
+	return err
+}
+
+// CreateSnapshot stores a product metric snapshot
+func (r *ProductRepository) CreateSnapshot(snapshot *models.ProductSnapshot) error {
+	return insertProductSnapshot(r.db, snapshot)
+}
+
+// insertProductSnapshot inserts a product metric snapshot on the database or within a transaction
+func insertProductSnapshot(ex execer, snapshot *models.ProductSnapshot) error {
+	_, err := ex.Exec(
+		`INSERT INTO product_snapshots (
+			brand_id, product_id, visibility_score, mention_count,
+			positive_count, neutral_count, negative_count,
+			normalized_mention_rate, weighted_position_score, recommendation_rate, relative_sentiment_index,
+			response_count, snapshot_date, scoring_profile_version
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.BrandID, snapshot.ProductID, snapshot.VisibilityScore, snapshot.MentionCount,
+		snapshot.PositiveCount, snapshot.NeutralCount, snapshot.NegativeCount,
+		snapshot.NormalizedMentionRate, snapshot.WeightedPositionScore, snapshot.RecommendationRate, snapshot.RelativeSentimentIndex,
+		snapshot.ResponseCount, snapshot.SnapshotDate, snapshot.ScoringProfileVersion,
+	)
+	return err
+}
+
+// GetSnapshots returns the product snapshots of a brand since a date, oldest first
+func (r *ProductRepository) GetSnapshots(brandID int, since time.Time) ([]models.ProductSnapshot, error) {
+	rows, err := r.db.Query(`
+		SELECT ps.id, ps.brand_id, ps.product_id, p.name, ps.visibility_score, ps.mention_count,
+			ps.positive_count, ps.neutral_count, ps.negative_count,
+			ps.normalized_mention_rate, ps.weighted_position_score, ps.recommendation_rate, ps.relative_sentiment_index,
+			ps.response_count, ps.snapshot_date, COALESCE(ps.scoring_profile_version, 0)
+		FROM product_snapshots ps
+		JOIN products p ON p.id = ps.product_id
+		WHERE ps.brand_id = ? AND ps.snapshot_date >= ?
+		ORDER BY ps.snapshot_date ASC, ps.id ASC`,
+		brandID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.ProductSnapshot
+	for rows.Next() {
+		var s models.ProductSnapshot
+		if err := rows.Scan(&s.ID, &s.BrandID, &s.ProductID, &s.ProductName, &s.VisibilityScore, &s.MentionCount,
+			&s.PositiveCount, &s.NeutralCount, &s.NegativeCount,
+			&s.NormalizedMentionRate, &s.WeightedPositionScore, &s.RecommendationRate, &s.RelativeSentimentIndex,
+			&s.ResponseCount, &s.SnapshotDate, &s.ScoringProfileVersion); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
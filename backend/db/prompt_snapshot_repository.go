@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// PromptSnapshotRepository handles per-prompt snapshot database operations
+type PromptSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewPromptSnapshotRepository creates a new prompt snapshot repository
+func NewPromptSnapshotRepository() *PromptSnapshotRepository {
+	return &PromptSnapshotRepository{db: DB}
+}
+
+// promptSnapshotColumns is the column list shared by prompt snapshot queries (see scanPromptSnapshot)
+const promptSnapshotColumns = `id, brand_id, COALESCE(metric_snapshot_id, 0), prompt_id, category,
+	response_count, responses_with_brand, mention_count, recommended_responses,
+	ranked_responses, rank_total, sentiment_sum, snapshot_date`
+
+// scanPromptSnapshot scans a row selected with promptSnapshotColumns
+func scanPromptSnapshot(row rowScanner) (models.PromptSnapshot, error) {
+	var s models.PromptSnapshot
+	err := row.Scan(&s.ID, &s.BrandID, &s.MetricSnapshotID, &s.PromptID, &s.Category,
+		&s.ResponseCount, &s.ResponsesWithBrand, &s.MentionCount, &s.RecommendedResponses,
+		&s.RankedResponses, &s.RankTotal, &s.SentimentSum, &s.SnapshotDate)
+	return s, err
+}
+
+// Create stores a prompt snapshot
+func (r *PromptSnapshotRepository) Create(snapshot *models.PromptSnapshot) error {
+	return insertPromptSnapshot(r.db, snapshot)
+}
+
+// insertPromptSnapshot inserts a prompt snapshot on the database or within a transaction
+func insertPromptSnapshot(ex execer, snapshot *models.PromptSnapshot) error {
+	var metricSnapshotID sql.NullInt64
+	if snapshot.MetricSnapshotID > 0 {
+		metricSnapshotID = sql.NullInt64{Int64: int64(snapshot.MetricSnapshotID), Valid: true}
+	}
+	_, err := ex.Exec(
+		`INSERT INTO prompt_snapshots (
+			brand_id, metric_snapshot_id, prompt_id, category,
+			response_count, responses_with_brand, mention_count, recommended_responses,
+			ranked_responses, rank_total, sentiment_sum, snapshot_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.BrandID, metricSnapshotID, snapshot.PromptID, snapshot.Category,
+		snapshot.ResponseCount, snapshot.ResponsesWithBrand, snapshot.MentionCount, snapshot.RecommendedResponses,
+		snapshot.RankedResponses, snapshot.RankTotal, snapshot.SentimentSum, snapshot.SnapshotDate,
+	)
+	return err
+}
+
+// GetSince returns the prompt snapshots of a brand since a date, oldest first
+func (r *PromptSnapshotRepository) GetSince(brandID int, since time.Time) ([]models.PromptSnapshot, error) {
+	rows, err := r.db.Query(`
+		SELECT `+promptSnapshotColumns+`
+		FROM prompt_snapshots
+		WHERE brand_id = ? AND snapshot_date >= ?
+		ORDER BY snapshot_date ASC, id ASC`,
+		brandID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.PromptSnapshot
+	for rows.Next() {
+		s, err := scanPromptSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/Sneh16Shah/ai-visibility-tracker/models"
 )
@@ -37,6 +39,26 @@ func (r *PromptRepository) GetAll() ([]models.Prompt, error) {
 	return prompts, nil
 }
 
+// GetCategories maps every prompt ID, active or not, to its category
+func (r *PromptRepository) GetCategories() (map[int]string, error) {
+	rows, err := r.db.Query("SELECT id, category FROM prompts")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := map[int]string{}
+	for rows.Next() {
+		var id int
+		var category string
+		if err := rows.Scan(&id, &category); err != nil {
+			return nil, err
+		}
+		categories[id] = category
+	}
+	return categories, nil
+}
+
 // GetByID retrieves a prompt by ID
 func (r *PromptRepository) GetByID(id int) (*models.Prompt, error) {
 	prompt := &models.Prompt{}
@@ -126,22 +148,11 @@ func (r *AIResponseRepository) GetByID(id int) (*models.AIResponse, error) {
 	}
 
 	// Get mentions for this response
-	mentionRows, err := r.db.Query(
-		"SELECT id, ai_response_id, entity_name, entity_type, sentiment, context_snippet, position, created_at FROM mentions WHERE ai_response_id = ?",
-		id,
-	)
+	mentions, err := NewMentionRepository().GetByResponseID(id)
 	if err != nil {
 		return nil, err
 	}
-	defer mentionRows.Close()
-
-	for mentionRows.Next() {
-		var mention models.Mention
-		if err := mentionRows.Scan(&mention.ID, &mention.AIResponseID, &mention.EntityName, &mention.EntityType, &mention.Sentiment, &mention.ContextSnippet, &mention.Position, &mention.CreatedAt); err != nil {
-			return nil, err
-		}
-		response.Mentions = append(response.Mentions, mention)
-	}
+	response.Mentions = mentions
 
 	return response, nil
 }
@@ -168,6 +179,70 @@ func (r *AIResponseRepository) GetByBrandID(brandID int) ([]models.AIResponse, e
 	return responses, nil
 }
 
+// GetByBrandAndPromptID retrieves a brand's AI responses to one prompt, with their mentions
+func (r *AIResponseRepository) GetByBrandAndPromptID(brandID, promptID int) ([]models.AIResponse, error) {
+	rows, err := r.db.Query(
+		"SELECT id, brand_id, prompt_id, prompt_text, response_text, model_name, created_at FROM ai_responses WHERE brand_id = ? AND prompt_id = ? ORDER BY created_at DESC",
+		brandID, promptID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var responses []models.AIResponse
+	for rows.Next() {
+		var response models.AIResponse
+		if err := rows.Scan(&response.ID, &response.BrandID, &response.PromptID, &response.PromptText, &response.ResponseText, &response.ModelName, &response.CreatedAt); err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	rows.Close()
+
+	mentionRepo := NewMentionRepository()
+	for i := range responses {
+		mentions, err := mentionRepo.GetByResponseID(responses[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		responses[i].Mentions = mentions
+	}
+	return responses, nil
+}
+
+// GetByBrandIDBetween retrieves a brand's AI responses created within [from, to].
+// A zero from or to leaves that side of the range open.
+func (r *AIResponseRepository) GetByBrandIDBetween(brandID int, from, to time.Time) ([]models.AIResponse, error) {
+	query := "SELECT id, brand_id, prompt_id, prompt_text, response_text, model_name, created_at FROM ai_responses WHERE brand_id = ?"
+	args := []interface{}{brandID}
+	if !from.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		query += " AND created_at <= ?"
+		args = append(args, to)
+	}
+	query += " ORDER BY created_at ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var responses []models.AIResponse
+	for rows.Next() {
+		var response models.AIResponse
+		if err := rows.Scan(&response.ID, &response.BrandID, &response.PromptID, &response.PromptText, &response.ResponseText, &response.ModelName, &response.CreatedAt); err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
 // GetLatestRunByBrandID retrieves only AI responses from the most recent analysis run
 // (responses created within 5 minutes of the latest response)
 func (r *AIResponseRepository) GetLatestRunByBrandID(brandID int) ([]models.AIResponse, error) {
@@ -228,11 +303,48 @@ func NewMentionRepository() *MentionRepository {
 	return &MentionRepository{db: DB}
 }
 
+// mentionColumns is the column list shared by all mention queries (see scanMention)
+const mentionColumns = `id, ai_response_id, entity_name, entity_type, sentiment, context_snippet, position,
+	COALESCE(is_recommendation, FALSE), COALESCE(position_rank, 0), COALESCE(list_length, 0), created_at,
+	ai_sentiment, ai_confidence, ai_is_recommendation, ai_rationale, classifier_model,
+	COALESCE(product_id, 0), COALESCE(product_name, '')`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// scanMention scans a row selected with mentionColumns
+func scanMention(row rowScanner) (models.Mention, error) {
+	var mention models.Mention
+	var aiSentiment, aiRationale, classifierModel sql.NullString
+	var aiConfidence sql.NullFloat64
+	var aiIsRecommendation sql.NullBool
+	err := row.Scan(&mention.ID, &mention.AIResponseID, &mention.EntityName, &mention.EntityType, &mention.Sentiment,
+		&mention.ContextSnippet, &mention.Position, &mention.IsRecommendation, &mention.PositionRank, &mention.ListLength, &mention.CreatedAt,
+		&aiSentiment, &aiConfidence, &aiIsRecommendation, &aiRationale, &classifierModel,
+		&mention.ProductID, &mention.ProductName)
+	if err != nil {
+		return mention, err
+	}
+	mention.AISentiment = aiSentiment.String
+	mention.AIConfidence = aiConfidence.Float64
+	mention.AIIsRecommendation = aiIsRecommendation.Bool
+	mention.AIRationale = aiRationale.String
+	mention.ClassifierModel = classifierModel.String
+	return mention, nil
+}
+
 // Create creates a new mention
-func (r *MentionRepository) Create(aiResponseID int, entityName, entityType, sentiment, contextSnippet string, position int, isRecommendation bool, positionRank int) (*models.Mention, error) {
+func (r *MentionRepository) Create(aiResponseID int, entityName, entityType, sentiment, contextSnippet string, position int, isRecommendation bool, positionRank, listLength int) (*models.Mention, error) {
 	result, err := r.db.Exec(
-		"INSERT INTO mentions (ai_response_id, entity_name, entity_type, sentiment, context_snippet, position, is_recommendation, position_rank) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
-		aiResponseID, entityName, entityType, sentiment, contextSnippet, position, isRecommendation, positionRank,
+		"INSERT INTO mentions (ai_response_id, entity_name, entity_type, sentiment, context_snippet, position, is_recommendation, position_rank, list_length) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		aiResponseID, entityName, entityType, sentiment, contextSnippet, position, isRecommendation, positionRank, listLength,
 	)
 	if err != nil {
 		return nil, err
@@ -243,19 +355,40 @@ func (r *MentionRepository) Create(aiResponseID int, entityName, entityType, sen
 		return nil, err
 	}
 
-	mention := &models.Mention{}
-	err = r.db.QueryRow(
-		"SELECT id, ai_response_id, entity_name, entity_type, sentiment, context_snippet, position, COALESCE(is_recommendation, FALSE), COALESCE(position_rank, 0), created_at FROM mentions WHERE id = ?",
-		mentionID,
-	).Scan(&mention.ID, &mention.AIResponseID, &mention.EntityName, &mention.EntityType, &mention.Sentiment, &mention.ContextSnippet, &mention.Position, &mention.IsRecommendation, &mention.PositionRank, &mention.CreatedAt)
+	return r.GetByID(int(mentionID))
+}
 
-	return mention, err
+// GetByID retrieves a mention by ID
+func (r *MentionRepository) GetByID(id int) (*models.Mention, error) {
+	mention, err := scanMention(r.db.QueryRow("SELECT "+mentionColumns+" FROM mentions WHERE id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	return &mention, nil
+}
+
+// UpdateClassification stores the LLM-as-judge verdict for a mention
+func (r *MentionRepository) UpdateClassification(mentionID int, sentiment string, confidence float64, isRecommendation bool, rationale, classifierModel string) error {
+	_, err := r.db.Exec(
+		"UPDATE mentions SET ai_sentiment = ?, ai_confidence = ?, ai_is_recommendation = ?, ai_rationale = ?, classifier_model = ? WHERE id = ?",
+		sentiment, confidence, isRecommendation, rationale, classifierModel, mentionID,
+	)
+	return err
+}
+
+// UpdateProduct attributes a mention to one of the brand's products
+func (r *MentionRepository) UpdateProduct(mentionID, productID int, productName string) error {
+	_, err := r.db.Exec(
+		"UPDATE mentions SET product_id = ?, product_name = ? WHERE id = ?",
+		productID, productName, mentionID,
+	)
+	return err
 }
 
 // GetByResponseID gets all mentions for an AI response
 func (r *MentionRepository) GetByResponseID(aiResponseID int) ([]models.Mention, error) {
 	rows, err := r.db.Query(
-		"SELECT id, ai_response_id, entity_name, entity_type, sentiment, context_snippet, position, COALESCE(is_recommendation, FALSE), COALESCE(position_rank, 0), created_at FROM mentions WHERE ai_response_id = ?",
+		"SELECT "+mentionColumns+" FROM mentions WHERE ai_response_id = ?",
 		aiResponseID,
 	)
 	if err != nil {
@@ -265,8 +398,8 @@ func (r *MentionRepository) GetByResponseID(aiResponseID int) ([]models.Mention,
 
 	var mentions []models.Mention
 	for rows.Next() {
-		var mention models.Mention
-		if err := rows.Scan(&mention.ID, &mention.AIResponseID, &mention.EntityName, &mention.EntityType, &mention.Sentiment, &mention.ContextSnippet, &mention.Position, &mention.IsRecommendation, &mention.PositionRank, &mention.CreatedAt); err != nil {
+		mention, err := scanMention(rows)
+		if err != nil {
 			return nil, err
 		}
 		mentions = append(mentions, mention)
@@ -274,6 +407,56 @@ func (r *MentionRepository) GetByResponseID(aiResponseID int) ([]models.Mention,
 	return mentions, nil
 }
 
+// ContextMention is a mention with the model and prompt category of its response
+type ContextMention struct {
+	models.Mention
+	ModelName      string
+	PromptCategory string
+}
+
+// withExtra appends extra scan targets after the ones a scan helper passes
+type withExtra struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+// Scan implements rowScanner
+func (w withExtra) Scan(dest ...interface{}) error {
+	return w.row.Scan(append(dest, w.extra...)...)
+}
+
+// GetByBrandIDSince gets every mention in a brand's responses created at or after a time,
+// with the response's model and prompt category, in one query
+func (r *MentionRepository) GetByBrandIDSince(brandID int, since time.Time) ([]ContextMention, error) {
+	rows, err := r.db.Query(
+		`SELECT `+mentionColumns+`, model_name, prompt_category FROM (
+			SELECT m.*, r.model_name, COALESCE(p.category, '') AS prompt_category
+			FROM mentions m
+			JOIN ai_responses r ON r.id = m.ai_response_id
+			LEFT JOIN prompts p ON p.id = r.prompt_id
+			WHERE r.brand_id = ? AND r.created_at >= ?
+		) AS windowed
+		ORDER BY ai_response_id, position`,
+		brandID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mentions []ContextMention
+	for rows.Next() {
+		var cm ContextMention
+		mention, err := scanMention(withExtra{row: rows, extra: []interface{}{&cm.ModelName, &cm.PromptCategory}})
+		if err != nil {
+			return nil, err
+		}
+		cm.Mention = mention
+		mentions = append(mentions, cm)
+	}
+	return mentions, nil
+}
+
 // MetricRepository handles metric database operations
 type MetricRepository struct {
 	db *sql.DB
@@ -291,12 +474,14 @@ func (r *MetricRepository) Create(snapshot *models.MetricSnapshot) (*models.Metr
 			brand_id, visibility_score, citation_share, mention_count, 
 			positive_count, neutral_count, negative_count, snapshot_date,
 			normalized_mention_rate, weighted_position_score, recommendation_rate, relative_sentiment_index,
-			confidence_score, confidence_level, response_count, category_avg_sentiment
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			confidence_score, confidence_level, response_count, category_avg_sentiment,
+			scoring_profile_version, rank_first_count, rank_second_count
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		snapshot.BrandID, snapshot.VisibilityScore, snapshot.CitationShare, snapshot.MentionCount,
 		snapshot.PositiveCount, snapshot.NeutralCount, snapshot.NegativeCount, snapshot.SnapshotDate,
 		snapshot.NormalizedMentionRate, snapshot.WeightedPositionScore, snapshot.RecommendationRate, snapshot.RelativeSentimentIndex,
 		snapshot.ConfidenceScore, snapshot.ConfidenceLevel, snapshot.ResponseCount, snapshot.CategoryAvgSentiment,
+		snapshot.ScoringProfileVersion, snapshot.RankFirstCount, snapshot.RankSecondCount,
 	)
 	if err != nil {
 		return nil, err
@@ -313,10 +498,31 @@ func (r *MetricRepository) Create(snapshot *models.MetricSnapshot) (*models.Metr
 // GetByID retrieves a metric snapshot by ID
 func (r *MetricRepository) GetByID(id int) (*models.MetricSnapshot, error) {
 	snapshot := &models.MetricSnapshot{}
+	var confidenceLevel sql.NullString
 	err := r.db.QueryRow(
-		"SELECT id, brand_id, visibility_score, citation_share, mention_count, positive_count, neutral_count, negative_count, snapshot_date, created_at FROM metric_snapshots WHERE id = ?",
+		`SELECT id, brand_id, visibility_score, citation_share, mention_count,
+			positive_count, neutral_count, negative_count, snapshot_date, created_at,
+			COALESCE(normalized_mention_rate, 0), COALESCE(weighted_position_score, 0),
+			COALESCE(recommendation_rate, 0), COALESCE(relative_sentiment_index, 0),
+			COALESCE(confidence_score, 0), confidence_level,
+			COALESCE(response_count, 0), COALESCE(category_avg_sentiment, 0),
+			COALESCE(scoring_profile_version, 0)
+		FROM metric_snapshots WHERE id = ?`,
 		id,
-	).Scan(&snapshot.ID, &snapshot.BrandID, &snapshot.VisibilityScore, &snapshot.CitationShare, &snapshot.MentionCount, &snapshot.PositiveCount, &snapshot.NeutralCount, &snapshot.NegativeCount, &snapshot.SnapshotDate, &snapshot.CreatedAt)
+	).Scan(&snapshot.ID, &snapshot.BrandID, &snapshot.VisibilityScore, &snapshot.CitationShare,
+		&snapshot.MentionCount, &snapshot.PositiveCount, &snapshot.NeutralCount, &snapshot.NegativeCount,
+		&snapshot.SnapshotDate, &snapshot.CreatedAt,
+		&snapshot.NormalizedMentionRate, &snapshot.WeightedPositionScore,
+		&snapshot.RecommendationRate, &snapshot.RelativeSentimentIndex,
+		&snapshot.ConfidenceScore, &confidenceLevel,
+		&snapshot.ResponseCount, &snapshot.CategoryAvgSentiment,
+		&snapshot.ScoringProfileVersion)
+
+	if confidenceLevel.Valid {
+		snapshot.ConfidenceLevel = confidenceLevel.String
+	} else {
+		snapshot.ConfidenceLevel = "medium"
+	}
 	return snapshot, err
 }
 
@@ -330,8 +536,9 @@ func (r *MetricRepository) GetLatestByBrandID(brandID int) (*models.MetricSnapsh
 			COALESCE(normalized_mention_rate, 0), COALESCE(weighted_position_score, 0), 
 			COALESCE(recommendation_rate, 0), COALESCE(relative_sentiment_index, 0),
 			COALESCE(confidence_score, 0), confidence_level, 
-			COALESCE(response_count, 0), COALESCE(category_avg_sentiment, 0)
-		FROM metric_snapshots WHERE brand_id = ? ORDER BY snapshot_date DESC LIMIT 1`,
+			COALESCE(response_count, 0), COALESCE(category_avg_sentiment, 0),
+			COALESCE(scoring_profile_version, 0)
+		FROM metric_snapshots WHERE brand_id = ? ORDER BY snapshot_date DESC, id DESC LIMIT 1`,
 		brandID,
 	).Scan(&snapshot.ID, &snapshot.BrandID, &snapshot.VisibilityScore, &snapshot.CitationShare,
 		&snapshot.MentionCount, &snapshot.PositiveCount, &snapshot.NeutralCount, &snapshot.NegativeCount,
@@ -339,7 +546,8 @@ func (r *MetricRepository) GetLatestByBrandID(brandID int) (*models.MetricSnapsh
 		&snapshot.NormalizedMentionRate, &snapshot.WeightedPositionScore,
 		&snapshot.RecommendationRate, &snapshot.RelativeSentimentIndex,
 		&snapshot.ConfidenceScore, &confidenceLevel,
-		&snapshot.ResponseCount, &snapshot.CategoryAvgSentiment)
+		&snapshot.ResponseCount, &snapshot.CategoryAvgSentiment,
+		&snapshot.ScoringProfileVersion)
 
 	if confidenceLevel.Valid {
 		snapshot.ConfidenceLevel = confidenceLevel.String
@@ -349,6 +557,316 @@ func (r *MetricRepository) GetLatestByBrandID(brandID int) (*models.MetricSnapsh
 	return snapshot, err
 }
 
+// GetCreatedSince retrieves a brand's snapshots created at or after a time, oldest first
+func (r *MetricRepository) GetCreatedSince(brandID int, since time.Time) ([]models.MetricSnapshot, error) {
+	rows, err := r.db.Query(
+		"SELECT id, brand_id, visibility_score, snapshot_date, created_at FROM metric_snapshots WHERE brand_id = ? AND created_at >= ? ORDER BY created_at ASC",
+		brandID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.MetricSnapshot
+	for rows.Next() {
+		var snapshot models.MetricSnapshot
+		if err := rows.Scan(&snapshot.ID, &snapshot.BrandID, &snapshot.VisibilityScore, &snapshot.SnapshotDate, &snapshot.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// SnapshotChildren holds the product, competitor, model, category and prompt snapshots
+// stored with a brand snapshot
+type SnapshotChildren struct {
+	Products    []models.ProductSnapshot
+	Competitors []models.CompetitorSnapshot
+	Models      []models.ModelSnapshot
+	Categories  []models.CategorySnapshot
+	Prompts     []models.PromptSnapshot
+}
+
+// ReplaceSnapshot overwrites the computed values of an existing snapshot, keeping its date
+// and confidence (which depends on the snapshots before it), and replaces its child
+// snapshots, all in one transaction. Product snapshots have no link to the brand
+// snapshot and are matched on its date.
+func (r *MetricRepository) ReplaceSnapshot(snapshot *models.MetricSnapshot, children SnapshotChildren) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE metric_snapshots SET
+			visibility_score = ?, citation_share = ?, mention_count = ?,
+			positive_count = ?, neutral_count = ?, negative_count = ?,
+			normalized_mention_rate = ?, weighted_position_score = ?, recommendation_rate = ?, relative_sentiment_index = ?,
+			response_count = ?, category_avg_sentiment = ?,
+			scoring_profile_version = ?, rank_first_count = ?, rank_second_count = ?
+		WHERE id = ?`,
+		snapshot.VisibilityScore, snapshot.CitationShare, snapshot.MentionCount,
+		snapshot.PositiveCount, snapshot.NeutralCount, snapshot.NegativeCount,
+		snapshot.NormalizedMentionRate, snapshot.WeightedPositionScore, snapshot.RecommendationRate, snapshot.RelativeSentimentIndex,
+		snapshot.ResponseCount, snapshot.CategoryAvgSentiment,
+		snapshot.ScoringProfileVersion, snapshot.RankFirstCount, snapshot.RankSecondCount,
+		snapshot.ID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, table := range []string{"competitor_snapshots", "model_snapshots", "category_snapshots", "prompt_snapshots"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE brand_id = ? AND metric_snapshot_id = ?", snapshot.BrandID, snapshot.ID); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM product_snapshots WHERE brand_id = ? AND snapshot_date = ?", snapshot.BrandID, snapshot.SnapshotDate); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("product_snapshots: %w", err)
+	}
+
+	for i := range children.Products {
+		if err := insertProductSnapshot(tx, &children.Products[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("product_snapshots: %w", err)
+		}
+	}
+	for i := range children.Competitors {
+		if err := insertCompetitorSnapshot(tx, &children.Competitors[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("competitor_snapshots: %w", err)
+		}
+	}
+	for i := range children.Models {
+		if err := insertModelSnapshot(tx, &children.Models[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("model_snapshots: %w", err)
+		}
+	}
+	for i := range children.Categories {
+		if err := insertCategorySnapshot(tx, &children.Categories[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("category_snapshots: %w", err)
+		}
+	}
+	for i := range children.Prompts {
+		if err := insertPromptSnapshot(tx, &children.Prompts[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("prompt_snapshots: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetComponentsByBrandID retrieves every snapshot of a brand with the components and
+// rank counts needed to rescore it, oldest first
+func (r *MetricRepository) GetComponentsByBrandID(brandID int) ([]models.MetricSnapshot, error) {
+	rows, err := r.db.Query(
+		`SELECT id, brand_id, visibility_score, snapshot_date, created_at,
+			COALESCE(normalized_mention_rate, 0), COALESCE(weighted_position_score, 0),
+			COALESCE(recommendation_rate, 0), COALESCE(relative_sentiment_index, 0),
+			COALESCE(response_count, 0), COALESCE(scoring_profile_version, 0),
+			rank_first_count, rank_second_count
+		FROM metric_snapshots WHERE brand_id = ? ORDER BY snapshot_date ASC, id ASC`,
+		brandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.MetricSnapshot
+	for rows.Next() {
+		var snapshot models.MetricSnapshot
+		var rankFirst, rankSecond sql.NullInt64
+		if err := rows.Scan(&snapshot.ID, &snapshot.BrandID, &snapshot.VisibilityScore, &snapshot.SnapshotDate, &snapshot.CreatedAt,
+			&snapshot.NormalizedMentionRate, &snapshot.WeightedPositionScore,
+			&snapshot.RecommendationRate, &snapshot.RelativeSentimentIndex,
+			&snapshot.ResponseCount, &snapshot.ScoringProfileVersion,
+			&rankFirst, &rankSecond); err != nil {
+			return nil, err
+		}
+		if rankFirst.Valid && rankSecond.Valid {
+			first, second := int(rankFirst.Int64), int(rankSecond.Int64)
+			snapshot.RankFirstCount = &first
+			snapshot.RankSecondCount = &second
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// RescoreCounts is how many child snapshots of each kind a rescore updated
+type RescoreCounts struct {
+	Products    int
+	Competitors int
+	Models      int
+	Categories  int
+}
+
+// Rescore stores the brand's snapshots rescored under a new profile and recomputes its
+// product, competitor, model and category snapshots from their stored components, all
+// in one transaction. Child rank counts are not kept, so their position scores stay as stored.
+func (r *MetricRepository) Rescore(brandID int, snapshots []models.MetricSnapshot, profile *models.ScoringProfile) (RescoreCounts, error) {
+	var counts RescoreCounts
+	tx, err := r.db.Begin()
+	if err != nil {
+		return counts, err
+	}
+
+	for _, snapshot := range snapshots {
+		_, err := tx.Exec(
+			"UPDATE metric_snapshots SET visibility_score = ?, weighted_position_score = ?, scoring_profile_version = ? WHERE id = ?",
+			snapshot.VisibilityScore, snapshot.WeightedPositionScore, snapshot.ScoringProfileVersion, snapshot.ID,
+		)
+		if err != nil {
+			tx.Rollback()
+			return counts, err
+		}
+	}
+
+	children := []struct {
+		table string
+		count *int
+	}{
+		{"product_snapshots", &counts.Products},
+		{"competitor_snapshots", &counts.Competitors},
+		{"model_snapshots", &counts.Models},
+		{"category_snapshots", &counts.Categories},
+	}
+	for _, child := range children {
+		result, err := tx.Exec(
+			`UPDATE `+child.table+` SET
+				visibility_score = (? * normalized_mention_rate + ? * weighted_position_score + ? * recommendation_rate + ? * relative_sentiment_index) * 100,
+				scoring_profile_version = ?
+			WHERE brand_id = ?`,
+			profile.WeightMentionRate, profile.WeightPosition, profile.WeightRecommend, profile.WeightSentiment,
+			profile.Version, brandID,
+		)
+		if err != nil {
+			tx.Rollback()
+			return counts, fmt.Errorf("%s: %w", child.table, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return counts, fmt.Errorf("%s: %w", child.table, err)
+		}
+		*child.count = int(affected)
+	}
+
+	return counts, tx.Commit()
+}
+
+// GetBetween retrieves a brand's snapshots created within [from, to], oldest first
+func (r *MetricRepository) GetBetween(brandID int, from, to time.Time) ([]models.MetricSnapshot, error) {
+	rows, err := r.db.Query(
+		`SELECT id, brand_id, visibility_score, citation_share, mention_count,
+			positive_count, neutral_count, negative_count, snapshot_date, created_at,
+			COALESCE(normalized_mention_rate, 0), COALESCE(weighted_position_score, 0),
+			COALESCE(recommendation_rate, 0), COALESCE(relative_sentiment_index, 0),
+			COALESCE(confidence_score, 0), confidence_level,
+			COALESCE(response_count, 0), COALESCE(category_avg_sentiment, 0),
+			COALESCE(scoring_profile_version, 0)
+		FROM metric_snapshots WHERE brand_id = ? AND created_at BETWEEN ? AND ?
+		ORDER BY created_at ASC, id ASC`,
+		brandID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.MetricSnapshot
+	for rows.Next() {
+		var snapshot models.MetricSnapshot
+		var confidenceLevel sql.NullString
+		if err := rows.Scan(&snapshot.ID, &snapshot.BrandID, &snapshot.VisibilityScore, &snapshot.CitationShare,
+			&snapshot.MentionCount, &snapshot.PositiveCount, &snapshot.NeutralCount, &snapshot.NegativeCount,
+			&snapshot.SnapshotDate, &snapshot.CreatedAt,
+			&snapshot.NormalizedMentionRate, &snapshot.WeightedPositionScore,
+			&snapshot.RecommendationRate, &snapshot.RelativeSentimentIndex,
+			&snapshot.ConfidenceScore, &confidenceLevel,
+			&snapshot.ResponseCount, &snapshot.CategoryAvgSentiment,
+			&snapshot.ScoringProfileVersion); err != nil {
+			return nil, err
+		}
+		snapshot.ConfidenceLevel = "medium"
+		if confidenceLevel.Valid {
+			snapshot.ConfidenceLevel = confidenceLevel.String
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// GetLatestByUserID retrieves the latest snapshot created at or before asOf of each of a
+// user's brands, by brand ID
+func (r *MetricRepository) GetLatestByUserID(userID int, asOf time.Time) (map[int]models.MetricSnapshot, error) {
+	return r.getLatestPerBrand("b.user_id = ? AND ms.created_at <= ?", userID, asOf)
+}
+
+// GetLatestByIndustry retrieves the latest snapshot of every brand of an industry, across all
+// users, by brand ID. Industries match case-insensitively, ignoring surrounding spaces.
+func (r *MetricRepository) GetLatestByIndustry(industry string) (map[int]models.MetricSnapshot, error) {
+	return r.getLatestPerBrand("LOWER(TRIM(b.industry)) = LOWER(TRIM(?))", industry)
+}
+
+// getLatestPerBrand retrieves the latest snapshot of each brand matching a condition on
+// brands b and metric_snapshots ms. Snapshot IDs grow with creation time, so the highest one is the latest.
+func (r *MetricRepository) getLatestPerBrand(condition string, args ...interface{}) (map[int]models.MetricSnapshot, error) {
+	rows, err := r.db.Query(
+		`SELECT m.id, m.brand_id, m.visibility_score, m.citation_share, m.mention_count,
+			m.positive_count, m.neutral_count, m.negative_count, m.snapshot_date, m.created_at,
+			COALESCE(m.normalized_mention_rate, 0), COALESCE(m.weighted_position_score, 0),
+			COALESCE(m.recommendation_rate, 0), COALESCE(m.relative_sentiment_index, 0),
+			COALESCE(m.confidence_score, 0), m.confidence_level,
+			COALESCE(m.response_count, 0), COALESCE(m.category_avg_sentiment, 0),
+			COALESCE(m.scoring_profile_version, 0)
+		FROM metric_snapshots m
+		JOIN (
+			SELECT ms.brand_id, MAX(ms.id) AS id
+			FROM metric_snapshots ms
+			JOIN brands b ON b.id = ms.brand_id
+			WHERE `+condition+`
+			GROUP BY ms.brand_id
+		) latest ON latest.id = m.id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := map[int]models.MetricSnapshot{}
+	for rows.Next() {
+		var snapshot models.MetricSnapshot
+		var confidenceLevel sql.NullString
+		if err := rows.Scan(&snapshot.ID, &snapshot.BrandID, &snapshot.VisibilityScore, &snapshot.CitationShare,
+			&snapshot.MentionCount, &snapshot.PositiveCount, &snapshot.NeutralCount, &snapshot.NegativeCount,
+			&snapshot.SnapshotDate, &snapshot.CreatedAt,
+			&snapshot.NormalizedMentionRate, &snapshot.WeightedPositionScore,
+			&snapshot.RecommendationRate, &snapshot.RelativeSentimentIndex,
+			&snapshot.ConfidenceScore, &confidenceLevel,
+			&snapshot.ResponseCount, &snapshot.CategoryAvgSentiment,
+			&snapshot.ScoringProfileVersion); err != nil {
+			return nil, err
+		}
+		snapshot.ConfidenceLevel = "medium"
+		if confidenceLevel.Valid {
+			snapshot.ConfidenceLevel = confidenceLevel.String
+		}
+		snapshots[snapshot.BrandID] = snapshot
+	}
+	return snapshots, nil
+}
+
 // GetTrendsByBrandID retrieves metric trends for a brand (last N snapshots)
 func (r *MetricRepository) GetTrendsByBrandID(brandID int, days int) ([]models.MetricSnapshot, error) {
 	rows, err := r.db.Query(
@@ -357,8 +875,9 @@ func (r *MetricRepository) GetTrendsByBrandID(brandID int, days int) ([]models.M
 			COALESCE(normalized_mention_rate, 0), COALESCE(weighted_position_score, 0), 
 			COALESCE(recommendation_rate, 0), COALESCE(relative_sentiment_index, 0),
 			COALESCE(confidence_score, 0), confidence_level, 
-			COALESCE(response_count, 0), COALESCE(category_avg_sentiment, 0)
-		FROM metric_snapshots WHERE brand_id = ? ORDER BY snapshot_date DESC LIMIT ?`,
+			COALESCE(response_count, 0), COALESCE(category_avg_sentiment, 0),
+			COALESCE(scoring_profile_version, 0)
+		FROM metric_snapshots WHERE brand_id = ? ORDER BY snapshot_date DESC, id DESC LIMIT ?`,
 		brandID, days,
 	)
 	if err != nil {
@@ -376,7 +895,8 @@ func (r *MetricRepository) GetTrendsByBrandID(brandID int, days int) ([]models.M
 			&snapshot.NormalizedMentionRate, &snapshot.WeightedPositionScore,
 			&snapshot.RecommendationRate, &snapshot.RelativeSentimentIndex,
 			&snapshot.ConfidenceScore, &confidenceLevel,
-			&snapshot.ResponseCount, &snapshot.CategoryAvgSentiment); err != nil {
+			&snapshot.ResponseCount, &snapshot.CategoryAvgSentiment,
+			&snapshot.ScoringProfileVersion); err != nil {
 			return nil, err
 		}
 		if confidenceLevel.Valid {
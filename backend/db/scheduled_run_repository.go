@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// ScheduledRunRepository handles scheduled run history database operations
+type ScheduledRunRepository struct {
+	db *sql.DB
+}
+
+// NewScheduledRunRepository creates a new scheduled run repository
+func NewScheduledRunRepository() *ScheduledRunRepository {
+	return &ScheduledRunRepository{db: DB}
+}
+
+// Create records a queued run of a brand due at scheduledFor and returns its ID
+func (r *ScheduledRunRepository) Create(brandID int, scheduledFor *time.Time) (int, error) {
+	result, err := r.db.Exec(
+		"INSERT INTO scheduled_runs (brand_id, scheduled_for, status) VALUES (?, ?, ?)",
+		brandID, scheduledFor, models.RunQueued,
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// MarkStarted moves a queued run to running
+func (r *ScheduledRunRepository) MarkStarted(id int) error {
+	_, err := r.db.Exec(
+		"UPDATE scheduled_runs SET status = ?, started_at = NOW() WHERE id = ?",
+		models.RunRunning, id,
+	)
+	return err
+}
+
+// Finish records a run's outcome
+func (r *ScheduledRunRepository) Finish(id int, status string, responsesRun int, errorMessage string) error {
+	_, err := r.db.Exec(
+		"UPDATE scheduled_runs SET status = ?, responses_run = ?, error = ?, finished_at = NOW() WHERE id = ?",
+		status, responsesRun, nullableString(errorMessage), id,
+	)
+	return err
+}
+
+// FailInterrupted marks runs left queued or running by a previous process as failed. Runs are
+// not tied to an instance, so this assumes a single scheduler per database.
+func (r *ScheduledRunRepository) FailInterrupted() (int, error) {
+	result, err := r.db.Exec(
+		"UPDATE scheduled_runs SET status = ?, error = 'interrupted by server restart', finished_at = NOW() WHERE status IN (?, ?)",
+		models.RunFailed, models.RunQueued, models.RunRunning,
+	)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// GetRecent returns the latest scheduled runs of every brand, newest first
+func (r *ScheduledRunRepository) GetRecent(limit int) ([]models.ScheduledRun, error) {
+	rows, err := r.db.Query(`
+		SELECT sr.id, sr.brand_id, b.name, sr.scheduled_for, sr.status, COALESCE(sr.responses_run, 0),
+			COALESCE(sr.error, ''), sr.created_at, sr.started_at, sr.finished_at
+		FROM scheduled_runs sr
+		JOIN brands b ON b.id = sr.brand_id
+		ORDER BY sr.id DESC
+		LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.ScheduledRun
+	for rows.Next() {
+		var run models.ScheduledRun
+		var scheduledFor, startedAt, finishedAt sql.NullTime
+		if err := rows.Scan(&run.ID, &run.BrandID, &run.BrandName, &scheduledFor, &run.Status, &run.ResponsesRun,
+			&run.Error, &run.CreatedAt, &startedAt, &finishedAt); err != nil {
+			return nil, err
+		}
+		if scheduledFor.Valid {
+			run.ScheduledFor = &scheduledFor.Time
+		}
+		if startedAt.Valid {
+			run.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			run.FinishedAt = &finishedAt.Time
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// ScoringProfileRepository handles scoring profile database operations
+type ScoringProfileRepository struct {
+	db *sql.DB
+}
+
+// NewScoringProfileRepository creates a new scoring profile repository
+func NewScoringProfileRepository() *ScoringProfileRepository {
+	return &ScoringProfileRepository{db: DB}
+}
+
+const scoringProfileColumns = `id, brand_id, version, weight_mention_rate, weight_position, weight_recommend, weight_sentiment,
+	position_first, position_second, position_later, created_at`
+
+// scanScoringProfile reads one scoring_profiles row
+func scanScoringProfile(row rowScanner) (*models.ScoringProfile, error) {
+	p := &models.ScoringProfile{}
+	err := row.Scan(&p.ID, &p.BrandID, &p.Version, &p.WeightMentionRate, &p.WeightPosition, &p.WeightRecommend, &p.WeightSentiment,
+		&p.PositionFirst, &p.PositionSecond, &p.PositionLater, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetActive retrieves the brand's highest profile version (sql.ErrNoRows when it has none)
+func (r *ScoringProfileRepository) GetActive(brandID int) (*models.ScoringProfile, error) {
+	return scanScoringProfile(r.db.QueryRow(
+		"SELECT "+scoringProfileColumns+" FROM scoring_profiles WHERE brand_id = ? ORDER BY version DESC LIMIT 1",
+		brandID,
+	))
+}
+
+// GetVersions retrieves every profile version of a brand, newest first
+func (r *ScoringProfileRepository) GetVersions(brandID int) ([]models.ScoringProfile, error) {
+	rows, err := r.db.Query("SELECT "+scoringProfileColumns+" FROM scoring_profiles WHERE brand_id = ? ORDER BY version DESC", brandID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []models.ScoringProfile
+	for rows.Next() {
+		p, err := scanScoringProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *p)
+	}
+	return profiles, nil
+}
+
+// Create stores the profile as the brand's next version
+func (r *ScoringProfileRepository) Create(p *models.ScoringProfile) (*models.ScoringProfile, error) {
+	result, err := r.db.Exec(
+		`INSERT INTO scoring_profiles (brand_id, version, weight_mention_rate, weight_position, weight_recommend, weight_sentiment,
+			position_first, position_second, position_later)
+		SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ?, ?, ?, ?
+		FROM scoring_profiles WHERE brand_id = ?`,
+		p.BrandID, p.WeightMentionRate, p.WeightPosition, p.WeightRecommend, p.WeightSentiment,
+		p.PositionFirst, p.PositionSecond, p.PositionLater,
+		p.BrandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanScoringProfile(r.db.QueryRow("SELECT "+scoringProfileColumns+" FROM scoring_profiles WHERE id = ?", id))
+}
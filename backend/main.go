@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/Sneh16Shah/ai-visibility-tracker/config"
 	"github.com/Sneh16Shah/ai-visibility-tracker/db"
@@ -33,11 +39,13 @@ func main() {
 	}
 
 	// Initialize database connection
+	dbConnected := false
 	if err := db.Connect(cfg); err != nil {
 		log.Printf("⚠️ Database connection failed: %v", err)
 		log.Println("📝 Running in demo mode without database")
 	} else {
 		defer db.Close()
+		dbConnected = true
 
 		// Seed default user if none exists
 		userRepo := db.NewUserRepository()
@@ -51,9 +59,27 @@ func main() {
 	// Initialize AI analysis service with rate limiting
 	services.InitAnalysisService(cfg)
 
+	// Initialize optional LLM-as-judge mention classifier
+	services.InitMentionClassifier(cfg)
+
 	// Initialize Compare Models service (OpenRouter multi-model comparison)
 	services.InitCompareService(cfg)
 
+	// Initialize email alerts (disabled unless SMTP is configured)
+	services.InitEmailService()
+
+	// Start the background scheduler for scheduled analyses and alert checks
+	var scheduler *services.Scheduler
+	switch {
+	case !cfg.SchedulerEnabled:
+		log.Println("⏰ Scheduler disabled (SCHEDULER_ENABLED=false)")
+	case !dbConnected:
+		log.Println("⏰ Scheduler not started: no database connection")
+	default:
+		scheduler = services.InitScheduler(cfg)
+		scheduler.Start()
+	}
+
 	// Initialize router
 	router := gin.Default()
 
@@ -61,7 +87,7 @@ func main() {
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Admin-Token")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -82,8 +108,28 @@ func main() {
 	log.Printf("📊 Environment: %s", cfg.Environment)
 	log.Printf("🗄️ Database: %s:%s/%s", cfg.DBHost, cfg.DBPort, cfg.DBName)
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-		os.Exit(1)
+	srv := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Wait for an interrupt, then stop taking requests and let scheduled runs drain
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("🛑 Shutting down...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ HTTP server shutdown: %v", err)
+	}
+	if scheduler != nil {
+		if err := scheduler.Stop(cfg.SchedulerDrainTime); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
 	}
 }
@@ -18,17 +18,42 @@ type Brand struct {
 	UserID                      int          `json:"user_id"`
 	Name                        string       `json:"name"`
 	Industry                    string       `json:"industry"`
-	AlertThreshold              float64      `json:"alert_threshold"`    // Score below which to send alert
-	ScheduleFrequency           string       `json:"schedule_frequency"` // "disabled", "daily", "weekly"
+	AlertThreshold              float64      `json:"alert_threshold"`             // Score below which to send alert
+	ForecastAlertWeeks          int          `json:"forecast_alert_weeks"`        // Also alert when the forecast falls below the threshold within N weeks (0 = off)
+	ScheduleFrequency           string       `json:"schedule_frequency"`          // "disabled", "daily", "weekly" or "cron"
+	ScheduleCron                string       `json:"schedule_cron,omitempty"`     // Five-field cron expression (frequency "cron")
+	ScheduleTimezone            string       `json:"schedule_timezone,omitempty"` // IANA zone the cron expression runs in
+	NextRunAt                   *time.Time   `json:"next_run_at"`                 // Next scheduled analysis; nil when disabled
+	SentimentSource             string       `json:"sentiment_source"`            // "rules" or "llm" - which labels drive the score
 	LastScheduledRun            time.Time    `json:"last_scheduled_run"`
+	LastAlertState              string       `json:"last_alert_state,omitempty"`   // Condition the owner was last emailed about (AlertState* constants)
+	LastAlertSentAt             *time.Time   `json:"last_alert_sent_at,omitempty"` // When that email went out
 	CompetitorInsights          string       `json:"competitor_insights,omitempty"`
 	CompetitorInsightsUpdatedAt *time.Time   `json:"competitor_insights_updated_at,omitempty"`
 	Aliases                     []BrandAlias `json:"aliases,omitempty"`
 	Competitors                 []Competitor `json:"competitors,omitempty"`
+	Products                    []Product    `json:"products,omitempty"`
 	CreatedAt                   time.Time    `json:"created_at"`
 	UpdatedAt                   time.Time    `json:"updated_at"`
 }
 
+// Schedule frequencies
+const (
+	ScheduleDisabled = "disabled"
+	ScheduleDaily    = "daily"  // 24 hours after the last scheduled run
+	ScheduleWeekly   = "weekly" // 7 days after the last scheduled run
+	ScheduleCron     = "cron"   // On the brand's cron expression
+)
+
+// AlertSettings is the request body for a brand's alert and schedule settings
+type AlertSettings struct {
+	AlertThreshold     float64 `json:"alert_threshold"`
+	ForecastAlertWeeks int     `json:"forecast_alert_weeks"` // 0 turns forecast alerts off
+	ScheduleFrequency  string  `json:"schedule_frequency"`   // Defaults to "cron" when a cron expression is given
+	ScheduleCron       string  `json:"schedule_cron"`        // e.g. "0 6 * * MON", optionally followed by the time zone
+	ScheduleTimezone   string  `json:"schedule_timezone"`    // IANA name, e.g. "Europe/Berlin"; defaults to UTC
+}
+
 // BrandAlias represents an alternative name for a brand
 type BrandAlias struct {
 	ID        int       `json:"id"`
@@ -45,6 +70,23 @@ type Competitor struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Product represents an individual product tracked under a brand
+type Product struct {
+	ID        int            `json:"id"`
+	BrandID   int            `json:"brand_id"`
+	Name      string         `json:"name"`
+	Aliases   []ProductAlias `json:"aliases,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ProductAlias represents an alternative name for a product
+type ProductAlias struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	Alias     string    `json:"alias"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // Prompt represents a prompt template
 type Prompt struct {
 	ID          int       `json:"id"`
@@ -77,8 +119,50 @@ type Mention struct {
 	ContextSnippet   string    `json:"context_snippet"`
 	Position         int       `json:"position"`
 	IsRecommendation bool      `json:"is_recommendation"` // True if explicitly recommended
-	PositionRank     int       `json:"position_rank"`     // 1=first, 2=second, 3+=later
+	PositionRank     int       `json:"position_rank"`     // Rank in the response's recommended list (1=first)
+	ListLength       int       `json:"list_length"`       // Number of items in that list
 	CreatedAt        time.Time `json:"created_at"`
+
+	// Product the mention is attributed to (brand mentions only, empty when unattributed)
+	ProductID   int    `json:"product_id,omitempty"`
+	ProductName string `json:"product_name,omitempty"`
+
+	// LLM-as-judge classification (empty when the classifier did not run)
+	AISentiment        string  `json:"ai_sentiment,omitempty"`
+	AIConfidence       float64 `json:"ai_confidence,omitempty"`
+	AIIsRecommendation bool    `json:"ai_is_recommendation,omitempty"`
+	AIRationale        string  `json:"ai_rationale,omitempty"`
+	ClassifierModel    string  `json:"classifier_model,omitempty"`
+}
+
+// MentionAspect represents sentiment about one product aspect (pricing, support, ...) in a mention
+type MentionAspect struct {
+	ID         int       `json:"id"`
+	BrandID    int       `json:"brand_id"`
+	MentionID  int       `json:"mention_id"`
+	EntityName string    `json:"entity_name"`
+	EntityType string    `json:"entity_type"` // "brand" or "competitor"
+	Aspect     string    `json:"aspect"`      // "pricing", "ease_of_use", "support", "integrations", "performance", "security"
+	Sentiment  string    `json:"sentiment"`   // "positive", "neutral", "negative"
+	Snippet    string    `json:"snippet"`
+	ModelName  string    `json:"model_name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ResponseCitation represents a URL, domain or named source cited in an AI response
+type ResponseCitation struct {
+	ID           int       `json:"id"`
+	BrandID      int       `json:"brand_id"`
+	AIResponseID int       `json:"ai_response_id"`
+	MentionID    int       `json:"mention_id,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	Domain       string    `json:"domain,omitempty"`
+	SourceName   string    `json:"source_name,omitempty"` // e.g. "G2", "Gartner"
+	Position     int       `json:"position"`
+	EntityName   string    `json:"entity_name,omitempty"` // Nearest brand/competitor mention
+	EntityType   string    `json:"entity_type,omitempty"`
+	ModelName    string    `json:"model_name"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // MetricSnapshot represents aggregated metrics at a point in time
@@ -94,11 +178,19 @@ type MetricSnapshot struct {
 	SnapshotDate    time.Time `json:"snapshot_date"`
 	CreatedAt       time.Time `json:"created_at"`
 
-	// Composite score components (0.0 - 1.0)
-	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`  // 40% weight
-	WeightedPositionScore  float64 `json:"weighted_position_score"`  // 25% weight
-	RecommendationRate     float64 `json:"recommendation_rate"`      // 20% weight
-	RelativeSentimentIndex float64 `json:"relative_sentiment_index"` // 15% weight
+	// Composite score components (0.0 - 1.0), weighted by the brand's scoring profile
+	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`  // 40% by default
+	WeightedPositionScore  float64 `json:"weighted_position_score"`  // 25% by default
+	RecommendationRate     float64 `json:"recommendation_rate"`      // 20% by default
+	RelativeSentimentIndex float64 `json:"relative_sentiment_index"` // 15% by default
+
+	// Scoring profile version the score was computed with (0 = built-in weights)
+	ScoringProfileVersion int `json:"scoring_profile_version"`
+
+	// Responses where the brand ranked first/second, kept so the position score can be
+	// recomputed under new position weights (nil for older snapshots)
+	RankFirstCount  *int `json:"rank_first_count,omitempty"`
+	RankSecondCount *int `json:"rank_second_count,omitempty"`
 
 	// Confidence tracking
 	ConfidenceScore float64 `json:"confidence_score"`
@@ -109,6 +201,254 @@ type MetricSnapshot struct {
 	CategoryAvgSentiment float64 `json:"category_avg_sentiment"`
 }
 
+// ProductSnapshot represents a product's visibility metrics at a point in time
+type ProductSnapshot struct {
+	ID              int       `json:"id"`
+	BrandID         int       `json:"brand_id"`
+	ProductID       int       `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	VisibilityScore float64   `json:"visibility_score"`
+	MentionCount    int       `json:"mention_count"`
+	PositiveCount   int       `json:"positive_count"`
+	NeutralCount    int       `json:"neutral_count"`
+	NegativeCount   int       `json:"negative_count"`
+	SnapshotDate    time.Time `json:"snapshot_date"`
+
+	// Composite score components (0.0 - 1.0), same weights as the brand score
+	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`
+	WeightedPositionScore  float64 `json:"weighted_position_score"`
+	RecommendationRate     float64 `json:"recommendation_rate"`
+	RelativeSentimentIndex float64 `json:"relative_sentiment_index"`
+
+	ResponseCount         int `json:"response_count"`
+	ScoringProfileVersion int `json:"scoring_profile_version"`
+}
+
+// CompetitorSnapshot is a competitor's composite score for one run, computed with the
+// same formula and scoring profile as the brand
+type CompetitorSnapshot struct {
+	ID               int       `json:"id"`
+	BrandID          int       `json:"brand_id"`
+	CompetitorID     int       `json:"competitor_id"`
+	CompetitorName   string    `json:"competitor_name"`
+	MetricSnapshotID int       `json:"metric_snapshot_id,omitempty"` // Brand snapshot of the same run
+	VisibilityScore  float64   `json:"visibility_score"`
+	MentionCount     int       `json:"mention_count"`
+	PositiveCount    int       `json:"positive_count"`
+	NeutralCount     int       `json:"neutral_count"`
+	NegativeCount    int       `json:"negative_count"`
+	SnapshotDate     time.Time `json:"snapshot_date"`
+
+	// Composite score components (0.0 - 1.0)
+	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`
+	WeightedPositionScore  float64 `json:"weighted_position_score"`
+	RecommendationRate     float64 `json:"recommendation_rate"`
+	RelativeSentimentIndex float64 `json:"relative_sentiment_index"`
+
+	ResponseCount         int `json:"response_count"`
+	ScoringProfileVersion int `json:"scoring_profile_version"`
+}
+
+// ModelSnapshot is the brand's composite score within one AI model's responses of a run
+type ModelSnapshot struct {
+	ID                 int       `json:"id"`
+	BrandID            int       `json:"brand_id"`
+	MetricSnapshotID   int       `json:"metric_snapshot_id,omitempty"` // Brand snapshot of the same run
+	ModelName          string    `json:"model_name"`                   // As stored in ai_responses.model_name
+	VisibilityScore    float64   `json:"visibility_score"`
+	MentionCount       int       `json:"mention_count"`
+	ResponsesWithBrand int       `json:"responses_with_brand"`
+	SnapshotDate       time.Time `json:"snapshot_date"`
+
+	// Composite score components (0.0 - 1.0)
+	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`
+	WeightedPositionScore  float64 `json:"weighted_position_score"`
+	RecommendationRate     float64 `json:"recommendation_rate"`
+	RelativeSentimentIndex float64 `json:"relative_sentiment_index"`
+
+	ResponseCount         int `json:"response_count"`
+	ScoringProfileVersion int `json:"scoring_profile_version"`
+}
+
+// CategorySnapshot is the brand's composite score within one prompt category's responses of a run
+type CategorySnapshot struct {
+	ID                 int       `json:"id"`
+	BrandID            int       `json:"brand_id"`
+	MetricSnapshotID   int       `json:"metric_snapshot_id,omitempty"` // Brand snapshot of the same run
+	Category           string    `json:"category"`
+	VisibilityScore    float64   `json:"visibility_score"`
+	MentionCount       int       `json:"mention_count"`
+	ResponsesWithBrand int       `json:"responses_with_brand"`
+	SnapshotDate       time.Time `json:"snapshot_date"`
+
+	// Composite score components (0.0 - 1.0)
+	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`
+	WeightedPositionScore  float64 `json:"weighted_position_score"`
+	RecommendationRate     float64 `json:"recommendation_rate"`
+	RelativeSentimentIndex float64 `json:"relative_sentiment_index"`
+
+	ResponseCount         int `json:"response_count"`
+	ScoringProfileVersion int `json:"scoring_profile_version"`
+}
+
+// PromptSnapshot holds the brand's raw counts within one prompt's responses of a run
+type PromptSnapshot struct {
+	ID                   int       `json:"id"`
+	BrandID              int       `json:"brand_id"`
+	MetricSnapshotID     int       `json:"metric_snapshot_id,omitempty"` // Brand snapshot of the same run
+	PromptID             int       `json:"prompt_id"`
+	Category             string    `json:"category"` // Prompt category at the time of the run
+	ResponseCount        int       `json:"response_count"`
+	ResponsesWithBrand   int       `json:"responses_with_brand"`
+	MentionCount         int       `json:"mention_count"`
+	RecommendedResponses int       `json:"recommended_responses"`
+	RankedResponses      int       `json:"ranked_responses"`
+	RankTotal            int       `json:"rank_total"`
+	SentimentSum         float64   `json:"sentiment_sum"` // 1-5 scale, summed over mentions
+	SnapshotDate         time.Time `json:"snapshot_date"`
+}
+
+// DrilldownPoint is the brand's standing within a prompt or prompt category in one run
+type DrilldownPoint struct {
+	MetricSnapshotID   int       `json:"metric_snapshot_id"`
+	SnapshotDate       time.Time `json:"snapshot_date"`
+	Responses          int       `json:"responses"`
+	ResponsesWithBrand int       `json:"responses_with_brand"`
+	Mentions           int       `json:"mentions"`
+	MentionRate        float64   `json:"mention_rate"`        // 0.0 - 1.0
+	AvgRank            float64   `json:"avg_rank"`            // Best list rank when ranked; 0 when never ranked
+	RecommendationRate float64   `json:"recommendation_rate"` // 0.0 - 1.0
+	AvgSentiment       float64   `json:"avg_sentiment"`       // 1-5 scale; 3 (neutral) when unmentioned
+}
+
+// PromptDrilldown is one prompt's latest drill-down metrics and their trend
+type PromptDrilldown struct {
+	PromptID int              `json:"prompt_id"`
+	Prompt   string           `json:"prompt"`
+	Category string           `json:"category"`
+	Latest   DrilldownPoint   `json:"latest"`
+	Trend    []DrilldownPoint `json:"trend"` // Oldest first
+}
+
+// CategoryDrilldown is one prompt category's latest drill-down metrics and their trend
+type CategoryDrilldown struct {
+	Category string           `json:"category"`
+	Prompts  int              `json:"prompts"` // Prompts of the category in the latest run
+	Latest   DrilldownPoint   `json:"latest"`
+	Trend    []DrilldownPoint `json:"trend"` // Oldest first
+}
+
+// Anomaly kinds and scopes
+const (
+	AnomalyScoreDrop          = "score_drop"
+	AnomalyScoreSpike         = "score_spike"
+	AnomalyCompetitorOvertake = "competitor_overtake"
+	AnomalyModelSilent        = "model_silent"
+
+	AnomalyScopeBrand      = "brand"
+	AnomalyScopeCompetitor = "competitor"
+	AnomalyScopeModel      = "model"
+)
+
+// Anomaly is an unusual move in a brand, competitor or model visibility series
+type Anomaly struct {
+	ID               int        `json:"id"`
+	BrandID          int        `json:"brand_id"`
+	MetricSnapshotID int        `json:"metric_snapshot_id,omitempty"` // Run the anomaly was detected in
+	Kind             string     `json:"kind"`                         // "score_drop", "score_spike", "competitor_overtake", "model_silent"
+	Scope            string     `json:"scope"`                        // "brand", "competitor" or "model"
+	Subject          string     `json:"subject,omitempty"`            // Competitor or model name
+	Value            float64    `json:"value"`                        // Observed score (mention rate for model_silent)
+	Baseline         float64    `json:"baseline"`                     // What it was compared with
+	ZScore           float64    `json:"z_score,omitempty"`
+	Message          string     `json:"message"`
+	DetectedAt       time.Time  `json:"detected_at"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// CompetitorTrend is a competitor's latest snapshot plus its trend
+type CompetitorTrend struct {
+	CompetitorID   int                  `json:"competitor_id"`
+	CompetitorName string               `json:"competitor_name"`
+	Latest         *CompetitorSnapshot  `json:"latest"`
+	Trends         []CompetitorSnapshot `json:"trends"`
+}
+
+// ModelTrend is one AI model's latest snapshot and its trend over a period
+type ModelTrend struct {
+	ModelName   string          `json:"model_name"` // ai_responses.model_name
+	DisplayName string          `json:"display_name"`
+	Color       string          `json:"color"`
+	Latest      *ModelSnapshot  `json:"latest"`
+	Trends      []ModelSnapshot `json:"trends"`
+}
+
+// RankingEntry is one entity's place in a run's visibility ranking
+type RankingEntry struct {
+	Rank                   int     `json:"rank"`
+	EntityName             string  `json:"entity_name"`
+	EntityType             string  `json:"entity_type"` // "brand" or "competitor"
+	CompetitorID           int     `json:"competitor_id,omitempty"`
+	VisibilityScore        float64 `json:"visibility_score"`
+	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`
+	WeightedPositionScore  float64 `json:"weighted_position_score"`
+	RecommendationRate     float64 `json:"recommendation_rate"`
+	RelativeSentimentIndex float64 `json:"relative_sentiment_index"`
+	MentionCount           int     `json:"mention_count"`
+}
+
+// VisibilityRanking ranks the brand and its competitors by composite score for the latest run
+type VisibilityRanking struct {
+	BrandID          int            `json:"brand_id"`
+	MetricSnapshotID int            `json:"metric_snapshot_id"`
+	SnapshotDate     time.Time      `json:"snapshot_date"`
+	BrandRank        int            `json:"brand_rank"`
+	Entries          []RankingEntry `json:"entries"`
+}
+
+// ScoringProfile holds the composite score weights for a brand.
+// Profiles are versioned: saving one adds a new version and the highest version is active.
+type ScoringProfile struct {
+	ID      int `json:"id"`
+	BrandID int `json:"brand_id"`
+	Version int `json:"version"` // 0 = built-in default
+
+	// Component weights, summing to 1
+	WeightMentionRate float64 `json:"weight_mention_rate"`
+	WeightPosition    float64 `json:"weight_position"`
+	WeightRecommend   float64 `json:"weight_recommend"`
+	WeightSentiment   float64 `json:"weight_sentiment"`
+
+	// Weight of the brand's best list rank in a response (later also covers unranked)
+	PositionFirst  float64 `json:"position_first"`
+	PositionSecond float64 `json:"position_second"`
+	PositionLater  float64 `json:"position_later"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RescoreReport summarises recomputing a brand's snapshot history under a scoring profile
+type RescoreReport struct {
+	BrandID             int     `json:"brand_id"`
+	ProfileVersion      int     `json:"profile_version"`
+	SnapshotsRescored   int     `json:"snapshots_rescored"`
+	PositionRecomputed  int     `json:"position_recomputed"` // Snapshots with rank counts
+	ProductSnapshots    int     `json:"product_snapshots_rescored"`
+	CompetitorSnapshots int     `json:"competitor_snapshots_rescored"`
+	ModelSnapshots      int     `json:"model_snapshots_rescored"`
+	CategorySnapshots   int     `json:"category_snapshots_rescored"`
+	LatestScoreBefore   float64 `json:"latest_score_before"`
+	LatestScoreAfter    float64 `json:"latest_score_after"`
+}
+
+// ProductMetrics is a product's latest snapshot plus its trend
+type ProductMetrics struct {
+	ProductID   int               `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Latest      *ProductSnapshot  `json:"latest"`
+	Trends      []ProductSnapshot `json:"trends"`
+}
+
 // ============================================
 // Request/Response DTOs
 // ============================================
@@ -132,6 +472,12 @@ type AddAliasRequest struct {
 	Alias string `json:"alias" binding:"required"`
 }
 
+// AddProductRequest is the request body for adding a product to a brand
+type AddProductRequest struct {
+	Name    string   `json:"name" binding:"required"`
+	Aliases []string `json:"aliases"`
+}
+
 // AddCompetitorRequest is the request body for adding a competitor
 type AddCompetitorRequest struct {
 	Name string `json:"name" binding:"required"`
@@ -142,6 +488,38 @@ type UpdateInsightsRequest struct {
 	Insights string `json:"insights" binding:"required"`
 }
 
+// UpdateSentimentSourceRequest is the request body for choosing which labels drive the score
+type UpdateSentimentSourceRequest struct {
+	SentimentSource string `json:"sentiment_source" binding:"required"`
+}
+
+// BrandFactRequest is the request body for adding or updating a verified brand fact
+type BrandFactRequest struct {
+	FactType string `json:"fact_type" binding:"required"`
+	Label    string `json:"label"`
+	Value    string `json:"value" binding:"required"`
+}
+
+// BackfillRequest is the request body for re-running mention detection over stored responses
+type BackfillRequest struct {
+	From   string `json:"from"` // YYYY-MM-DD, empty for no lower bound
+	To     string `json:"to"`   // YYYY-MM-DD inclusive, empty for no upper bound
+	DryRun bool   `json:"dry_run"`
+}
+
+// ScoringProfileRequest is the request body for saving a new scoring profile version.
+// Position weights are optional and default to the active profile's.
+type ScoringProfileRequest struct {
+	WeightMentionRate float64  `json:"weight_mention_rate"`
+	WeightPosition    float64  `json:"weight_position"`
+	WeightRecommend   float64  `json:"weight_recommend"`
+	WeightSentiment   float64  `json:"weight_sentiment"`
+	PositionFirst     *float64 `json:"position_first"`
+	PositionSecond    *float64 `json:"position_second"`
+	PositionLater     *float64 `json:"position_later"`
+	RecomputeHistory  bool     `json:"recompute_history"`
+}
+
 // RunAnalysisRequest is the request body for running analysis
 type RunAnalysisRequest struct {
 	BrandID   int   `json:"brand_id" binding:"required"`
@@ -154,11 +532,16 @@ type DashboardData struct {
 	CitationShare     float64             `json:"citation_share"`
 	TotalMentions     int                 `json:"total_mentions"`
 	SentimentScore    float64             `json:"sentiment_score"`
-	Trends            []MetricSnapshot    `json:"trends"`
+	Trends            []MetricPoint       `json:"trends"`
+	Annotations       []Annotation        `json:"annotations"` // Marketing events within the trend range
 	CitationBreakdown []CitationBreakdown `json:"citation_breakdown"`
 	CompetitorData    []CompetitorMetrics `json:"competitor_data"`
 	ModelVisibility   []ModelVisibility   `json:"model_visibility"`
 
+	// Share of voice split by AI model and by prompt category
+	ShareOfVoiceByModel    []ShareOfVoiceGroup `json:"share_of_voice_by_model"`
+	ShareOfVoiceByCategory []ShareOfVoiceGroup `json:"share_of_voice_by_category"`
+
 	// Composite score components (0-1)
 	NormalizedMentionRate  float64 `json:"normalized_mention_rate"`
 	WeightedPositionScore  float64 `json:"weighted_position_score"`
@@ -169,16 +552,59 @@ type DashboardData struct {
 	ConfidenceScore float64 `json:"confidence_score"`
 	ConfidenceLevel string  `json:"confidence_level"`
 
+	// Change since the previous snapshot and whether it is significant (nil with a single snapshot)
+	ScoreChange *ScoreChange `json:"score_change,omitempty"`
+
 	// Metadata
 	ResponseCount        int     `json:"response_count"`
 	CategoryAvgSentiment float64 `json:"category_avg_sentiment"`
 }
 
+// Metrics query granularities and aggregations
+const (
+	GranularityRun   = "run"
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+
+	AggregationLast = "last"
+	AggregationAvg  = "avg"
+	AggregationMin  = "min"
+	AggregationMax  = "max"
+)
+
+// MetricsQuery selects a brand's metric series: a time range, how snapshots are bucketed
+// and combined, and an optional model or prompt category filter
+type MetricsQuery struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Granularity string    `json:"granularity"`        // "run", "day", "week" or "month"
+	Aggregation string    `json:"aggregation"`        // "last", "avg", "min" or "max"
+	Model       string    `json:"model,omitempty"`    // ai_responses.model_name
+	Category    string    `json:"category,omitempty"` // Prompt category
+}
+
+// MetricPoint is one bucket of a metric series. With a model or category filter only the
+// score, components, mention and response counts are set.
+type MetricPoint struct {
+	MetricSnapshot
+	PeriodStart time.Time `json:"period_start"`
+	Runs        int       `json:"runs"` // Snapshots combined into the point
+}
+
 // CitationBreakdown represents citation share by entity
 type CitationBreakdown struct {
-	Name  string  `json:"name"`
-	Value float64 `json:"value"`
-	Color string  `json:"color"`
+	Name     string  `json:"name"`
+	Value    float64 `json:"value"` // Share of voice (% of tracked mentions)
+	Color    string  `json:"color"`
+	Mentions int     `json:"mentions"`
+}
+
+// ShareOfVoiceGroup is the share of voice within one AI model or prompt category
+type ShareOfVoiceGroup struct {
+	Group         string              `json:"group"`
+	TotalMentions int                 `json:"total_mentions"`
+	Breakdown     []CitationBreakdown `json:"breakdown"`
 }
 
 // CompetitorMetrics represents metrics for competitor comparison
@@ -190,11 +616,393 @@ type CompetitorMetrics struct {
 	Negative int    `json:"negative"`
 }
 
+// AspectSentimentPoint aggregates aspect sentiment for one entity on one day
+type AspectSentimentPoint struct {
+	EntityName string  `json:"entity_name"`
+	EntityType string  `json:"entity_type"`
+	Aspect     string  `json:"aspect"`
+	Date       string  `json:"date"` // YYYY-MM-DD
+	Positive   int     `json:"positive"`
+	Neutral    int     `json:"neutral"`
+	Negative   int     `json:"negative"`
+	NetScore   float64 `json:"net_score"` // (positive - negative) / total, -1 to 1
+}
+
+// BrandFact is a verified fact about a brand (pricing tier, feature, HQ, ...)
+type BrandFact struct {
+	ID        int       `json:"id"`
+	BrandID   int       `json:"brand_id"`
+	FactType  string    `json:"fact_type"` // pricing, feature, integration, headquarters, founded
+	Label     string    `json:"label"`     // Tier name for pricing facts (e.g. "Pro"), optional otherwise
+	Value     string    `json:"value"`     // e.g. "$49/month", "Single sign-on", "Boston, MA", "2012"
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MisinformationFinding is a claim in an AI response that contradicts a brand fact
+type MisinformationFinding struct {
+	ID              int       `json:"id"`
+	BrandID         int       `json:"brand_id"`
+	AIResponseID    int       `json:"ai_response_id"`
+	FactID          int       `json:"fact_id,omitempty"`
+	FactType        string    `json:"fact_type"`
+	Claim           string    `json:"claim"`    // What the assistant said
+	Expected        string    `json:"expected"` // What the fact sheet says
+	Severity        string    `json:"severity"` // low, medium, high
+	Snippet         string    `json:"snippet"`
+	ModelName       string    `json:"model_name"`
+	DetectionMethod string    `json:"detection_method"` // rules, llm
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// SuggestedCompetitor is an untracked product/company that AI responses list next to the brand
+type SuggestedCompetitor struct {
+	Name               string  `json:"name"`
+	Responses          int     `json:"responses"`            // Responses that list it
+	Frequency          float64 `json:"frequency"`            // Responses / total responses scanned (0-1)
+	CoListedWithBrand  int     `json:"co_listed_with_brand"` // Responses where it shares a list with the brand
+	RecommendationRate float64 `json:"recommendation_rate"`  // Share of its responses that recommend it (0-1)
+	AverageRank        float64 `json:"average_rank"`         // Average list position (1=first)
+	SampleSnippet      string  `json:"sample_snippet"`
+	Score              float64 `json:"score"` // Ranking score combining frequency, co-listing with the brand and recommendation rate
+}
+
+// CitedDomain aggregates how often a domain is cited, optionally near one entity
+type CitedDomain struct {
+	Domain     string `json:"domain"`
+	EntityName string `json:"entity_name,omitempty"`
+	EntityType string `json:"entity_type,omitempty"`
+	Citations  int    `json:"citations"`
+	Responses  int    `json:"responses"`
+}
+
+// EntityCitedDomains lists the top cited domains near one brand or competitor
+type EntityCitedDomains struct {
+	EntityName string        `json:"entity_name"`
+	EntityType string        `json:"entity_type"`
+	Domains    []CitedDomain `json:"domains"`
+}
+
+// CitationReport is the "top cited domains" report for a brand
+type CitationReport struct {
+	BrandID  int                  `json:"brand_id"`
+	Days     int                  `json:"days"`
+	Domains  []CitedDomain        `json:"domains"`
+	ByEntity []EntityCitedDomains `json:"by_entity"`
+}
+
+// BackfillEntityChange is how many mentions an entity had before and after a backfill
+type BackfillEntityChange struct {
+	EntityName string `json:"entity_name"`
+	EntityType string `json:"entity_type"`
+	Before     int    `json:"before"`
+	After      int    `json:"after"`
+}
+
+// BackfillSnapshotChange is a recomputed metric snapshot's visibility score before and after
+type BackfillSnapshotChange struct {
+	SnapshotID   int       `json:"snapshot_id"`
+	SnapshotDate time.Time `json:"snapshot_date"`
+	Before       float64   `json:"before"`
+	After        *float64  `json:"after,omitempty"` // Empty on a dry run
+}
+
+// BackfillReport summarises what a mention backfill changed
+type BackfillReport struct {
+	BrandID                int                      `json:"brand_id"`
+	DryRun                 bool                     `json:"dry_run"`
+	ResponsesScanned       int                      `json:"responses_scanned"`
+	ResponsesChanged       int                      `json:"responses_changed"`
+	MentionsBefore         int                      `json:"mentions_before"`
+	MentionsAfter          int                      `json:"mentions_after"`
+	Added                  int                      `json:"added"`   // Mentions found now but not before
+	Removed                int                      `json:"removed"` // Mentions no longer found
+	SentimentChanged       int                      `json:"sentiment_changed"`
+	RankChanged            int                      `json:"rank_changed"`
+	RecommendationChanged  int                      `json:"recommendation_changed"`
+	Unclassified           int                      `json:"unclassified"` // Added mentions left without an LLM judge verdict
+	EntityChanges          []BackfillEntityChange   `json:"entity_changes"`
+	Snapshots              []BackfillSnapshotChange `json:"snapshots"`
+	SnapshotsNotRecomputed int                      `json:"snapshots_not_recomputed"` // Older snapshots in the window, whose runs' responses are gone
+	Note                   string                   `json:"note,omitempty"`
+}
+
 // ModelVisibility represents visibility score for a specific AI model
 type ModelVisibility struct {
-	Model    string  `json:"model"`
-	ModelID  string  `json:"modelId"`
-	Color    string  `json:"color"`
-	Score    float64 `json:"score"`
-	Mentions int     `json:"mentions"`
+	Model     string  `json:"model"`   // Display name
+	ModelID   string  `json:"modelId"` // ai_responses.model_name
+	Color     string  `json:"color"`
+	Score     float64 `json:"score"`
+	Mentions  int     `json:"mentions"`
+	Responses int     `json:"responses"`
+}
+
+// Score change verdicts
+const (
+	ChangeSignificantRise  = "significant_rise"
+	ChangeSignificantDrop  = "significant_drop"
+	ChangeNotSignificant   = "not_significant"
+	ChangeInsufficientData = "insufficient_data"
+)
+
+// ProportionChange is a two-proportion test on one share of responses (mention or recommendation rate)
+type ProportionChange struct {
+	From         float64 `json:"from"`
+	To           float64 `json:"to"`
+	Delta        float64 `json:"delta"`
+	PValue       float64 `json:"p_value"`
+	IntervalLow  float64 `json:"interval_low"` // 95% interval of the delta
+	IntervalHigh float64 `json:"interval_high"`
+	Significant  bool    `json:"significant"`
+}
+
+// ScoreChange tells whether the score change between two snapshots is statistically meaningful
+type ScoreChange struct {
+	FromSnapshotID int       `json:"from_snapshot_id"`
+	ToSnapshotID   int       `json:"to_snapshot_id"`
+	FromDate       time.Time `json:"from_date"`
+	ToDate         time.Time `json:"to_date"`
+	FromResponses  int       `json:"from_responses"`
+	ToResponses    int       `json:"to_responses"`
+
+	FromScore    float64 `json:"from_score"`
+	ToScore      float64 `json:"to_score"`
+	Delta        float64 `json:"delta"`
+	PValue       float64 `json:"p_value"`
+	IntervalLow  float64 `json:"interval_low"` // 95% interval of the score delta
+	IntervalHigh float64 `json:"interval_high"`
+	Significant  bool    `json:"significant"`
+	Verdict      string  `json:"verdict"` // "significant_rise", "significant_drop", "not_significant" or "insufficient_data"
+
+	MentionRate        ProportionChange `json:"mention_rate"`
+	RecommendationRate ProportionChange `json:"recommendation_rate"`
+}
+
+// Report periods
+const (
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodCustom  = "custom"
+)
+
+// ReportPeriod is one side of a period-over-period report: its range and the runs it pools
+type ReportPeriod struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Runs      int       `json:"runs"`
+	Responses int       `json:"responses"`
+}
+
+// MetricDelta is one metric compared across two periods. PValue is nil for metrics
+// without a significance test (counts, position score, sentiment index, confidence).
+type MetricDelta struct {
+	Metric        string   `json:"metric"`
+	Previous      float64  `json:"previous"`
+	Current       float64  `json:"current"`
+	Delta         float64  `json:"delta"`
+	RelativeDelta *float64 `json:"relative_delta"` // Delta / previous; nil when previous is 0
+	PValue        *float64 `json:"p_value,omitempty"`
+	Significant   bool     `json:"significant"`
+}
+
+// CompetitorPeriodDelta compares a competitor's share of voice and score across two periods
+type CompetitorPeriodDelta struct {
+	CompetitorID    int         `json:"competitor_id"`
+	CompetitorName  string      `json:"competitor_name"`
+	ShareOfVoice    MetricDelta `json:"share_of_voice"` // Percent of all brand and competitor mentions
+	VisibilityScore MetricDelta `json:"visibility_score"`
+}
+
+// ModelPeriodDelta compares the brand's score within one AI model across two periods
+type ModelPeriodDelta struct {
+	ModelName       string      `json:"model_name"`
+	VisibilityScore MetricDelta `json:"visibility_score"`
+	MentionRate     MetricDelta `json:"mention_rate"`
+}
+
+// PeriodReport compares a brand's metrics between a period and the one before it.
+// Each period pools its runs: rates are response-weighted means, counts are totals.
+type PeriodReport struct {
+	BrandID     int                     `json:"brand_id"`
+	BrandName   string                  `json:"brand_name"`
+	Period      string                  `json:"period"` // "week", "month", "quarter" or "custom"
+	Current     ReportPeriod            `json:"current"`
+	Previous    ReportPeriod            `json:"previous"`
+	Metrics     []MetricDelta           `json:"metrics"`
+	ScoreChange ScoreChange             `json:"score_change"`
+	Competitors []CompetitorPeriodDelta `json:"competitors"`
+	Models      []ModelPeriodDelta      `json:"models"`
+	GeneratedAt time.Time               `json:"generated_at"`
+}
+
+// Portfolio alert states, most urgent first
+const (
+	AlertStateBelowThreshold = "below_threshold" // Latest score under the brand's alert threshold
+	AlertStateAnomaly        = "anomaly"         // Unacknowledged anomalies
+	AlertStateOK             = "ok"
+	AlertStateNoData         = "no_data" // Never analyzed
+)
+
+// AlertStateForecastCrossing is the alert email state of a brand whose forecast falls below
+// its threshold within its forecast horizon
+const AlertStateForecastCrossing = "forecast_crossing"
+
+// PortfolioEntry is one brand's row in a user's portfolio
+type PortfolioEntry struct {
+	BrandID            int        `json:"brand_id"`
+	BrandName          string     `json:"brand_name"`
+	Industry           string     `json:"industry"`
+	VisibilityScore    float64    `json:"visibility_score"`
+	WeekChange         *float64   `json:"week_change"` // Change since the latest run a week ago; nil without one
+	ConfidenceLevel    string     `json:"confidence_level"`
+	TopCompetitor      string     `json:"top_competitor"` // Highest-scoring competitor in the latest run
+	TopCompetitorScore float64    `json:"top_competitor_score"`
+	AlertThreshold     float64    `json:"alert_threshold"`
+	OpenAnomalies      int        `json:"open_anomalies"`
+	AlertState         string     `json:"alert_state"` // See AlertState* constants
+	LastRunAt          *time.Time `json:"last_run_at"`
+}
+
+// BenchmarkDistribution is the anonymized spread of one metric across an industry's brands and competitors
+type BenchmarkDistribution struct {
+	Metric string  `json:"metric"`
+	P10    float64 `json:"p10"`
+	P25    float64 `json:"p25"`
+	P50    float64 `json:"p50"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+}
+
+// IndustryBenchmark describes the latest scores of every tracked brand and competitor of an
+// industry. Metrics stay empty until PeerBrands reaches MinSampleSize.
+type IndustryBenchmark struct {
+	Industry      string                  `json:"industry"`
+	SampleSize    int                     `json:"sample_size"` // Brands and competitors in the distribution
+	PeerBrands    int                     `json:"peer_brands"` // Brands tracked by other users
+	MinSampleSize int                     `json:"min_sample_size"`
+	Sufficient    bool                    `json:"sufficient"`
+	Metrics       []BenchmarkDistribution `json:"metrics"`
+}
+
+// BenchmarkPercentile places a brand's latest value of one metric within its industry
+type BenchmarkPercentile struct {
+	Metric     string  `json:"metric"`
+	Value      float64 `json:"value"`
+	Percentile float64 `json:"percentile"` // Share of the industry below the brand (0-100)
+}
+
+// BrandBenchmark is a brand's standing within its industry benchmark
+type BrandBenchmark struct {
+	BrandID     int                   `json:"brand_id"`
+	BrandName   string                `json:"brand_name"`
+	Benchmark   IndustryBenchmark     `json:"benchmark"`
+	Percentiles []BenchmarkPercentile `json:"percentiles"` // Empty without a run or a sufficient sample
+}
+
+// ForecastPoint is one week of a visibility forecast, or of the weekly history it was fitted to
+type ForecastPoint struct {
+	WeekStart       time.Time `json:"week_start"`
+	VisibilityScore float64   `json:"visibility_score"`
+	Low             *float64  `json:"low,omitempty"`  // 95% prediction interval (forecast only)
+	High            *float64  `json:"high,omitempty"` // 95% prediction interval (forecast only)
+}
+
+// VisibilityForecast projects a brand's weekly visibility score with a linear trend
+type VisibilityForecast struct {
+	BrandID      int             `json:"brand_id"`
+	Method       string          `json:"method"`
+	SlopePerWeek float64         `json:"slope_per_week"`
+	History      []ForecastPoint `json:"history"` // Weekly average scores the trend was fitted to
+	Forecast     []ForecastPoint `json:"forecast"`
+
+	// Alert threshold of the brand and the first forecast week below it (nil when none is)
+	AlertThreshold        float64    `json:"alert_threshold"`
+	CrossesThresholdAt    *time.Time `json:"crosses_threshold_at"`
+	CrossesThresholdWeeks int        `json:"crosses_threshold_weeks,omitempty"`
+}
+
+// Annotation types
+const (
+	AnnotationContent  = "content" // Published content, e.g. a comparison page
+	AnnotationLaunch   = "launch"  // Product or feature launch
+	AnnotationPress    = "press"
+	AnnotationCampaign = "campaign"
+	AnnotationOther    = "other"
+)
+
+// Annotation marks a marketing event on a brand's visibility timeline
+type Annotation struct {
+	ID        int       `json:"id"`
+	BrandID   int       `json:"brand_id"`
+	Date      time.Time `json:"date"` // Day of the event, midnight server time
+	Title     string    `json:"title"`
+	Type      string    `json:"type"` // See Annotation* constants
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AnnotationRequest is the request body for creating or updating an annotation
+type AnnotationRequest struct {
+	Date  string `json:"date" binding:"required"` // YYYY-MM-DD
+	Title string `json:"title" binding:"required"`
+	Type  string `json:"type"` // Defaults to "other"
+	URL   string `json:"url"`
+}
+
+// AnnotationImpact compares a brand's metrics in the windows before and after an annotation
+type AnnotationImpact struct {
+	Annotation  Annotation    `json:"annotation"`
+	WindowDays  int           `json:"window_days"`
+	Before      ReportPeriod  `json:"before"`
+	After       ReportPeriod  `json:"after"`
+	Complete    bool          `json:"complete"`    // False while the after window is still running
+	Overlapping []int         `json:"overlapping"` // Other annotations within either window, whose effects are mixed in
+	ScoreChange ScoreChange   `json:"score_change"`
+	Metrics     []MetricDelta `json:"metrics"`
+}
+
+// Scheduled run statuses
+const (
+	RunQueued    = "queued" // Waiting for its jitter delay or a free slot
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunPartial   = "partial" // Some prompts failed
+	RunFailed    = "failed"
+	RunSkipped   = "skipped" // Another analysis of the brand was in progress, or the scheduler stopped first
+)
+
+// ScheduledRun is one analysis started by the background scheduler
+type ScheduledRun struct {
+	ID           int        `json:"id"`
+	BrandID      int        `json:"brand_id"`
+	BrandName    string     `json:"brand_name"`
+	ScheduledFor *time.Time `json:"scheduled_for"` // Due time the run was started for
+	Status       string     `json:"status"`        // See Run* constants
+	ResponsesRun int        `json:"responses_run"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+}
+
+// UpcomingRun is a brand's next scheduled analysis
+type UpcomingRun struct {
+	BrandID           int       `json:"brand_id"`
+	BrandName         string    `json:"brand_name"`
+	ScheduleFrequency string    `json:"schedule_frequency"`
+	ScheduleCron      string    `json:"schedule_cron,omitempty"`
+	ScheduleTimezone  string    `json:"schedule_timezone,omitempty"`
+	NextRunAt         time.Time `json:"next_run_at"`
+}
+
+// ScheduleOverview is the admin view of the background scheduler
+type ScheduleOverview struct {
+	SchedulerRunning bool           `json:"scheduler_running"`
+	Concurrency      int            `json:"concurrency"`
+	MaxJitterSeconds int            `json:"max_jitter_seconds"`
+	InFlight         []int          `json:"in_flight"` // Brand IDs queued or running
+	Upcoming         []UpcomingRun  `json:"upcoming"`
+	Recent           []ScheduledRun `json:"recent"`
 }
@@ -35,6 +35,21 @@ func Setup(router *gin.Engine) {
 
 			// Competitor routes (nested under brands)
 			brands.GET("/:id/competitors", controllers.GetCompetitors)
+			brands.GET("/:id/competitors/suggestions", controllers.GetSuggestedCompetitors)
+			brands.GET("/:id/competitors/metrics", controllers.GetCompetitorMetrics)
+			brands.GET("/:id/models/metrics", controllers.GetModelMetrics)
+			brands.GET("/:id/ranking", controllers.GetVisibilityRanking)
+			brands.GET("/:id/score-change", controllers.GetScoreChange)
+			brands.GET("/:id/period-report", controllers.GetPeriodReport)
+			brands.GET("/:id/benchmark", controllers.GetBrandBenchmark)
+			brands.GET("/:id/forecast", controllers.GetForecast)
+			brands.GET("/:id/anomalies", controllers.GetAnomalies)
+			brands.POST("/:id/anomalies/:anomalyId/acknowledge", controllers.AcknowledgeAnomaly)
+			brands.GET("/:id/annotations", controllers.GetAnnotations)
+			brands.POST("/:id/annotations", controllers.CreateAnnotation)
+			brands.GET("/:id/annotations/impact", controllers.GetAnnotationImpact)
+			brands.PUT("/:id/annotations/:annotationId", controllers.UpdateAnnotation)
+			brands.DELETE("/:id/annotations/:annotationId", controllers.DeleteAnnotation)
 			brands.POST("/:id/competitors", controllers.AddCompetitor)
 			brands.DELETE("/:id/competitors/:competitorId", controllers.RemoveCompetitor)
 
@@ -42,11 +57,45 @@ func Setup(router *gin.Engine) {
 			brands.GET("/:id/aliases", controllers.GetAliases)
 			brands.POST("/:id/aliases", controllers.AddAlias)
 			brands.DELETE("/:id/aliases/:aliasId", controllers.RemoveAlias)
+
+			// Product routes (nested under brands)
+			brands.GET("/:id/products", controllers.GetProducts)
+			brands.GET("/:id/products/metrics", controllers.GetProductMetrics)
+			brands.POST("/:id/products", controllers.AddProduct)
+			brands.DELETE("/:id/products/:productId", controllers.RemoveProduct)
+			brands.POST("/:id/products/:productId/aliases", controllers.AddProductAlias)
+			brands.DELETE("/:id/products/:productId/aliases/:aliasId", controllers.RemoveProductAlias)
+
 			brands.PUT("/:id/alerts", controllers.UpdateAlertSettings)
+			brands.PUT("/:id/sentiment-source", controllers.UpdateSentimentSource)
+
+			// Composite score weights (versioned per brand)
+			brands.GET("/:id/scoring-profile", controllers.GetScoringProfile)
+			brands.PUT("/:id/scoring-profile", controllers.UpdateScoringProfile)
+			brands.POST("/:id/scoring-profile/recompute", controllers.RescoreHistory)
 
 			// Insights routes (competitor deep dive)
 			brands.GET("/:id/insights", controllers.GetInsights)
 			brands.PUT("/:id/insights", controllers.SaveInsights)
+
+			// Per-category and per-prompt drill-down, down to the prompt's responses
+			brands.GET("/:id/drilldown/categories", controllers.GetCategoryDrilldown)
+			brands.GET("/:id/drilldown/prompts", controllers.GetPromptDrilldown)
+			brands.GET("/:id/drilldown/prompts/:promptId/responses", controllers.GetPromptResponses)
+
+			// Aspect sentiment (pricing, support, ...) per entity over time
+			brands.GET("/:id/aspects", controllers.GetAspectSentiment)
+			brands.GET("/:id/citations/domains", controllers.GetCitedDomains)
+
+			// Re-run mention detection over stored responses
+			brands.POST("/:id/backfill", controllers.BackfillMentions)
+
+			// Fact sheet and misinformation findings
+			brands.GET("/:id/facts", controllers.GetBrandFacts)
+			brands.POST("/:id/facts", controllers.AddBrandFact)
+			brands.PUT("/:id/facts/:factId", controllers.UpdateBrandFact)
+			brands.DELETE("/:id/facts/:factId", controllers.DeleteBrandFact)
+			brands.GET("/:id/misinformation", controllers.GetMisinformationFindings)
 		}
 
 		// Prompt routes
@@ -81,6 +130,27 @@ func Setup(router *gin.Engine) {
 			metrics.GET("/dashboard", controllers.GetDashboardData)
 		}
 
+		// Portfolio routes (all brands of the user)
+		portfolio := api.Group("/portfolio")
+		portfolio.Use(controllers.OptionalAuthMiddleware())
+		{
+			portfolio.GET("", controllers.GetPortfolio)
+		}
+
+		// Industry benchmark routes (anonymized across all users)
+		benchmarks := api.Group("/benchmarks")
+		benchmarks.Use(controllers.OptionalAuthMiddleware())
+		{
+			benchmarks.GET("", controllers.GetIndustryBenchmark)
+		}
+
+		// Admin routes (X-Admin-Token header, disabled without ADMIN_TOKEN)
+		admin := api.Group("/admin")
+		admin.Use(controllers.AdminMiddleware())
+		{
+			admin.GET("/scheduled-runs", controllers.GetScheduledRuns)
+		}
+
 		// Export routes
 		export := api.Group("/export")
 		{
@@ -0,0 +1,33 @@
+package scoring
+
+import (
+	"math"
+	"sort"
+)
+
+// MinBenchmarkSample is the fewest brands of other users an industry needs before its
+// distribution is published. Below it, quantiles and percentiles would come close to
+// revealing individual scores.
+const MinBenchmarkSample = 5
+
+// Quantile returns the q-th quantile (0-1) of values, interpolating linearly between the
+// closest ranks. values must be sorted ascending and not empty.
+func Quantile(values []float64, q float64) float64 {
+	pos := q * float64(len(values)-1)
+	lower := int(math.Floor(pos))
+	if lower >= len(values)-1 {
+		return values[len(values)-1]
+	}
+	return values[lower] + (pos-float64(lower))*(values[lower+1]-values[lower])
+}
+
+// PercentileRank returns the share (0-100) of values below v, counting values equal to v
+// as half below, so the middle of a distribution sits at 50. values must be sorted ascending.
+func PercentileRank(values []float64, v float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	below := sort.SearchFloat64s(values, v)
+	equal := sort.SearchFloat64s(values, math.Nextafter(v, math.Inf(1))) - below
+	return (float64(below) + float64(equal)/2) / float64(len(values)) * 100
+}
@@ -0,0 +1,33 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50}
+	cases := []struct{ q, want float64 }{
+		{0, 10}, {0.25, 20}, {0.5, 30}, {0.9, 46}, {1, 50},
+	}
+	for _, c := range cases {
+		if got := Quantile(values, c.q); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("Quantile(%.2f) = %.2f, want %.2f", c.q, got, c.want)
+		}
+	}
+	if got := Quantile([]float64{42}, 0.75); got != 42 {
+		t.Errorf("Quantile of one value = %.2f, want 42", got)
+	}
+}
+
+func TestPercentileRank(t *testing.T) {
+	values := []float64{10, 20, 30, 30, 50}
+	cases := []struct{ v, want float64 }{
+		{5, 0}, {10, 10}, {30, 60}, {40, 80}, {60, 100},
+	}
+	for _, c := range cases {
+		if got := PercentileRank(values, c.v); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("PercentileRank(%.0f) = %.2f, want %.2f", c.v, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package scoring
+
+import (
+	"errors"
+	"math"
+)
+
+// MinForecastPoints is the fewest observations a trend is fitted to: two fix the line,
+// the third gives the residual error the prediction interval needs
+const MinForecastPoints = 3
+
+// ErrInsufficientHistory is returned when a series is too short to fit a trend to
+var ErrInsufficientHistory = errors.New("not enough history to forecast")
+
+// tQuantiles975 are the two-sided 95% quantiles of Student's t for 1-30 degrees of freedom
+var tQuantiles975 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// Trend is an ordinary least-squares line fitted to a series
+type Trend struct {
+	Intercept float64
+	Slope     float64
+	StdError  float64 // Residual standard error
+	Points    int
+
+	meanX float64
+	sxx   float64 // Sum of squared deviations of x
+}
+
+// FitTrend fits a line to the points (xs[i], ys[i]). At least MinForecastPoints points are
+// needed, and they must not all share the same x.
+func FitTrend(xs, ys []float64) (Trend, error) {
+	n := len(xs)
+	if n < MinForecastPoints || n != len(ys) {
+		return Trend{}, ErrInsufficientHistory
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sxx == 0 {
+		return Trend{}, ErrInsufficientHistory
+	}
+
+	t := Trend{Slope: sxy / sxx, Points: n, meanX: meanX, sxx: sxx}
+	t.Intercept = meanY - t.Slope*meanX
+
+	var sse float64
+	for i := range xs {
+		residual := ys[i] - t.Predict(xs[i])
+		sse += residual * residual
+	}
+	t.StdError = math.Sqrt(sse / float64(n-2))
+	return t, nil
+}
+
+// Predict returns the trend's value at x
+func (t Trend) Predict(x float64) float64 {
+	return t.Intercept + t.Slope*x
+}
+
+// PredictionInterval returns the 95% interval a new observation at x should fall in.
+// It widens with distance from the fitted points.
+func (t Trend) PredictionInterval(x float64) (low, high float64) {
+	df := t.Points - 2
+	q := z95
+	if df <= len(tQuantiles975) {
+		q = tQuantiles975[df-1]
+	}
+	margin := q * t.StdError * math.Sqrt(1+1/float64(t.Points)+(x-t.meanX)*(x-t.meanX)/t.sxx)
+	value := t.Predict(x)
+	return value - margin, value + margin
+}
@@ -0,0 +1,44 @@
+package scoring
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFitTrend(t *testing.T) {
+	// Exact line: no residual error, so the interval collapses onto the prediction
+	trend, err := FitTrend([]float64{0, 1, 2, 3}, []float64{50, 48, 46, 44})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(trend.Slope+2) > 1e-9 || math.Abs(trend.Intercept-50) > 1e-9 {
+		t.Errorf("trend = %+.2f x + %.2f, want -2x + 50", trend.Slope, trend.Intercept)
+	}
+	if low, high := trend.PredictionInterval(5); math.Abs(low-40) > 1e-9 || math.Abs(high-40) > 1e-9 {
+		t.Errorf("interval at 5 = [%.2f, %.2f], want [40, 40]", low, high)
+	}
+
+	// Noisy series: residuals 2/3, -4/3, 2/3 give a residual error of sqrt(8/3)
+	noisy, err := FitTrend([]float64{0, 1, 2}, []float64{11, 10, 13})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(noisy.Slope-1) > 1e-9 || math.Abs(noisy.StdError-math.Sqrt(8.0/3)) > 1e-9 {
+		t.Errorf("noisy trend: slope %.3f, std error %.3f", noisy.Slope, noisy.StdError)
+	}
+	near, _ := noisy.PredictionInterval(3)
+	far, _ := noisy.PredictionInterval(6)
+	if !(far-noisy.Predict(6) < near-noisy.Predict(3)) {
+		t.Errorf("interval should widen further out: %.2f vs %.2f", far-noisy.Predict(6), near-noisy.Predict(3))
+	}
+}
+
+func TestFitTrendInsufficientHistory(t *testing.T) {
+	if _, err := FitTrend([]float64{0, 1}, []float64{40, 42}); !errors.Is(err, ErrInsufficientHistory) {
+		t.Errorf("two points: err = %v, want ErrInsufficientHistory", err)
+	}
+	if _, err := FitTrend([]float64{1, 1, 1}, []float64{40, 42, 44}); !errors.Is(err, ErrInsufficientHistory) {
+		t.Errorf("single x: err = %v, want ErrInsufficientHistory", err)
+	}
+}
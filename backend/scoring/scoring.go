@@ -0,0 +1,274 @@
+// Package scoring computes the composite visibility score used across the app.
+//
+// Every score is built from the same four components (each 0-1):
+//   - mention rate: share of responses that mention the entity
+//   - position: weight of the entity's best list rank per response, averaged over all responses
+//   - recommendation rate: share of responses that explicitly recommend the entity
+//   - relative sentiment: the entity's average sentiment against the other entities'
+//
+// The components are weighted by a scoring profile and scaled to 0-100. A run score
+// aggregates many responses; a response score is the same formula over a run of one.
+package scoring
+
+import (
+	"math"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// Default component weights (a brand can override them with a scoring profile)
+const (
+	WeightMentionRate = 0.40 // 40%
+	WeightPosition    = 0.25 // 25%
+	WeightRecommend   = 0.20 // 20%
+	WeightSentiment   = 0.15 // 15%
+)
+
+// Default position weights for the entity's best rank in a response
+const (
+	PositionFirst  = 1.0
+	PositionSecond = 0.7
+	PositionLater  = 0.4 // Ranked third or later, or mentioned outside a list
+)
+
+// Which labels drive sentiment and recommendation
+const (
+	SentimentSourceRules = "rules"
+	SentimentSourceLLM   = "llm"
+)
+
+// neutralSentiment is the 1-5 scale midpoint used when there is nothing to average
+const neutralSentiment = 3.0
+
+// DefaultProfile returns the built-in weights (version 0) used until a brand saves a profile
+func DefaultProfile(brandID int) *models.ScoringProfile {
+	return &models.ScoringProfile{
+		BrandID:           brandID,
+		Version:           0,
+		WeightMentionRate: WeightMentionRate,
+		WeightPosition:    WeightPosition,
+		WeightRecommend:   WeightRecommend,
+		WeightSentiment:   WeightSentiment,
+		PositionFirst:     PositionFirst,
+		PositionSecond:    PositionSecond,
+		PositionLater:     PositionLater,
+	}
+}
+
+// Components are the four inputs of the composite score, each 0-1
+type Components struct {
+	MentionRate        float64 `json:"mention_rate"`
+	PositionScore      float64 `json:"position_score"`
+	RecommendationRate float64 `json:"recommendation_rate"`
+	RelativeSentiment  float64 `json:"relative_sentiment"`
+}
+
+// Score combines the components into the 0-100 visibility score
+func Score(profile *models.ScoringProfile, c Components) float64 {
+	return (profile.WeightMentionRate*c.MentionRate +
+		profile.WeightPosition*c.PositionScore +
+		profile.WeightRecommend*c.RecommendationRate +
+		profile.WeightSentiment*c.RelativeSentiment) * 100
+}
+
+// PositionWeight converts a list rank into the profile's position weight (0 = unranked)
+func PositionWeight(profile *models.ScoringProfile, rank int) float64 {
+	switch rank {
+	case 1:
+		return profile.PositionFirst
+	case 2:
+		return profile.PositionSecond
+	default:
+		return profile.PositionLater
+	}
+}
+
+// SentimentValue maps a sentiment label onto the 1-5 scale (1=negative, 3=neutral, 5=positive)
+func SentimentValue(sentiment string) float64 {
+	switch sentiment {
+	case "positive":
+		return 5.0
+	case "negative":
+		return 1.0
+	default:
+		return neutralSentiment
+	}
+}
+
+// RelativeSentiment maps the difference between two 1-5 averages (-4..+4) onto 0-1
+func RelativeSentiment(entityAvg, categoryAvg float64) float64 {
+	index := (entityAvg - categoryAvg + 4.0) / 8.0
+	return math.Max(0, math.Min(1, index))
+}
+
+// EffectiveLabels returns the sentiment and recommendation flag that should drive
+// scoring for a mention, falling back to the rule-based labels when the
+// classifier did not run for it.
+func EffectiveLabels(mention models.Mention, source string) (string, bool) {
+	if source == SentimentSourceLLM && mention.AISentiment != "" {
+		return mention.AISentiment, mention.AIIsRecommendation
+	}
+	return mention.Sentiment, mention.IsRecommendation
+}
+
+// Stat accumulates one entity's mention data across a run's responses
+type Stat struct {
+	Mentions             int
+	Positive             int
+	Neutral              int
+	Negative             int
+	SentimentSum         float64 // On the 1-5 scale
+	Responses            int     // Responses mentioning the entity
+	RecommendedResponses int
+	PositionTotal        float64
+}
+
+// AddMention counts one mention with the sentiment that drives the score
+func (s *Stat) AddMention(sentiment string) {
+	s.Mentions++
+	s.SentimentSum += SentimentValue(sentiment)
+	switch sentiment {
+	case "positive":
+		s.Positive++
+	case "negative":
+		s.Negative++
+	default:
+		s.Neutral++
+	}
+}
+
+// AddResponse counts one response the entity appears in, with its best list rank (0 = unranked)
+func (s *Stat) AddResponse(profile *models.ScoringProfile, bestRank int, recommended bool) {
+	s.Responses++
+	s.PositionTotal += PositionWeight(profile, bestRank)
+	if recommended {
+		s.RecommendedResponses++
+	}
+}
+
+// AvgSentiment is the entity's average sentiment on the 1-5 scale (neutral when unmentioned)
+func (s *Stat) AvgSentiment() float64 {
+	if s.Mentions == 0 {
+		return neutralSentiment
+	}
+	return s.SentimentSum / float64(s.Mentions)
+}
+
+// Components returns the entity's components over a run of totalResponses.
+// An entity that is never mentioned gets no sentiment credit.
+func (s *Stat) Components(totalResponses int, categoryAvgSentiment float64) Components {
+	if totalResponses == 0 {
+		return Components{}
+	}
+	c := Components{
+		MentionRate:        float64(s.Responses) / float64(totalResponses),
+		PositionScore:      s.PositionTotal / float64(totalResponses),
+		RecommendationRate: float64(s.RecommendedResponses) / float64(totalResponses),
+	}
+	if s.Mentions > 0 {
+		c.RelativeSentiment = RelativeSentiment(s.AvgSentiment(), categoryAvgSentiment)
+	}
+	return c
+}
+
+// RankTally collects, within one response, each entity's best rank and whether it was recommended
+type RankTally struct {
+	BestRank    map[int]int
+	Recommended map[int]bool
+}
+
+// NewRankTally creates an empty tally for one response
+func NewRankTally() *RankTally {
+	return &RankTally{BestRank: map[int]int{}, Recommended: map[int]bool{}}
+}
+
+// Add records one mention of the entity with the given key
+func (t *RankTally) Add(key int, mention models.Mention, isRecommendation bool) {
+	rank, seen := t.BestRank[key]
+	if !seen || (mention.PositionRank > 0 && (rank == 0 || mention.PositionRank < rank)) {
+		t.BestRank[key] = mention.PositionRank
+	}
+	if isRecommendation {
+		t.Recommended[key] = true
+	}
+}
+
+// Run accumulates the brand's score over a set of responses. Brand mentions (aliases and
+// products included) score the brand; competitor mentions form the category sentiment.
+type Run struct {
+	profile *models.ScoringProfile
+	source  string
+
+	Brand                Stat
+	Responses            int
+	RankFirst            int // Responses where the brand ranked first
+	RankSecond           int // Responses where the brand ranked second
+	RankedResponses      int // Responses where the brand appeared in a ranked list
+	RankTotal            int // Sum of the brand's best rank over those responses
+	CategoryMentions     int
+	CategorySentimentSum float64
+}
+
+// NewRun starts a run scored with a profile and sentiment source
+func NewRun(profile *models.ScoringProfile, sentimentSource string) *Run {
+	return &Run{profile: profile, source: sentimentSource}
+}
+
+// AddResponse counts one response's mentions
+func (r *Run) AddResponse(mentions []models.Mention) {
+	r.Responses++
+	tally := NewRankTally()
+	const brandKey = 0
+
+	for _, mention := range mentions {
+		sentiment, isRecommendation := EffectiveLabels(mention, r.source)
+		if mention.EntityType != "brand" {
+			r.CategoryMentions++
+			r.CategorySentimentSum += SentimentValue(sentiment)
+			continue
+		}
+		r.Brand.AddMention(sentiment)
+		tally.Add(brandKey, mention, isRecommendation)
+	}
+
+	bestRank, mentioned := tally.BestRank[brandKey]
+	if !mentioned {
+		return
+	}
+	r.Brand.AddResponse(r.profile, bestRank, tally.Recommended[brandKey])
+	if bestRank > 0 {
+		r.RankedResponses++
+		r.RankTotal += bestRank
+	}
+	switch bestRank {
+	case 1:
+		r.RankFirst++
+	case 2:
+		r.RankSecond++
+	}
+}
+
+// CategoryAvgSentiment is the competitors' average sentiment (neutral when none were mentioned)
+func (r *Run) CategoryAvgSentiment() float64 {
+	if r.CategoryMentions == 0 {
+		return neutralSentiment
+	}
+	return r.CategorySentimentSum / float64(r.CategoryMentions)
+}
+
+// Components returns the brand's components over the run
+func (r *Run) Components() Components {
+	return r.Brand.Components(r.Responses, r.CategoryAvgSentiment())
+}
+
+// Score returns the brand's 0-100 visibility score over the run
+func (r *Run) Score() float64 {
+	return Score(r.profile, r.Components())
+}
+
+// ResponseScore scores the brand in a single response with the run formula
+func ResponseScore(profile *models.ScoringProfile, sentimentSource string, mentions []models.Mention) float64 {
+	run := NewRun(profile, sentimentSource)
+	run.AddResponse(mentions)
+	return run.Score()
+}
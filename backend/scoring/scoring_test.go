@@ -0,0 +1,136 @@
+package scoring
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// Regenerate the golden file after an intended formula change with:
+//
+//	go test ./scoring -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite testdata/golden.json from the current formula")
+
+// goldenTolerance absorbs float formatting in the stored results
+const goldenTolerance = 1e-9
+
+// goldenCase is one input run in testdata/cases.json
+type goldenCase struct {
+	Name            string                 `json:"name"`
+	SentimentSource string                 `json:"sentiment_source"`
+	Profile         *models.ScoringProfile `json:"profile,omitempty"` // Defaults when omitted
+	Responses       [][]models.Mention     `json:"responses"`
+}
+
+// goldenResult is the pinned output of one case
+type goldenResult struct {
+	Components     Components `json:"components"`
+	RunScore       float64    `json:"run_score"`
+	ResponseScores []float64  `json:"response_scores"`
+	RankFirst      int        `json:"rank_first"`
+	RankSecond     int        `json:"rank_second"`
+}
+
+func scoreCase(c goldenCase) goldenResult {
+	profile := c.Profile
+	if profile == nil {
+		profile = DefaultProfile(0)
+	}
+
+	run := NewRun(profile, c.SentimentSource)
+	result := goldenResult{ResponseScores: []float64{}}
+	for _, mentions := range c.Responses {
+		run.AddResponse(mentions)
+		result.ResponseScores = append(result.ResponseScores, ResponseScore(profile, c.SentimentSource, mentions))
+	}
+	result.Components = run.Components()
+	result.RunScore = run.Score()
+	result.RankFirst = run.RankFirst
+	result.RankSecond = run.RankSecond
+	return result
+}
+
+// TestGolden pins the run and per-response scores of every case in testdata/cases.json
+func TestGolden(t *testing.T) {
+	data, err := os.ReadFile("testdata/cases.json")
+	if err != nil {
+		t.Fatalf("load cases: %v", err)
+	}
+	var cases []goldenCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		t.Fatalf("parse cases: %v", err)
+	}
+
+	got := map[string]goldenResult{}
+	for _, c := range cases {
+		got[c.Name] = scoreCase(c)
+	}
+
+	if *update {
+		out, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile("testdata/golden.json", append(out, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err = os.ReadFile("testdata/golden.json")
+	if err != nil {
+		t.Fatalf("load golden results: %v", err)
+	}
+	var want map[string]goldenResult
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("parse golden results: %v", err)
+	}
+
+	for _, c := range cases {
+		w, ok := want[c.Name]
+		if !ok {
+			t.Errorf("%s: no golden result (run with -update)", c.Name)
+			continue
+		}
+		g := got[c.Name]
+		check := func(field string, want, got float64) {
+			if math.Abs(want-got) > goldenTolerance {
+				t.Errorf("%s: %s = %v, want %v", c.Name, field, got, want)
+			}
+		}
+		check("mention_rate", w.Components.MentionRate, g.Components.MentionRate)
+		check("position_score", w.Components.PositionScore, g.Components.PositionScore)
+		check("recommendation_rate", w.Components.RecommendationRate, g.Components.RecommendationRate)
+		check("relative_sentiment", w.Components.RelativeSentiment, g.Components.RelativeSentiment)
+		check("run_score", w.RunScore, g.RunScore)
+		if g.RankFirst != w.RankFirst || g.RankSecond != w.RankSecond {
+			t.Errorf("%s: rank counts = %d/%d, want %d/%d", c.Name, g.RankFirst, g.RankSecond, w.RankFirst, w.RankSecond)
+		}
+		if len(g.ResponseScores) != len(w.ResponseScores) {
+			t.Errorf("%s: %d response scores, want %d", c.Name, len(g.ResponseScores), len(w.ResponseScores))
+			continue
+		}
+		for i := range w.ResponseScores {
+			check("response_scores", w.ResponseScores[i], g.ResponseScores[i])
+		}
+	}
+}
+
+// TestResponseScoreIsRunOfOne keeps the per-response score tied to the run formula
+func TestResponseScoreIsRunOfOne(t *testing.T) {
+	profile := DefaultProfile(0)
+	mentions := []models.Mention{
+		{EntityType: "brand", EntityName: "Acme", Sentiment: "positive", PositionRank: 2, IsRecommendation: true},
+		{EntityType: "competitor", EntityName: "Globex", Sentiment: "negative", PositionRank: 1},
+	}
+
+	run := NewRun(profile, SentimentSourceRules)
+	run.AddResponse(mentions)
+	if got, want := ResponseScore(profile, SentimentSourceRules, mentions), run.Score(); got != want {
+		t.Errorf("ResponseScore = %v, want run score %v", got, want)
+	}
+}
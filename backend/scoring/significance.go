@@ -0,0 +1,111 @@
+package scoring
+
+import (
+	"math"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// SignificanceLevel is the p-value below which a change is reported as significant
+const SignificanceLevel = 0.05
+
+// z95 is the standard normal quantile of a two-sided 95% interval
+const z95 = 1.959964
+
+// Sample is a stored run: its score, the components behind it and how many responses it covered
+type Sample struct {
+	Score      float64
+	Components Components
+	Responses  int
+}
+
+// CompareProportions runs a two-proportion z-test on two rates observed over n1 and n2
+// responses. The interval uses the Agresti-Caffo adjustment (one success and one failure
+// added to each side) so it stays meaningful at 0% and 100%.
+func CompareProportions(p1 float64, n1 int, p2 float64, n2 int) models.ProportionChange {
+	change := models.ProportionChange{From: p1, To: p2, Delta: p2 - p1, PValue: 1}
+	if n1 == 0 || n2 == 0 {
+		return change
+	}
+
+	// Rates are stored as fractions of the run's responses: recover the counts
+	x1 := math.Round(p1 * float64(n1))
+	x2 := math.Round(p2 * float64(n2))
+	m1, m2 := float64(n1), float64(n2)
+
+	pooled := (x1 + x2) / (m1 + m2)
+	if se := math.Sqrt(pooled * (1 - pooled) * (1/m1 + 1/m2)); se > 0 {
+		change.PValue = twoSidedPValue((x2/m2 - x1/m1) / se)
+	}
+
+	a1 := (x1 + 1) / (m1 + 2)
+	a2 := (x2 + 1) / (m2 + 2)
+	seAdjusted := math.Sqrt(a1*(1-a1)/(m1+2) + a2*(1-a2)/(m2+2))
+	change.IntervalLow = a2 - a1 - z95*seAdjusted
+	change.IntervalHigh = a2 - a1 + z95*seAdjusted
+	change.Significant = change.PValue < SignificanceLevel
+	return change
+}
+
+// CompareRuns tells whether the score moved significantly between two runs. Per-response
+// data is not kept for stored runs, so the test is a normal approximation on the score's
+// standard error bound (see scoreStdError) rather than a bootstrap.
+func CompareRuns(profile *models.ScoringProfile, from, to Sample) models.ScoreChange {
+	change := models.ScoreChange{
+		FromResponses: from.Responses,
+		ToResponses:   to.Responses,
+		FromScore:     from.Score,
+		ToScore:       to.Score,
+		Delta:         to.Score - from.Score,
+		PValue:        1,
+		Verdict:       models.ChangeInsufficientData,
+
+		MentionRate:        CompareProportions(from.Components.MentionRate, from.Responses, to.Components.MentionRate, to.Responses),
+		RecommendationRate: CompareProportions(from.Components.RecommendationRate, from.Responses, to.Components.RecommendationRate, to.Responses),
+	}
+	if from.Responses == 0 || to.Responses == 0 {
+		return change
+	}
+
+	se := math.Hypot(scoreStdError(profile, from.Components, from.Responses), scoreStdError(profile, to.Components, to.Responses))
+	change.PValue = twoSidedPValue(change.Delta / se)
+	change.IntervalLow = change.Delta - z95*se
+	change.IntervalHigh = change.Delta + z95*se
+	change.Significant = change.PValue < SignificanceLevel
+
+	switch {
+	case !change.Significant:
+		change.Verdict = models.ChangeNotSignificant
+	case change.Delta > 0:
+		change.Verdict = models.ChangeSignificantRise
+	default:
+		change.Verdict = models.ChangeSignificantDrop
+	}
+	return change
+}
+
+// scoreStdError bounds the standard error of a run's score. Each component averages
+// per-response values in [0, 1], so its variance is at most c(1-c); adding up the weighted
+// standard deviations covers any correlation between components. Components are shrunk
+// towards 0.5 like the proportion interval so a run at 0% or 100% still has some spread.
+func scoreStdError(profile *models.ScoringProfile, c Components, responses int) float64 {
+	n := float64(responses)
+	parts := []struct{ weight, value float64 }{
+		{profile.WeightMentionRate, c.MentionRate},
+		{profile.WeightPosition, c.PositionScore},
+		{profile.WeightRecommend, c.RecommendationRate},
+		{profile.WeightSentiment, c.RelativeSentiment},
+	}
+
+	sd := 0.0
+	for _, part := range parts {
+		adjusted := (part.value*n + 1) / (n + 2)
+		sd += part.weight * math.Sqrt(adjusted*(1-adjusted))
+	}
+	return 100 * sd / math.Sqrt(n)
+}
+
+// twoSidedPValue is the probability of a standard normal value at least as extreme as z
+func twoSidedPValue(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
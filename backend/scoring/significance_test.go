@@ -0,0 +1,56 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestCompareProportions(t *testing.T) {
+	// 50/100 -> 30/100: pooled 0.4, z = -2.887
+	change := CompareProportions(0.5, 100, 0.3, 100)
+	if math.Abs(change.PValue-0.00389) > 0.0001 {
+		t.Errorf("p-value = %.5f, want 0.00389", change.PValue)
+	}
+	if !change.Significant || change.IntervalHigh >= 0 {
+		t.Errorf("drop from 50%% to 30%% over 100 responses should be significant: %+v", change)
+	}
+
+	// Same rates over six responses each
+	if change := CompareProportions(0.5, 6, 0.3, 6); change.Significant {
+		t.Errorf("drop over six responses should not be significant: %+v", change)
+	}
+
+	// Identical extremes: nothing to test
+	if change := CompareProportions(1, 10, 1, 10); change.PValue != 1 || change.Significant {
+		t.Errorf("unchanged 100%% rate: %+v", change)
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	profile := DefaultProfile(0)
+	from := Components{MentionRate: 0.67, PositionScore: 0.55, RecommendationRate: 0.5, RelativeSentiment: 0.6}
+	to := Components{MentionRate: 0.5, PositionScore: 0.5, RecommendationRate: 0.5, RelativeSentiment: 0.55}
+
+	small := CompareRuns(profile,
+		Sample{Score: Score(profile, from), Components: from, Responses: 6},
+		Sample{Score: Score(profile, to), Components: to, Responses: 6})
+	if small.Verdict != models.ChangeNotSignificant {
+		t.Errorf("score %.1f -> %.1f over six responses: verdict %s (p=%.3f)", small.FromScore, small.ToScore, small.Verdict, small.PValue)
+	}
+	if small.IntervalLow > small.Delta || small.IntervalHigh < small.Delta {
+		t.Errorf("interval [%.1f, %.1f] does not contain delta %.1f", small.IntervalLow, small.IntervalHigh, small.Delta)
+	}
+
+	large := CompareRuns(profile,
+		Sample{Score: Score(profile, from), Components: from, Responses: 2000},
+		Sample{Score: Score(profile, to), Components: to, Responses: 2000})
+	if large.Verdict != models.ChangeSignificantDrop {
+		t.Errorf("same drop over 2000 responses: verdict %s (p=%.4f)", large.Verdict, large.PValue)
+	}
+
+	if empty := CompareRuns(profile, Sample{}, Sample{Responses: 6}); empty.Verdict != models.ChangeInsufficientData {
+		t.Errorf("run without responses: verdict %s", empty.Verdict)
+	}
+}
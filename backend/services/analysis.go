@@ -101,6 +101,7 @@ type AnalysisStatus struct {
 	ProviderName      string                 `json:"provider_name"`
 	RateLimitStatus   map[string]interface{} `json:"rate_limit_status"`
 	CanRunAnalysis    bool                   `json:"can_run_analysis"`
+	ClassifierModel   string                 `json:"classifier_model,omitempty"`
 }
 
 // GetStatus returns the current status of the analysis service
@@ -119,6 +120,7 @@ func (s *AnalysisService) GetStatus() AnalysisStatus {
 		ProviderName:      providerName,
 		RateLimitStatus:   rateLimitStatus,
 		CanRunAnalysis:    canRun,
+		ClassifierModel:   GetMentionClassifier().GetModelName(),
 	}
 }
 
@@ -204,6 +206,13 @@ func (s *AnalysisService) RunAnalysis(ctx context.Context, brandID int, promptID
 
 	responseRepo := db.NewAIResponseRepository()
 
+	// Load the brand's verified facts so responses can be checked for misinformation
+	facts, err := db.NewFactRepository().GetByBrandID(brandID)
+	if err != nil {
+		log.Printf("Warning: failed to load facts for brand %d: %v", brandID, err)
+	}
+	factChecker := NewFactChecker(facts)
+
 	// Delete existing responses for this brand before running new analysis
 	// This ensures we only keep the latest run data
 	if err := responseRepo.DeleteByBrandID(brandID); err != nil {
@@ -243,9 +252,16 @@ func (s *AnalysisService) RunAnalysis(ctx context.Context, brandID int, promptID
 		mentionDetector := NewMentionDetector()
 		detectedMentions := mentionDetector.DetectMentions(responseText, brand)
 
+		// Optionally let the LLM judge label each mention alongside the rules
+		if classifier := GetMentionClassifier(); classifier.IsAvailable() {
+			for _, msg := range classifier.ClassifyMentions(ctx, responseText, detectedMentions) {
+				log.Printf("Warning: %s", msg)
+			}
+		}
+
 		// Store mentions
 		if len(detectedMentions) > 0 {
-			storedMentions, err := mentionDetector.StoreMentions(aiResponse.ID, detectedMentions)
+			storedMentions, err := mentionDetector.StoreMentions(aiResponse, detectedMentions)
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Failed to store mentions: %s", err.Error()))
 			} else {
@@ -253,6 +269,23 @@ func (s *AnalysisService) RunAnalysis(ctx context.Context, brandID int, promptID
 			}
 		}
 
+		// Store the sources the response cites, whether or not it mentions a tracked entity;
+		// citations are linked to the nearest stored mention, if any
+		citationExtractor := NewCitationExtractor()
+		citations := citationExtractor.ExtractCitations(responseText, detectedMentions)
+		if err := citationExtractor.StoreCitations(aiResponse, citations, aiResponse.Mentions); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Failed to store citations: %s", err.Error()))
+		}
+
+		// Flag claims that contradict the brand's fact sheet
+		findings, err := factChecker.CheckResponse(ctx, responseText, detectedMentions)
+		if err != nil {
+			log.Printf("Warning: fact verifier failed: %v", err)
+		}
+		if err := factChecker.StoreFindings(aiResponse, findings); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Failed to store misinformation findings: %s", err.Error()))
+		}
+
 		result.Responses = append(result.Responses, *aiResponse)
 		result.ResponsesRun++
 
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// Impact windows, in days on each side of an annotation
+const (
+	DefaultImpactWindowDays = 14
+	MaxImpactWindowDays     = 90
+)
+
+// ErrInvalidAnnotation wraps every annotation validation failure
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// NewAnnotation validates an annotation request for a brand. The date is a day in the
+// server's time zone; the type defaults to "other" and the URL must be http(s).
+func NewAnnotation(brandID int, req models.AnnotationRequest) (*models.Annotation, error) {
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.Date), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidAnnotation)
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" || len(title) > 255 {
+		return nil, fmt.Errorf("%w: title must be 1-255 characters", ErrInvalidAnnotation)
+	}
+
+	annotationType := strings.ToLower(strings.TrimSpace(req.Type))
+	switch annotationType {
+	case "":
+		annotationType = models.AnnotationOther
+	case models.AnnotationContent, models.AnnotationLaunch, models.AnnotationPress, models.AnnotationCampaign, models.AnnotationOther:
+	default:
+		return nil, fmt.Errorf("%w: type must be content, launch, press, campaign or other", ErrInvalidAnnotation)
+	}
+
+	link := strings.TrimSpace(req.URL)
+	if link != "" {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(link) > 2048 {
+			return nil, fmt.Errorf("%w: url must be an http(s) URL of at most 2048 characters", ErrInvalidAnnotation)
+		}
+	}
+
+	return &models.Annotation{BrandID: brandID, Date: date, Title: title, Type: annotationType, URL: link}, nil
+}
+
+// ImpactWindows returns the windowDays days before an annotation's day and the windowDays
+// days starting on it
+func ImpactWindows(date time.Time, windowDays int) (before, after models.ReportPeriod) {
+	before = models.ReportPeriod{From: date.AddDate(0, 0, -windowDays), To: date.Add(-time.Nanosecond)}
+	after = models.ReportPeriod{From: date, To: date.AddDate(0, 0, windowDays).Add(-time.Nanosecond)}
+	return before, after
+}
+
+// GetAnnotationImpact compares the brand's metrics in the window before and after each of its
+// annotations, newest annotation first. Changes carry the same significance tests as period
+// reports; annotations close together share their windows and are listed as overlapping.
+func (m *MetricsCalculator) GetAnnotationImpact(brandID, windowDays int) ([]models.AnnotationImpact, error) {
+	if windowDays < 1 || windowDays > MaxImpactWindowDays {
+		return nil, fmt.Errorf("%w: window must be between 1 and %d days", ErrInvalidAnnotation, MaxImpactWindowDays)
+	}
+
+	annotations, err := db.NewAnnotationRepository().GetByBrandID(brandID)
+	if err != nil {
+		return nil, err
+	}
+	profile := loadScoringProfile(brandID)
+	now := time.Now()
+
+	impacts := []models.AnnotationImpact{}
+	for i := len(annotations) - 1; i >= 0; i-- {
+		a := annotations[i]
+		before, after := ImpactWindows(a.Date, windowDays)
+
+		prev, err := loadPeriodData(brandID, before)
+		if err != nil {
+			return nil, err
+		}
+		cur, err := loadPeriodData(brandID, after)
+		if err != nil {
+			return nil, err
+		}
+		before.Runs, before.Responses = prev.brand.runs, prev.brand.responses
+		after.Runs, after.Responses = cur.brand.runs, cur.brand.responses
+
+		scoreChange := scoring.CompareRuns(profile, prev.brand.sample(), cur.brand.sample())
+		scoreChange.FromDate, scoreChange.ToDate = before.From, after.From
+
+		overlapping := []int{}
+		for _, other := range annotations {
+			if other.ID != a.ID && !other.Date.Before(before.From) && !other.Date.After(after.To) {
+				overlapping = append(overlapping, other.ID)
+			}
+		}
+
+		impacts = append(impacts, models.AnnotationImpact{
+			Annotation:  a,
+			WindowDays:  windowDays,
+			Before:      before,
+			After:       after,
+			Complete:    now.After(after.To),
+			Overlapping: overlapping,
+			ScoreChange: scoreChange,
+			Metrics:     brandPeriodDeltas(prev, cur, scoreChange),
+		})
+	}
+	return impacts, nil
+}
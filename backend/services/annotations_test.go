@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestNewAnnotation(t *testing.T) {
+	cases := []struct {
+		name     string
+		req      models.AnnotationRequest
+		wantErr  bool
+		wantType string
+	}{
+		{"minimal", models.AnnotationRequest{Date: "2026-03-01", Title: "Launch"}, false, models.AnnotationOther},
+		{"typed and linked", models.AnnotationRequest{Date: "2026-03-01", Title: "Blog post", Type: "Content", URL: "https://acme.com/blog"}, false, models.AnnotationContent},
+		{"surrounding spaces", models.AnnotationRequest{Date: " 2026-03-01 ", Title: "  Press  ", Type: " press "}, false, models.AnnotationPress},
+		{"title of 255 characters", models.AnnotationRequest{Date: "2026-03-01", Title: strings.Repeat("a", 255)}, false, models.AnnotationOther},
+		{"bad date", models.AnnotationRequest{Date: "03/01/2026", Title: "Launch"}, true, ""},
+		{"impossible date", models.AnnotationRequest{Date: "2026-02-30", Title: "Launch"}, true, ""},
+		{"blank title", models.AnnotationRequest{Date: "2026-03-01", Title: "   "}, true, ""},
+		{"title too long", models.AnnotationRequest{Date: "2026-03-01", Title: strings.Repeat("a", 256)}, true, ""},
+		{"unknown type", models.AnnotationRequest{Date: "2026-03-01", Title: "Launch", Type: "webinar"}, true, ""},
+		{"non-http url", models.AnnotationRequest{Date: "2026-03-01", Title: "Launch", URL: "ftp://acme.com/file"}, true, ""},
+		{"url without host", models.AnnotationRequest{Date: "2026-03-01", Title: "Launch", URL: "https://"}, true, ""},
+		{"url too long", models.AnnotationRequest{Date: "2026-03-01", Title: "Launch", URL: "https://acme.com/" + strings.Repeat("a", 2048)}, true, ""},
+	}
+
+	for _, tc := range cases {
+		got, err := NewAnnotation(7, tc.req)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: NewAnnotation() error = %v, want error %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, ErrInvalidAnnotation) {
+				t.Errorf("%s: error %v does not wrap ErrInvalidAnnotation", tc.name, err)
+			}
+			continue
+		}
+		if got.BrandID != 7 || got.Type != tc.wantType || got.Title != strings.TrimSpace(tc.req.Title) {
+			t.Errorf("%s: NewAnnotation() = %+v, want brand 7, type %q", tc.name, got, tc.wantType)
+		}
+		if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local); !got.Date.Equal(want) {
+			t.Errorf("%s: date = %v, want %v", tc.name, got.Date, want)
+		}
+	}
+}
+
+func TestImpactWindows(t *testing.T) {
+	date := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	before, after := ImpactWindows(date, 7)
+
+	if want := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC); !before.From.Equal(want) {
+		t.Errorf("before.From = %v, want %v", before.From, want)
+	}
+	if !before.To.Before(date) || date.Sub(before.To) != time.Nanosecond {
+		t.Errorf("before.To = %v, want the instant before %v", before.To, date)
+	}
+	if !after.From.Equal(date) {
+		t.Errorf("after.From = %v, want %v", after.From, date)
+	}
+	if end := time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC); !after.To.Before(end) || end.Sub(after.To) != time.Nanosecond {
+		t.Errorf("after.To = %v, want the instant before %v", after.To, end)
+	}
+
+	// The annotation's own day belongs to the after window only
+	noon := date.Add(12 * time.Hour)
+	if !noon.After(before.To) || noon.Before(after.From) || noon.After(after.To) {
+		t.Errorf("noon of the annotation day %v is not in the after window only", noon)
+	}
+
+	// Windows span whole calendar days across a month end
+	before, after = ImpactWindows(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), 1)
+	if want := time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC); !before.From.Equal(want) {
+		t.Errorf("before.From = %v, want %v", before.From, want)
+	}
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond); !after.To.Equal(want) {
+		t.Errorf("after.To = %v, want %v", after.To, want)
+	}
+}
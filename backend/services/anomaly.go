@@ -0,0 +1,272 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/ai"
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// Anomaly detection settings
+const (
+	anomalyWindow     = 14  // Previous runs forming a series' baseline
+	anomalyMinHistory = 5   // Runs a series needs before it is checked
+	anomalyZThreshold = 2.5 // |z| at or above which a move is flagged
+	anomalyMinStdDev  = 2.0 // Score points; keeps a flat baseline from flagging tiny moves
+)
+
+// AnomalyDetector flags unusual moves in a brand's visibility series: drops and spikes of
+// the brand, competitor and per-model scores against a rolling baseline, a competitor
+// overtaking the brand, and a model that stops mentioning the brand.
+type AnomalyDetector struct{}
+
+// NewAnomalyDetector creates a new anomaly detector
+func NewAnomalyDetector() *AnomalyDetector {
+	return &AnomalyDetector{}
+}
+
+// Detect checks every series at a freshly stored run and stores the anomalies found.
+// It returns the newly raised anomalies; re-running it for the same run raises nothing new.
+func (d *AnomalyDetector) Detect(brandID int, current *models.MetricSnapshot) ([]models.Anomaly, error) {
+	history, err := db.NewMetricRepository().GetTrendsByBrandID(brandID, anomalyWindow+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Previous runs, newest first; runIndex maps their snapshot IDs to that order
+	var previous []models.MetricSnapshot
+	runIndex := map[int]int{}
+	for _, s := range history {
+		if s.ID == current.ID || len(previous) == anomalyWindow {
+			continue
+		}
+		runIndex[s.ID] = len(previous)
+		previous = append(previous, s)
+	}
+
+	var candidates []models.Anomaly
+
+	baseline := make([]float64, len(previous))
+	for i, s := range previous {
+		baseline[i] = s.VisibilityScore
+	}
+	if a := scoreAnomaly(models.AnomalyScopeBrand, "", baseline, current.VisibilityScore); a != nil {
+		candidates = append(candidates, *a)
+	}
+
+	competitorAnomalies, err := d.competitorAnomalies(brandID, current, previous, runIndex)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, competitorAnomalies...)
+
+	modelAnomalies, err := d.modelAnomalies(brandID, current, previous, runIndex)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, modelAnomalies...)
+
+	repo := db.NewAnomalyRepository()
+	raised := []models.Anomaly{}
+	for _, a := range candidates {
+		a.BrandID = brandID
+		a.MetricSnapshotID = current.ID
+		inserted, err := repo.Create(&a)
+		if err != nil {
+			return nil, err
+		}
+		if inserted {
+			log.Printf("🚨 Anomaly for brand %d: %s", brandID, a.Message)
+			raised = append(raised, a)
+		}
+	}
+	return raised, nil
+}
+
+// competitorAnomalies checks each competitor's score series and whether one overtook the brand
+func (d *AnomalyDetector) competitorAnomalies(brandID int, current *models.MetricSnapshot, previous []models.MetricSnapshot, runIndex map[int]int) ([]models.Anomaly, error) {
+	snapshots, err := db.NewCompetitorSnapshotRepository().GetSince(brandID, seriesStart(current, previous))
+	if err != nil {
+		return nil, err
+	}
+
+	type series struct {
+		name     string
+		baseline []float64
+		byRun    map[int]float64 // Run index -> score
+		current  *float64
+	}
+	byCompetitor := map[int]*series{}
+	var order []int
+	for _, s := range snapshots {
+		c, ok := byCompetitor[s.CompetitorID]
+		if !ok {
+			c = &series{name: s.CompetitorName, byRun: map[int]float64{}}
+			byCompetitor[s.CompetitorID] = c
+			order = append(order, s.CompetitorID)
+		}
+		score := s.VisibilityScore
+		if s.MetricSnapshotID == current.ID {
+			c.current = &score
+		} else if run, ok := runIndex[s.MetricSnapshotID]; ok {
+			c.baseline = append(c.baseline, score)
+			c.byRun[run] = score
+		}
+	}
+
+	var anomalies []models.Anomaly
+	for _, id := range order {
+		c := byCompetitor[id]
+		if c.current == nil {
+			continue // Not scored in this run
+		}
+		if a := scoreAnomaly(models.AnomalyScopeCompetitor, c.name, c.baseline, *c.current); a != nil {
+			anomalies = append(anomalies, *a)
+		}
+
+		// Overtaking: ahead of the brand now, level or behind in the previous run
+		if len(previous) == 0 {
+			continue
+		}
+		prevScore, ok := c.byRun[0]
+		if ok && overtook(*c.current, current.VisibilityScore, prevScore, previous[0].VisibilityScore) {
+			anomalies = append(anomalies, models.Anomaly{
+				Kind:     models.AnomalyCompetitorOvertake,
+				Scope:    models.AnomalyScopeCompetitor,
+				Subject:  c.name,
+				Value:    *c.current,
+				Baseline: current.VisibilityScore,
+				Message: fmt.Sprintf("%s overtook the brand: %.1f vs %.1f (previous run %.1f vs %.1f)",
+					c.name, *c.current, current.VisibilityScore, prevScore, previous[0].VisibilityScore),
+			})
+		}
+	}
+	return anomalies, nil
+}
+
+// modelAnomalies checks each AI model's score series and whether a model stopped mentioning the brand
+func (d *AnomalyDetector) modelAnomalies(brandID int, current *models.MetricSnapshot, previous []models.MetricSnapshot, runIndex map[int]int) ([]models.Anomaly, error) {
+	snapshots, err := db.NewModelSnapshotRepository().GetSince(brandID, seriesStart(current, previous))
+	if err != nil {
+		return nil, err
+	}
+
+	type series struct {
+		baseline []float64
+		last     *models.ModelSnapshot // Previous run
+		current  *models.ModelSnapshot
+	}
+	byModel := map[string]*series{}
+	var order []string
+	for i := range snapshots {
+		s := &snapshots[i]
+		m, ok := byModel[s.ModelName]
+		if !ok {
+			m = &series{}
+			byModel[s.ModelName] = m
+			order = append(order, s.ModelName)
+		}
+		if s.MetricSnapshotID == current.ID {
+			m.current = s
+		} else if run, ok := runIndex[s.MetricSnapshotID]; ok {
+			m.baseline = append(m.baseline, s.VisibilityScore)
+			if run == 0 {
+				m.last = s
+			}
+		}
+	}
+
+	var anomalies []models.Anomaly
+	for _, name := range order {
+		m := byModel[name]
+		if m.current == nil {
+			continue // Model not queried in this run
+		}
+		if a := scoreAnomaly(models.AnomalyScopeModel, name, m.baseline, m.current.VisibilityScore); a != nil {
+			anomalies = append(anomalies, *a)
+		}
+
+		if wentSilent(m.last, m.current) {
+			anomalies = append(anomalies, models.Anomaly{
+				Kind:     models.AnomalyModelSilent,
+				Scope:    models.AnomalyScopeModel,
+				Subject:  name,
+				Value:    0,
+				Baseline: m.last.NormalizedMentionRate,
+				Message: fmt.Sprintf("%s stopped mentioning the brand: 0 of %d responses (previous run %d of %d)",
+					ai.LookupModel(name).Name, m.current.ResponseCount, m.last.ResponsesWithBrand, m.last.ResponseCount),
+			})
+		}
+	}
+	return anomalies, nil
+}
+
+// overtook reports whether a competitor is ahead of the brand now after being level or behind in the previous run
+func overtook(competitorNow, brandNow, competitorPrev, brandPrev float64) bool {
+	return competitorNow > brandNow && competitorPrev <= brandPrev
+}
+
+// wentSilent reports whether a model that mentioned the brand in the previous run answered
+// this run without mentioning it
+func wentSilent(last, current *models.ModelSnapshot) bool {
+	return last != nil && last.ResponsesWithBrand > 0 && current.ResponseCount > 0 && current.ResponsesWithBrand == 0
+}
+
+// seriesStart is the earliest snapshot date the baseline runs can have
+func seriesStart(current *models.MetricSnapshot, previous []models.MetricSnapshot) time.Time {
+	if len(previous) == 0 {
+		return current.SnapshotDate
+	}
+	return previous[len(previous)-1].SnapshotDate
+}
+
+// scoreAnomaly returns a drop or spike anomaly when value is anomalyZThreshold standard
+// deviations away from the baseline mean, or nil when it is not (or the baseline is too short)
+func scoreAnomaly(scope, subject string, baseline []float64, value float64) *models.Anomaly {
+	if len(baseline) < anomalyMinHistory {
+		return nil
+	}
+
+	mean := 0.0
+	for _, v := range baseline {
+		mean += v
+	}
+	mean /= float64(len(baseline))
+
+	variance := 0.0
+	for _, v := range baseline {
+		variance += (v - mean) * (v - mean)
+	}
+	stdDev := math.Max(math.Sqrt(variance/float64(len(baseline)-1)), anomalyMinStdDev)
+
+	z := (value - mean) / stdDev
+	if math.Abs(z) < anomalyZThreshold {
+		return nil
+	}
+
+	kind, verb := models.AnomalyScoreSpike, "jumped"
+	if z < 0 {
+		kind, verb = models.AnomalyScoreDrop, "dropped"
+	}
+	label := "Brand"
+	switch scope {
+	case models.AnomalyScopeCompetitor:
+		label = "Competitor " + subject
+	case models.AnomalyScopeModel:
+		label = "Model " + ai.LookupModel(subject).Name
+	}
+	return &models.Anomaly{
+		Kind:     kind,
+		Scope:    scope,
+		Subject:  subject,
+		Value:    value,
+		Baseline: mean,
+		ZScore:   z,
+		Message: fmt.Sprintf("%s score %s to %.1f (baseline %.1f over %d runs, z=%.1f)",
+			label, verb, value, mean, len(baseline), z),
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestScoreAnomaly(t *testing.T) {
+	cases := []struct {
+		name     string
+		baseline []float64
+		value    float64
+		wantKind string // Empty when no anomaly
+		wantZ    float64
+	}{
+		{"too little history", []float64{50, 50, 50, 50}, 10, "", 0},
+		{"within threshold", []float64{48, 52, 50, 49, 51}, 52, "", 0},
+		{"drop", []float64{48, 52, 50, 49, 51}, 40, models.AnomalyScoreDrop, -5},
+		{"spike", []float64{48, 52, 50, 49, 51}, 60, models.AnomalyScoreSpike, 5},
+		// Flat baseline: the stddev floor (2 points) keeps a 3-point move quiet
+		{"flat baseline small move", []float64{50, 50, 50, 50, 50}, 53, "", 0},
+		{"flat baseline large move", []float64{50, 50, 50, 50, 50}, 45, models.AnomalyScoreDrop, -2.5},
+		// Sample stddev of {40, 60, 40, 60, 40, 60} is ~10.95, so a 25-point move is z ~2.28
+		{"noisy baseline", []float64{40, 60, 40, 60, 40, 60}, 75, "", 0},
+	}
+
+	for _, tc := range cases {
+		a := scoreAnomaly(models.AnomalyScopeBrand, "", tc.baseline, tc.value)
+		if tc.wantKind == "" {
+			if a != nil {
+				t.Errorf("%s: got %s (z=%.2f), want none", tc.name, a.Kind, a.ZScore)
+			}
+			continue
+		}
+		if a == nil {
+			t.Errorf("%s: got none, want %s", tc.name, tc.wantKind)
+			continue
+		}
+		if a.Kind != tc.wantKind || math.Abs(a.ZScore-tc.wantZ) > 0.01 {
+			t.Errorf("%s: got %s z=%.2f, want %s z=%.2f", tc.name, a.Kind, a.ZScore, tc.wantKind, tc.wantZ)
+		}
+	}
+}
+
+func TestOvertook(t *testing.T) {
+	cases := []struct {
+		name                                               string
+		competitorNow, brandNow, competitorPrev, brandPrev float64
+		want                                               bool
+	}{
+		{"passes the brand", 55, 50, 45, 50, true},
+		{"was level", 55, 50, 50, 50, true},
+		{"already ahead", 60, 50, 55, 50, false},
+		{"still behind", 45, 50, 40, 50, false},
+		{"ties the brand", 50, 50, 40, 50, false},
+	}
+	for _, tc := range cases {
+		if got := overtook(tc.competitorNow, tc.brandNow, tc.competitorPrev, tc.brandPrev); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWentSilent(t *testing.T) {
+	snapshot := func(responses, withBrand int) *models.ModelSnapshot {
+		return &models.ModelSnapshot{ResponseCount: responses, ResponsesWithBrand: withBrand}
+	}
+	cases := []struct {
+		name          string
+		last, current *models.ModelSnapshot
+		want          bool
+	}{
+		{"stopped mentioning", snapshot(3, 2), snapshot(3, 0), true},
+		{"still mentions", snapshot(3, 2), snapshot(3, 1), false},
+		{"never mentioned", snapshot(3, 0), snapshot(3, 0), false},
+		{"no responses this run", snapshot(3, 2), snapshot(0, 0), false},
+		{"no previous run", nil, snapshot(3, 0), false},
+	}
+	for _, tc := range cases {
+		if got := wentSilent(tc.last, tc.current); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"strings"
+)
+
+// Aspects tracked for every brand and competitor mention
+const (
+	AspectPricing      = "pricing"
+	AspectEaseOfUse    = "ease_of_use"
+	AspectSupport      = "support"
+	AspectIntegrations = "integrations"
+	AspectPerformance  = "performance"
+	AspectSecurity     = "security"
+)
+
+// DetectedAspect represents sentiment about one aspect near a mention
+type DetectedAspect struct {
+	Aspect    string
+	Sentiment string
+	Snippet   string
+}
+
+// aspectKeywords maps each aspect to the phrases that signal it.
+// Order matters only for readability; every aspect is checked.
+var aspectKeywords = []struct {
+	Aspect   string
+	Keywords []string
+}{
+	{AspectPricing, []string{"price", "pricing", "cost", "expensive", "cheap", "affordable", "free tier", "free plan", "subscription", "per user", "per seat", "budget", "value for money"}},
+	{AspectEaseOfUse, []string{"easy to use", "ease of use", "user-friendly", "intuitive", "learning curve", "interface", "ui", "ux", "setup", "onboarding", "clunky", "complicated"}},
+	{AspectSupport, []string{"support", "customer service", "help desk", "documentation", "docs", "community", "onboarding help", "account manager"}},
+	{AspectIntegrations, []string{"integration", "integrations", "integrates", "api", "plugin", "plugins", "connects with", "ecosystem", "marketplace", "zapier"}},
+	{AspectPerformance, []string{"performance", "fast", "slow", "speed", "scalable", "scalability", "latency", "uptime", "lag", "reliable", "reliability"}},
+	{AspectSecurity, []string{"security", "secure", "compliance", "compliant", "soc 2", "gdpr", "hipaa", "encryption", "privacy", "sso"}},
+}
+
+// Extra polarity words that only make sense when talking about a specific aspect
+var (
+	aspectPositiveWords = []string{
+		"affordable", "cheap", "free", "intuitive", "easy", "user-friendly", "responsive",
+		"helpful", "fast", "seamless", "scalable", "secure", "compliant", "robust", "extensive",
+	}
+	aspectNegativeWords = []string{
+		"pricey", "steep", "costly", "clunky", "difficult", "hard to", "unresponsive",
+		"lag", "downtime", "vulnerab", "breach", "few integrations", "steep learning curve",
+	}
+)
+
+// clauseSeparators split a sentence into clauses so "cheap but slow" yields two verdicts
+var clauseSeparators = []string{",", ";", " but ", " however ", " although ", " while ", " whereas "}
+
+// extractAspects finds aspect sentiment about a mention. Only the mention's own clause is
+// searched when it names an aspect, so in "Acme has great support, but Globex's pricing is
+// awful" Acme does not inherit the pricing verdict; otherwise the whole sentence is.
+func (d *MentionDetector) extractAspects(text string, position, length int) []DetectedAspect {
+	start, end := sentenceBounds(text, position, length)
+	sentence := text[start:end]
+	lowerSentence := strings.ToLower(sentence)
+
+	clauses := splitClauses(lowerSentence)
+	clauseStart, clauseEnd := clauseBounds(lowerSentence, position-start, length)
+	if own := lowerSentence[clauseStart:clauseEnd]; d.hasAspectKeyword(own) {
+		clauses = []string{own}
+	}
+
+	var aspects []DetectedAspect
+	for _, group := range aspectKeywords {
+		for _, clause := range clauses {
+			if !d.containsKeyword(clause, group.Keywords) {
+				continue
+			}
+			aspects = append(aspects, DetectedAspect{
+				Aspect:    group.Aspect,
+				Sentiment: d.analyzeAspectSentiment(clause),
+				Snippet:   strings.TrimSpace(sentence),
+			})
+			break // One verdict per aspect per mention
+		}
+	}
+	return aspects
+}
+
+// hasAspectKeyword checks whether a clause mentions any tracked aspect
+func (d *MentionDetector) hasAspectKeyword(clause string) bool {
+	for _, group := range aspectKeywords {
+		if d.containsKeyword(clause, group.Keywords) {
+			return true
+		}
+	}
+	return false
+}
+
+// clauseBounds returns the byte span of the clause of a lowercase sentence containing
+// sentence[offset:offset+length], using the same separators as splitClauses
+func clauseBounds(sentence string, offset, length int) (int, int) {
+	offset = min(max(offset, 0), len(sentence))
+	mentionEnd := min(offset+length, len(sentence))
+
+	start, end := 0, len(sentence)
+	for _, sep := range clauseSeparators {
+		if i := strings.LastIndex(sentence[:offset], sep); i != -1 && i+len(sep) > start {
+			start = i + len(sep)
+		}
+		if i := strings.Index(sentence[mentionEnd:], sep); i != -1 && mentionEnd+i < end {
+			end = mentionEnd + i
+		}
+	}
+	return start, end
+}
+
+// analyzeAspectSentiment scores a clause with the general and aspect-specific lexicons
+func (d *MentionDetector) analyzeAspectSentiment(clause string) string {
+	positive := append(append([]string{}, positiveWords...), aspectPositiveWords...)
+	negative := append(append([]string{}, negativeWords...), aspectNegativeWords...)
+	return d.classifySentiment(clause, positive, negative)
+}
+
+// sentenceAround returns the sentence (or list item) containing text[position:position+length]
+func sentenceAround(text string, position, length int) string {
+	start, end := sentenceBounds(text, position, length)
+	return text[start:end]
+}
+
+// sentenceBounds returns the byte span of the sentence containing text[position:position+length]
+func sentenceBounds(text string, position, length int) (int, int) {
+	start := 0
+	for i := position - 1; i >= 0; i-- {
+		if isSentenceEnd(text, i) {
+			start = i + 1
+			break
+		}
+	}
+
+	end := len(text)
+	for i := position + length; i < len(text); i++ {
+		if isSentenceEnd(text, i) {
+			end = i + 1
+			break
+		}
+	}
+	return start, end
+}
+
+// isSentenceEnd treats newlines and ., !, ? followed by whitespace as boundaries,
+// so names like "Monday.com" are not split
+func isSentenceEnd(text string, i int) bool {
+	switch text[i] {
+	case '\n':
+		return true
+	case '.', '!', '?':
+		return i+1 >= len(text) || text[i+1] == ' ' || text[i+1] == '\n' || text[i+1] == '\t'
+	}
+	return false
+}
+
+// splitClauses breaks a lowercase sentence into clauses on commas and contrast words
+func splitClauses(sentence string) []string {
+	clauses := []string{sentence}
+	for _, sep := range clauseSeparators {
+		var next []string
+		for _, c := range clauses {
+			next = append(next, strings.Split(c, sep)...)
+		}
+		clauses = next
+	}
+	return clauses
+}
+
+// containsKeyword checks for any keyword on word boundaries
+func (d *MentionDetector) containsKeyword(text string, keywords []string) bool {
+	for _, kw := range keywords {
+		searchStart := 0
+		for {
+			pos := strings.Index(text[searchStart:], kw)
+			if pos == -1 {
+				break
+			}
+			actualPos := searchStart + pos
+			if d.isWordBoundary(text, actualPos, len(kw)) {
+				return true
+			}
+			searchStart = actualPos + len(kw)
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractAspectsUsesMentionClause(t *testing.T) {
+	cases := []struct {
+		text, entity string
+		want         map[string]string // aspect -> sentiment
+	}{
+		{"Acme has great support, but Globex's pricing is awful.", "Acme", map[string]string{AspectSupport: "positive"}},
+		{"Acme has great support, but Globex's pricing is awful.", "Globex", map[string]string{AspectPricing: "negative"}},
+		// Mention's clause names no aspect: fall back to the sentence
+		{"Acme is a solid pick, and its pricing is affordable.", "Acme", map[string]string{AspectPricing: "positive"}},
+	}
+
+	d := NewMentionDetector()
+	for _, tc := range cases {
+		pos := strings.Index(tc.text, tc.entity)
+		got := map[string]string{}
+		for _, a := range d.extractAspects(tc.text, pos, len(tc.entity)) {
+			got[a.Aspect] = a.Sentiment
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s in %q: got %v, want %v", tc.entity, tc.text, got, tc.want)
+			continue
+		}
+		for aspect, sentiment := range tc.want {
+			if got[aspect] != sentiment {
+				t.Errorf("%s in %q: got %v, want %v", tc.entity, tc.text, got, tc.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// ErrNoIndustry is returned when benchmarking a brand (or a query) without an industry
+var ErrNoIndustry = errors.New("brand has no industry")
+
+// benchmarkMetrics are the metrics benchmarked, in response order
+var benchmarkMetrics = []string{
+	"visibility_score",
+	"normalized_mention_rate",
+	"weighted_position_score",
+	"recommendation_rate",
+	"relative_sentiment_index",
+}
+
+// benchmarkValues holds one tracked entity's latest value of each benchmark metric
+type benchmarkValues map[string]float64
+
+// industrySample is the latest values of every tracked brand and competitor of an industry
+type industrySample struct {
+	industry   string
+	brands     map[int]benchmarkValues // By brand ID
+	entities   []benchmarkValues
+	peerBrands int // Distinct brands tracked by users other than the requester
+}
+
+// snapshotValues extracts the benchmark metrics of a brand snapshot
+func snapshotValues(s models.MetricSnapshot) benchmarkValues {
+	return benchmarkValues{
+		"visibility_score":         s.VisibilityScore,
+		"normalized_mention_rate":  s.NormalizedMentionRate,
+		"weighted_position_score":  s.WeightedPositionScore,
+		"recommendation_rate":      s.RecommendationRate,
+		"relative_sentiment_index": s.RelativeSentimentIndex,
+	}
+}
+
+// loadIndustrySample collects the latest run of every brand of an industry, across all users, with
+// the competitors scored in it. An entity tracked more than once (a competitor several brands watch,
+// or one that is itself a tracked brand) counts once, with its most recent values.
+// Brands of the excluded users (the requester) do not count as peer brands.
+func loadIndustrySample(industry string, excludedUsers ...int) (*industrySample, error) {
+	industry = strings.TrimSpace(industry)
+	if industry == "" {
+		return nil, ErrNoIndustry
+	}
+
+	latest, err := db.NewMetricRepository().GetLatestByIndustry(industry)
+	if err != nil {
+		return nil, err
+	}
+	competitorSnapshots, err := db.NewCompetitorSnapshotRepository().GetLatestRunByIndustry(industry)
+	if err != nil {
+		return nil, err
+	}
+	brands, err := db.NewBrandRepository().GetAllBrands()
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[int]bool{}
+	for _, userID := range excludedUsers {
+		excluded[userID] = true
+	}
+
+	sample := &industrySample{industry: industry, brands: map[int]benchmarkValues{}}
+	seen := map[string]bool{}
+	peers := map[string]bool{}
+	for _, brand := range brands {
+		snapshot, ok := latest[brand.ID]
+		if !ok {
+			continue
+		}
+		values := snapshotValues(snapshot)
+		sample.brands[brand.ID] = values
+		key := strings.ToLower(strings.TrimSpace(brand.Name))
+		if !excluded[brand.UserID] {
+			peers[key] = true
+		}
+		if !seen[key] {
+			seen[key] = true
+			sample.entities = append(sample.entities, values)
+		}
+	}
+	sample.peerBrands = len(peers)
+
+	// Newest runs first, so a competitor keeps its most recent values
+	sort.SliceStable(competitorSnapshots, func(i, j int) bool {
+		return competitorSnapshots[i].MetricSnapshotID > competitorSnapshots[j].MetricSnapshotID
+	})
+	for _, s := range competitorSnapshots {
+		key := strings.ToLower(strings.TrimSpace(s.CompetitorName))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		sample.entities = append(sample.entities, benchmarkValues{
+			"visibility_score":         s.VisibilityScore,
+			"normalized_mention_rate":  s.NormalizedMentionRate,
+			"weighted_position_score":  s.WeightedPositionScore,
+			"recommendation_rate":      s.RecommendationRate,
+			"relative_sentiment_index": s.RelativeSentimentIndex,
+		})
+	}
+	return sample, nil
+}
+
+// sorted returns every entity's value of a metric, ascending
+func (s *industrySample) sorted(metric string) []float64 {
+	values := make([]float64, len(s.entities))
+	for i, e := range s.entities {
+		values[i] = e[metric]
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// benchmark summarizes the sample as quantiles. The distribution is withheld until enough
+// brands of other users contribute: competitors do not count, since the requester picks and
+// knows them. No mean is published, as it would give away a lone peer's exact score.
+func (s *industrySample) benchmark() models.IndustryBenchmark {
+	b := models.IndustryBenchmark{
+		Industry:      s.industry,
+		SampleSize:    len(s.entities),
+		PeerBrands:    s.peerBrands,
+		MinSampleSize: scoring.MinBenchmarkSample,
+		Sufficient:    s.peerBrands >= scoring.MinBenchmarkSample,
+		Metrics:       []models.BenchmarkDistribution{},
+	}
+	if !b.Sufficient {
+		return b
+	}
+	for _, metric := range benchmarkMetrics {
+		values := s.sorted(metric)
+		b.Metrics = append(b.Metrics, models.BenchmarkDistribution{
+			Metric: metric,
+			P10:    scoring.Quantile(values, 0.10),
+			P25:    scoring.Quantile(values, 0.25),
+			P50:    scoring.Quantile(values, 0.50),
+			P75:    scoring.Quantile(values, 0.75),
+			P90:    scoring.Quantile(values, 0.90),
+		})
+	}
+	return b
+}
+
+// GetIndustryBenchmark returns the anonymized distribution of scores and components within an
+// industry, as seen by a user
+func (m *MetricsCalculator) GetIndustryBenchmark(industry string, userID int) (models.IndustryBenchmark, error) {
+	sample, err := loadIndustrySample(industry, userID)
+	if err != nil {
+		return models.IndustryBenchmark{}, err
+	}
+	return sample.benchmark(), nil
+}
+
+// GetBrandBenchmark returns a brand's industry benchmark, as seen by a user, and the percentile
+// of its latest run within it (sql.ErrNoRows when the brand does not exist)
+func (m *MetricsCalculator) GetBrandBenchmark(brandID, userID int) (*models.BrandBenchmark, error) {
+	brand, err := db.NewBrandRepository().GetByID(brandID)
+	if err != nil {
+		return nil, err
+	}
+	sample, err := loadIndustrySample(brand.Industry, userID, brand.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BrandBenchmark{
+		BrandID:     brand.ID,
+		BrandName:   brand.Name,
+		Benchmark:   sample.benchmark(),
+		Percentiles: []models.BenchmarkPercentile{},
+	}
+	values, ok := sample.brands[brand.ID]
+	if !ok || !result.Benchmark.Sufficient {
+		return result, nil
+	}
+	for _, metric := range benchmarkMetrics {
+		result.Percentiles = append(result.Percentiles, models.BenchmarkPercentile{
+			Metric:     metric,
+			Value:      values[metric],
+			Percentile: scoring.PercentileRank(sample.sorted(metric), values[metric]),
+		})
+	}
+	return result, nil
+}
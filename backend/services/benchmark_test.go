@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+func TestIndustryBenchmarkNeedsPeerBrands(t *testing.T) {
+	sample := func(entities, peerBrands int) *industrySample {
+		s := &industrySample{industry: "SaaS", peerBrands: peerBrands}
+		for i := 0; i < entities; i++ {
+			s.entities = append(s.entities, benchmarkValues{"visibility_score": float64(10 * i)})
+		}
+		return s
+	}
+
+	cases := []struct {
+		name                 string
+		entities, peerBrands int
+		want                 bool
+	}{
+		// The requester's own brand and four competitors must not unlock one peer's score
+		{"competitors do not count", 6, 1, false},
+		{"one peer short", 10, scoring.MinBenchmarkSample - 1, false},
+		{"enough peers", scoring.MinBenchmarkSample + 1, scoring.MinBenchmarkSample, true},
+	}
+	for _, tc := range cases {
+		b := sample(tc.entities, tc.peerBrands).benchmark()
+		if b.Sufficient != tc.want {
+			t.Errorf("%s: sufficient = %v, want %v", tc.name, b.Sufficient, tc.want)
+		}
+		if published := len(b.Metrics) > 0; published != tc.want {
+			t.Errorf("%s: metrics published = %v, want %v", tc.name, published, tc.want)
+		}
+		if b.SampleSize != tc.entities || b.PeerBrands != tc.peerBrands {
+			t.Errorf("%s: sample %d/%d peers, want %d/%d", tc.name, b.SampleSize, b.PeerBrands, tc.entities, tc.peerBrands)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// citationNearbyWindow is how far (in characters) a citation may be from a mention
+// to be attributed to it
+const citationNearbyWindow = 300
+
+var (
+	urlPattern        = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+	bareDomainPattern = regexp.MustCompile(`(?i)\b(?:[a-z0-9-]+\.)+(?:com|org|net|io|ai|co|dev|app|edu|gov|info|news)\b`)
+	// Lead-in phrases match in any case ("Source:", "SOURCE:"); the name must stay capitalized
+	accordingToPattern = regexp.MustCompile(`(?i:according to|as reported by|cited by|source:)\s+((?:[A-Z][\w&'.-]*\s?){1,4})`)
+)
+
+// knownSources maps review sites and analysts that assistants name without a URL
+var knownSources = map[string]string{
+	"G2":             "g2.com",
+	"Capterra":       "capterra.com",
+	"TrustRadius":    "trustradius.com",
+	"Gartner":        "gartner.com",
+	"Forrester":      "forrester.com",
+	"Wikipedia":      "wikipedia.org",
+	"Reddit":         "reddit.com",
+	"TechCrunch":     "techcrunch.com",
+	"Forbes":         "forbes.com",
+	"PCMag":          "pcmag.com",
+	"Product Hunt":   "producthunt.com",
+	"Stack Overflow": "stackoverflow.com",
+	"GitHub":         "github.com",
+}
+
+// DetectedCitation represents a citation found in a response before storage
+type DetectedCitation struct {
+	URL          string
+	Domain       string
+	SourceName   string
+	Position     int
+	MentionIndex int // Index into the detected mentions of the nearest mention, or -1
+}
+
+// CitationExtractor finds URLs, domains and named sources in AI responses
+type CitationExtractor struct{}
+
+// NewCitationExtractor creates a new citation extractor
+func NewCitationExtractor() *CitationExtractor {
+	return &CitationExtractor{}
+}
+
+// ExtractCitations finds all citations in a response and links each to the nearest mention
+func (e *CitationExtractor) ExtractCitations(text string, mentions []DetectedMention) []DetectedCitation {
+	var citations []DetectedCitation
+	var urlSpans [][]int // URL and domain spans, so named sources are not double counted
+
+	// 1. Full URLs (including markdown links)
+	for _, span := range urlPattern.FindAllStringIndex(text, -1) {
+		raw := strings.TrimRight(text[span[0]:span[1]], ".,;:!?*_")
+		urlSpans = append(urlSpans, []int{span[0], span[0] + len(raw)})
+		citations = append(citations, DetectedCitation{
+			URL:      raw,
+			Domain:   domainFromURL(raw),
+			Position: span[0],
+		})
+	}
+
+	// 2. Bare domains ("see hubspot.com") outside of URLs and email addresses
+	for _, span := range bareDomainPattern.FindAllStringIndex(text, -1) {
+		if insideSpans(span[0], urlSpans) || (span[0] > 0 && (text[span[0]-1] == '@' || text[span[0]-1] == '/')) {
+			continue
+		}
+		urlSpans = append(urlSpans, span)
+		citations = append(citations, DetectedCitation{
+			Domain:   normalizeDomain(text[span[0]:span[1]]),
+			Position: span[0],
+		})
+	}
+
+	// 3. Named sources: known review sites/analysts and "according to X"
+	sourcePositions := map[int]bool{}
+	for name, domain := range knownSources {
+		for _, pos := range wordPositions(text, name) {
+			if insideSpans(pos, urlSpans) {
+				continue // Already counted as a URL or domain
+			}
+			citations = append(citations, DetectedCitation{
+				Domain:     domain,
+				SourceName: name,
+				Position:   pos,
+			})
+			sourcePositions[pos] = true
+		}
+	}
+	for _, match := range accordingToPattern.FindAllStringSubmatchIndex(text, -1) {
+		name := strings.TrimSpace(strings.TrimRight(text[match[2]:match[3]], ".,;:"))
+		if name == "" || sourcePositions[match[2]] || bareDomainPattern.MatchString(name) {
+			continue
+		}
+		citations = append(citations, DetectedCitation{
+			SourceName: name,
+			Position:   match[2],
+		})
+	}
+
+	sort.Slice(citations, func(i, j int) bool { return citations[i].Position < citations[j].Position })
+	for i := range citations {
+		citations[i].MentionIndex = nearestMention(citations[i].Position, mentions)
+	}
+	return citations
+}
+
+// StoreCitations saves citations for a response, linking them to the stored mentions
+// (storedMentions must be in the same order as the detected mentions)
+func (e *CitationExtractor) StoreCitations(aiResponse *models.AIResponse, citations []DetectedCitation, storedMentions []models.Mention) error {
+	repo := db.NewCitationRepository()
+	for _, c := range citations {
+		citation := &models.ResponseCitation{
+			BrandID:      aiResponse.BrandID,
+			AIResponseID: aiResponse.ID,
+			URL:          c.URL,
+			Domain:       c.Domain,
+			SourceName:   c.SourceName,
+			Position:     c.Position,
+			ModelName:    aiResponse.ModelName,
+		}
+		if c.MentionIndex >= 0 && c.MentionIndex < len(storedMentions) {
+			m := storedMentions[c.MentionIndex]
+			citation.MentionID = m.ID
+			citation.EntityName = m.EntityName
+			citation.EntityType = m.EntityType
+		}
+		if err := repo.Create(citation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// domainFromURL returns the normalized host of a URL
+func domainFromURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return normalizeDomain(parsed.Hostname())
+}
+
+// normalizeDomain lowercases a host and drops a leading "www."
+func normalizeDomain(host string) string {
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// insideSpans reports whether pos falls inside any [start, end) span
+func insideSpans(pos int, spans [][]int) bool {
+	for _, s := range spans {
+		if pos >= s[0] && pos < s[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// wordPositions returns every case-sensitive, word-bounded occurrence of word
+func wordPositions(text, word string) []int {
+	var positions []int
+	d := &MentionDetector{}
+	searchStart := 0
+	for {
+		pos := strings.Index(text[searchStart:], word)
+		if pos == -1 {
+			break
+		}
+		actualPos := searchStart + pos
+		if d.isWordBoundary(text, actualPos, len(word)) {
+			positions = append(positions, actualPos)
+		}
+		searchStart = actualPos + len(word)
+	}
+	return positions
+}
+
+// nearestMention returns the index of the closest mention within the window, or -1
+func nearestMention(position int, mentions []DetectedMention) int {
+	best := -1
+	bestDistance := citationNearbyWindow + 1
+	for i, m := range mentions {
+		distance := abs(position - m.Position)
+		if distance < bestDistance {
+			best = i
+			bestDistance = distance
+		}
+	}
+	return best
+}
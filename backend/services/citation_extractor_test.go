@@ -0,0 +1,45 @@
+package services
+
+import "testing"
+
+func TestExtractCitationsNamedSources(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"Acme leads the market, according to Northwind Labs.", "Northwind Labs"},
+		{"Acme leads the market.\nSource: Northwind Labs", "Northwind Labs"},
+		{"Acme leads the market.\nSOURCE: Northwind Labs", "Northwind Labs"},
+		{"As reported by TechCrunch, Acme raised a round.", "TechCrunch"},
+	}
+
+	e := NewCitationExtractor()
+	for _, tc := range cases {
+		citations := e.ExtractCitations(tc.text, nil)
+		found := false
+		for _, c := range citations {
+			if c.SourceName == tc.want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ExtractCitations(%q) = %+v, want source %q", tc.text, citations, tc.want)
+		}
+	}
+}
+
+func TestExtractCitationsWithoutMentions(t *testing.T) {
+	text := "The best CRMs are reviewed at https://www.g2.com/categories/crm and capterra.com."
+	citations := NewCitationExtractor().ExtractCitations(text, nil)
+
+	var domains []string
+	for _, c := range citations {
+		domains = append(domains, c.Domain)
+		if c.MentionIndex != -1 {
+			t.Errorf("citation %q linked to mention %d, want none", c.Domain, c.MentionIndex)
+		}
+	}
+	if len(domains) != 2 || domains[0] != "g2.com" || domains[1] != "capterra.com" {
+		t.Errorf("ExtractCitations() domains = %q, want [g2.com capterra.com]", domains)
+	}
+}
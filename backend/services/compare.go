@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"sync"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/Sneh16Shah/ai-visibility-tracker/config"
 	"github.com/Sneh16Shah/ai-visibility-tracker/db"
 	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
 )
 
 // CompareService handles multi-model comparison via OpenRouter and Groq
@@ -97,6 +99,15 @@ type CompareModelsResult struct {
 	Errors       []string      `json:"errors,omitempty"`
 }
 
+// storedModelName is the ai_responses.model_name of a compare model: the identifier its
+// provider reports, so compare and analysis responses of the same model group together
+func storedModelName(modelID string) string {
+	if modelID == GroqModelInfo.ID {
+		return ai.GroqModelName
+	}
+	return ai.OpenRouterModelName(modelID)
+}
+
 // GetAvailableModels returns the list of available models for comparison
 func (s *CompareService) GetAvailableModels() []map[string]string {
 	var allModels []map[string]string
@@ -148,6 +159,7 @@ func (s *CompareService) RunComparison(ctx context.Context, req CompareModelsReq
 	if err != nil {
 		return nil, fmt.Errorf("failed to get brand: %w", err)
 	}
+	profile := loadScoringProfile(brand.ID)
 
 	// Get prompts
 	promptRepo := db.NewPromptRepository()
@@ -270,8 +282,8 @@ func (s *CompareService) RunComparison(ctx context.Context, req CompareModelsReq
 				detectedMentions := mentionDetector.DetectMentions(response, brand)
 				modelResult.Mentions = convertToModelMentions(detectedMentions)
 
-				// Calculate score
-				modelResult.Score = calculateVisibilityScore(modelResult.Mentions, brand.Name)
+				// Score the response with the same formula as the dashboard
+				modelResult.Score = int(math.Round(scoring.ResponseScore(profile, brand.SentimentSource, modelResult.Mentions)))
 
 				mu.Lock()
 				result.Results = append(result.Results, modelResult)
@@ -301,18 +313,17 @@ func (s *CompareService) RunComparison(ctx context.Context, req CompareModelsReq
 
 	// Store results to database for Dashboard display
 	if result.SuccessCalls > 0 {
-		s.storeCompareResults(req.BrandID, result)
+		s.storeCompareResults(ctx, req.BrandID, result)
 	}
 
 	return result, nil
 }
 
 // storeCompareResults saves compare results as AI responses for Dashboard visibility
-func (s *CompareService) storeCompareResults(brandID int, result *CompareModelsResult) {
+func (s *CompareService) storeCompareResults(ctx context.Context, brandID int, result *CompareModelsResult) {
 	log.Printf("📊 storeCompareResults: Starting for brand %d with %d results", brandID, len(result.Results))
 
 	responseRepo := db.NewAIResponseRepository()
-	mentionRepo := db.NewMentionRepository()
 	promptRepo := db.NewPromptRepository()
 
 	// Delete old responses for this brand before storing new ones
@@ -328,7 +339,14 @@ func (s *CompareService) storeCompareResults(brandID int, result *CompareModelsR
 		return
 	}
 
+	facts, err := db.NewFactRepository().GetByBrandID(brandID)
+	if err != nil {
+		log.Printf("Warning: failed to load facts for brand %d: %v", brandID, err)
+	}
+
 	mentionDetector := NewMentionDetector()
+	citationExtractor := NewCitationExtractor()
+	factChecker := NewFactChecker(facts)
 	storedCount := 0
 	mentionCount := 0
 
@@ -346,8 +364,8 @@ func (s *CompareService) storeCompareResults(brandID int, result *CompareModelsR
 			promptID = prompts[0].ID
 		}
 
-		// Store the response with the model name
-		storedResponse, err := responseRepo.Create(brandID, promptID, modelResult.PromptText, modelResult.Response, modelResult.ModelName)
+		// Store the response under the model's identifier, as the analysis service does
+		storedResponse, err := responseRepo.Create(brandID, promptID, modelResult.PromptText, modelResult.Response, storedModelName(modelResult.ModelID))
 		if err != nil {
 			log.Printf("Warning: failed to store response for model %s: %v", modelResult.ModelName, err)
 			continue
@@ -359,23 +377,24 @@ func (s *CompareService) storeCompareResults(brandID int, result *CompareModelsR
 		detectedMentions := mentionDetector.DetectMentions(modelResult.Response, brand)
 		log.Printf("📊 Detected %d mentions in response for model %s", len(detectedMentions), modelResult.ModelName)
 
-		for _, mention := range detectedMentions {
-			_, err := mentionRepo.Create(
-				storedResponse.ID,
-				mention.EntityName,
-				string(mention.EntityType),
-				string(mention.Sentiment),
-				mention.ContextSnippet,
-				mention.Position,
-				mention.IsRecommendation,
-				mention.PositionRank,
-			)
-			if err != nil {
-				log.Printf("Warning: failed to store mention: %v", err)
-			} else {
-				mentionCount++
+		storedMentions, err := mentionDetector.StoreMentions(storedResponse, detectedMentions)
+		if err != nil {
+			log.Printf("Warning: failed to store mention: %v", err)
+		} else {
+			citations := citationExtractor.ExtractCitations(modelResult.Response, detectedMentions)
+			if err := citationExtractor.StoreCitations(storedResponse, citations, storedMentions); err != nil {
+				log.Printf("Warning: failed to store citations: %v", err)
 			}
 		}
+		mentionCount += len(storedMentions)
+
+		findings, err := factChecker.CheckResponse(ctx, modelResult.Response, detectedMentions)
+		if err != nil {
+			log.Printf("Warning: fact verifier failed: %v", err)
+		}
+		if err := factChecker.StoreFindings(storedResponse, findings); err != nil {
+			log.Printf("Warning: failed to store misinformation findings: %v", err)
+		}
 	}
 
 	log.Printf("📊 storeCompareResults: Stored %d responses and %d mentions for brand %d", storedCount, mentionCount, brandID)
@@ -450,54 +469,17 @@ func convertToModelMentions(detected []DetectedMention) []models.Mention {
 	mentions := make([]models.Mention, len(detected))
 	for i, d := range detected {
 		mentions[i] = models.Mention{
-			EntityName:     d.EntityName,
-			EntityType:     d.EntityType,
-			Sentiment:      string(d.Sentiment),
-			ContextSnippet: d.ContextSnippet,
+			EntityName:       d.EntityName,
+			EntityType:       d.EntityType,
+			Sentiment:        string(d.Sentiment),
+			ContextSnippet:   d.ContextSnippet,
+			Position:         d.Position,
+			IsRecommendation: d.IsRecommendation,
+			PositionRank:     d.PositionRank,
+			ListLength:       d.ListLength,
+			ProductID:        d.ProductID,
+			ProductName:      d.ProductName,
 		}
 	}
 	return mentions
 }
-
-// Calculate visibility score based on mentions
-func calculateVisibilityScore(mentions []models.Mention, brandName string) int {
-	var brandMention *models.Mention
-	competitorCount := 0
-
-	for i := range mentions {
-		if mentions[i].EntityType == "brand" {
-			brandMention = &mentions[i]
-		} else if mentions[i].EntityType == "competitor" {
-			competitorCount++
-		}
-	}
-
-	if brandMention == nil {
-		return 0
-	}
-
-	// Base score: 50 for being mentioned
-	score := 50
-
-	// Sentiment bonus
-	switch brandMention.Sentiment {
-	case "positive":
-		score += 25
-	case "negative":
-		score -= 25
-	}
-
-	// Citation share bonus
-	totalMentions := 1 + competitorCount
-	citationShare := (1.0 / float64(totalMentions)) * 25
-	score += int(citationShare)
-
-	if score > 100 {
-		score = 100
-	}
-	if score < 0 {
-		score = 0
-	}
-
-	return score
-}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// minSuggestionResponses is how many responses must list a name before it is suggested
+const minSuggestionResponses = 2
+
+// Suggestion score weights. Names listed next to the brand itself rank above names
+// only ever listed next to a tracked competitor.
+const (
+	suggestionWeightFrequency = 0.4
+	suggestionWeightCoListed  = 0.3
+	suggestionWeightRecommend = 0.3
+)
+
+// nonEntityLabels are list item labels that look like names but are section titles
+var nonEntityLabels = map[string]bool{
+	"pros": true, "cons": true, "features": true, "key features": true, "pricing": true,
+	"price": true, "summary": true, "conclusion": true, "overview": true, "best for": true,
+	"ideal for": true, "why": true, "note": true, "notes": true, "verdict": true,
+	"recommendation": true, "recommendations": true, "alternatives": true, "comparison": true,
+	"ease of use": true, "integrations": true, "support": true, "security": true,
+	"performance": true, "use case": true, "use cases": true, "tool": true, "name": true,
+	"free": true, "paid": true, "yes": true, "no": true, "step": true, "tip": true,
+}
+
+// CompetitorDiscovery finds untracked products that AI responses list next to the brand
+type CompetitorDiscovery struct {
+	detector *MentionDetector
+}
+
+// NewCompetitorDiscovery creates a new competitor discovery service
+func NewCompetitorDiscovery() *CompetitorDiscovery {
+	return &CompetitorDiscovery{detector: NewMentionDetector()}
+}
+
+// candidateStats accumulates evidence for one discovered name
+type candidateStats struct {
+	name            string
+	responses       int
+	coListed        int
+	recommendations int
+	rankSum         int
+	snippet         string
+}
+
+// SuggestCompetitors scans the brand's stored responses and returns ranked suggestions
+func (s *CompetitorDiscovery) SuggestCompetitors(brandID int, limit int) ([]models.SuggestedCompetitor, error) {
+	brand, err := db.NewBrandRepository().GetByID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := db.NewAIResponseRepository().GetByBrandID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]*candidateStats{}
+	for _, response := range responses {
+		for key, c := range s.extractFromResponse(response.ResponseText, brand) {
+			acc, ok := stats[key]
+			if !ok {
+				acc = &candidateStats{name: c.name, snippet: c.snippet}
+				stats[key] = acc
+			}
+			acc.responses++
+			acc.rankSum += c.rank
+			if c.coListed {
+				acc.coListed++
+			}
+			if c.recommended {
+				acc.recommendations++
+			}
+		}
+	}
+
+	suggestions := []models.SuggestedCompetitor{}
+	for _, acc := range stats {
+		if acc.responses < minSuggestionResponses {
+			continue
+		}
+		frequency := float64(acc.responses) / float64(len(responses))
+		coListedRate := float64(acc.coListed) / float64(acc.responses)
+		recommendationRate := float64(acc.recommendations) / float64(acc.responses)
+		suggestions = append(suggestions, models.SuggestedCompetitor{
+			Name:               acc.name,
+			Responses:          acc.responses,
+			Frequency:          frequency,
+			CoListedWithBrand:  acc.coListed,
+			RecommendationRate: recommendationRate,
+			AverageRank:        float64(acc.rankSum) / float64(acc.responses),
+			SampleSnippet:      acc.snippet,
+			Score:              suggestionWeightFrequency*frequency + suggestionWeightCoListed*coListedRate + suggestionWeightRecommend*recommendationRate,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if suggestions[i].Responses != suggestions[j].Responses {
+			return suggestions[i].Responses > suggestions[j].Responses
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// candidate is a name found in one response
+type candidate struct {
+	name        string
+	rank        int
+	coListed    bool
+	recommended bool
+	snippet     string
+}
+
+// extractFromResponse returns untracked names (keyed by lowercase name) listed in
+// lists that also contain the brand or a tracked competitor. Names in lists without
+// either are skipped; a name counts as co-listed when any of its lists has the brand.
+func (s *CompetitorDiscovery) extractFromResponse(text string, brand *models.Brand) map[string]candidate {
+	found := map[string]candidate{}
+	mentions := s.detector.DetectMentions(text, brand)
+	if len(mentions) == 0 {
+		return found // Off-topic answer, its lists are not category lists
+	}
+	lowerText := strings.ToLower(text)
+	tracked := trackedNames(brand)
+
+	for _, list := range parseRankedLists(text) {
+		hasTracked, hasBrand := false, false
+		for _, m := range mentions {
+			if m.Position >= list.Items[0].Start && m.Position < list.Items[len(list.Items)-1].End {
+				hasTracked = true
+				if m.EntityType == "brand" {
+					hasBrand = true
+				}
+			}
+		}
+		if !hasTracked {
+			continue
+		}
+
+		for i, item := range list.Items {
+			name, offset := itemLabel(text[item.Start:item.End])
+			if !looksLikeEntity(name) || isTracked(name, tracked) {
+				continue
+			}
+			key := strings.ToLower(name)
+			if prev, seen := found[key]; seen {
+				prev.coListed = prev.coListed || hasBrand
+				found[key] = prev
+				continue
+			}
+			position := item.Start + offset
+			found[key] = candidate{
+				name:        name,
+				rank:        i + 1,
+				coListed:    hasBrand,
+				recommended: s.detector.isRecommendation(lowerText, name, position),
+				snippet:     strings.TrimSpace(firstLine(text[item.Start:item.End])),
+			}
+		}
+	}
+	return found
+}
+
+// itemLabel extracts the leading name of a list item ("**HubSpot** - ..." -> "HubSpot")
+// and its offset within the item
+func itemLabel(item string) (string, int) {
+	line := firstLine(item)
+
+	// Table rows: the first cell is the label
+	if strings.HasPrefix(strings.TrimSpace(line), "|") {
+		cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+		line = cells[0]
+	}
+
+	// Strip heading hashes, list markers and numbering
+	rest := strings.TrimLeft(line, " \t#")
+	rest = strings.TrimLeft(rest, "-*+• ")
+	rest = stripNumbering(rest)
+
+	// Bold label wins: "**HubSpot**: ..." or "**1. HubSpot**"
+	if strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__") {
+		marker := rest[:2]
+		if end := strings.Index(rest[2:], marker); end != -1 {
+			rest = stripNumbering(rest[2 : 2+end])
+		}
+	} else {
+		cut := len(rest)
+		for _, sep := range []string{" - ", " – ", " — ", ": ", " (", ", ", ". "} {
+			if i := strings.Index(rest, sep); i != -1 && i < cut {
+				cut = i
+			}
+		}
+		rest = rest[:cut]
+	}
+
+	name := strings.Trim(rest, " *_`[]:.")
+	offset := strings.Index(item, name)
+	if offset < 0 {
+		offset = 0
+	}
+	return name, offset
+}
+
+// stripNumbering removes a leading "1. " or "2) "
+func stripNumbering(s string) string {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i > 0 && i < len(s) && (s[i] == '.' || s[i] == ')') {
+		return strings.TrimSpace(s[i+1:])
+	}
+	return s
+}
+
+// firstLine returns text up to the first newline
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i != -1 {
+		return s[:i]
+	}
+	return s
+}
+
+// looksLikeEntity filters out section labels and sentences
+func looksLikeEntity(name string) bool {
+	if len(name) < 2 || len(name) > 40 {
+		return false
+	}
+	if nonEntityLabels[strings.ToLower(name)] {
+		return false
+	}
+	if len(strings.Fields(name)) > 4 {
+		return false
+	}
+	first := []rune(name)[0]
+	return unicode.IsUpper(first) || unicode.IsDigit(first)
+}
+
+// trackedNames returns lowercase brand, alias, product and competitor names
+func trackedNames(brand *models.Brand) []string {
+	names := []string{strings.ToLower(brand.Name)}
+	for _, a := range brand.Aliases {
+		names = append(names, strings.ToLower(a.Alias))
+	}
+	for _, p := range brand.Products {
+		names = append(names, strings.ToLower(p.Name))
+		for _, a := range p.Aliases {
+			names = append(names, strings.ToLower(a.Alias))
+		}
+	}
+	for _, c := range brand.Competitors {
+		names = append(names, strings.ToLower(c.Name))
+	}
+	return names
+}
+
+// isTracked reports whether a name is (or contains) an already tracked entity
+func isTracked(name string, tracked []string) bool {
+	lower := strings.ToLower(name)
+	for _, t := range tracked {
+		if t != "" && (lower == t || strings.Contains(lower, t)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestExtractFromResponse(t *testing.T) {
+	brand := &models.Brand{
+		Name:        "Acme",
+		Competitors: []models.Competitor{{ID: 1, Name: "Globex"}},
+	}
+
+	type want struct {
+		rank     int
+		coListed bool
+	}
+	cases := []struct {
+		name string
+		text string
+		want map[string]want
+	}{
+		{
+			"listed with the brand",
+			"Top CRMs:\n1. Acme - simple\n2. Initech - flexible\n3. Hooli - cheap",
+			map[string]want{"initech": {2, true}, "hooli": {3, true}},
+		},
+		{
+			"listed with a tracked competitor only",
+			"Acme is fine. Alternatives:\n1. Globex - big\n2. Initech - flexible",
+			map[string]want{"initech": {2, false}},
+		},
+		{
+			"list without a tracked entity",
+			"Acme is fine.\n\nOther tools:\n1. Initech - flexible\n2. Hooli - cheap",
+			map[string]want{},
+		},
+		{
+			"no tracked mention at all",
+			"Popular tools:\n1. Initech\n2. Hooli",
+			map[string]want{},
+		},
+		{
+			"co-listed in any list",
+			"Enterprise:\n1. Globex\n2. Initech\n\nSmall teams:\n1. Acme\n2. Initech",
+			map[string]want{"initech": {2, true}},
+		},
+		{
+			"tracked names and section labels skipped",
+			"1. **Acme CRM**: fast\n2. **Globex**: big\n3. **Pricing**: varies\n4. **Hooli**: cheap",
+			map[string]want{"hooli": {4, true}},
+		},
+		{
+			"table rows",
+			"| Tool | Price |\n|---|---|\n| Acme | $10 |\n| Initech | $20 |",
+			map[string]want{"initech": {2, true}},
+		},
+	}
+
+	s := NewCompetitorDiscovery()
+	for _, tc := range cases {
+		got := s.extractFromResponse(tc.text, brand)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+			continue
+		}
+		for key, w := range tc.want {
+			c, ok := got[key]
+			if !ok {
+				t.Errorf("%s: missing %q in %+v", tc.name, key, got)
+				continue
+			}
+			if c.rank != w.rank || c.coListed != w.coListed {
+				t.Errorf("%s: %q rank=%d coListed=%v, want rank=%d coListed=%v", tc.name, key, c.rank, c.coListed, w.rank, w.coListed)
+			}
+		}
+	}
+}
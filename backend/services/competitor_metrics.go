@@ -0,0 +1,218 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// competitorStat accumulates one competitor's mention data across a run's responses
+type competitorStat struct {
+	competitor models.Competitor
+	scoring.Stat
+}
+
+// competitorStats tracks every competitor of a brand, plus the sentiment of all tracked
+// mentions in the run that each competitor's relative sentiment is measured against
+type competitorStats struct {
+	byID         map[int]*competitorStat
+	idByName     map[string]int // Lowercased competitor name -> ID
+	sentimentSum float64        // All tracked mentions, brand included
+	mentions     int
+}
+
+// newCompetitorStats creates empty accumulators for a brand's competitors
+func newCompetitorStats(competitors []models.Competitor) *competitorStats {
+	stats := &competitorStats{byID: map[int]*competitorStat{}, idByName: map[string]int{}}
+	for _, c := range competitors {
+		stats.byID[c.ID] = &competitorStat{competitor: c}
+		stats.idByName[strings.ToLower(c.Name)] = c.ID
+	}
+	return stats
+}
+
+// addResponse counts the brand and competitor mentions of one response
+func (s *competitorStats) addResponse(mentions []models.Mention, sentimentSource string, profile *models.ScoringProfile) {
+	tally := scoring.NewRankTally()
+
+	for _, mention := range mentions {
+		sentiment, isRecommendation := scoring.EffectiveLabels(mention, sentimentSource)
+		if mention.EntityType == "brand" {
+			s.sentimentSum += scoring.SentimentValue(sentiment)
+			s.mentions++
+			continue
+		}
+
+		id, ok := s.idByName[strings.ToLower(mention.EntityName)]
+		if !ok {
+			continue // Competitor no longer tracked
+		}
+		s.sentimentSum += scoring.SentimentValue(sentiment)
+		s.mentions++
+		s.byID[id].AddMention(sentiment)
+		tally.Add(id, mention, isRecommendation)
+	}
+
+	for id, rank := range tally.BestRank {
+		s.byID[id].AddResponse(profile, rank, tally.Recommended[id])
+	}
+}
+
+// categoryAvgFor is the average sentiment of every other tracked entity in the run,
+// the same comparison the brand gets against its competitors
+func (s *competitorStats) categoryAvgFor(stat *competitorStat) float64 {
+	others := s.mentions - stat.Mentions
+	if others <= 0 {
+		return 3.0 // Default neutral
+	}
+	return (s.sentimentSum - stat.SentimentSum) / float64(others)
+}
+
+// competitorSnapshots scores every competitor with the brand's profile, linked to the
+// brand snapshot of the same run
+func (m *MetricsCalculator) competitorSnapshots(brandID, metricSnapshotID int, stats *competitorStats, profile *models.ScoringProfile, totalResponses int, snapshotDate time.Time) []models.CompetitorSnapshot {
+	if stats == nil || totalResponses == 0 {
+		return nil
+	}
+	var snapshots []models.CompetitorSnapshot
+	for _, stat := range stats.byID {
+		components := stat.Components(totalResponses, stats.categoryAvgFor(stat))
+
+		snapshots = append(snapshots, models.CompetitorSnapshot{
+			BrandID:                brandID,
+			CompetitorID:           stat.competitor.ID,
+			MetricSnapshotID:       metricSnapshotID,
+			VisibilityScore:        scoring.Score(profile, components),
+			MentionCount:           stat.Mentions,
+			PositiveCount:          stat.Positive,
+			NeutralCount:           stat.Neutral,
+			NegativeCount:          stat.Negative,
+			SnapshotDate:           snapshotDate,
+			NormalizedMentionRate:  components.MentionRate,
+			WeightedPositionScore:  components.PositionScore,
+			RecommendationRate:     components.RecommendationRate,
+			RelativeSentimentIndex: components.RelativeSentiment,
+			ResponseCount:          totalResponses,
+			ScoringProfileVersion:  profile.Version,
+		})
+	}
+	return snapshots
+}
+
+// storeCompetitorSnapshots stores the competitor snapshots of a run
+func (m *MetricsCalculator) storeCompetitorSnapshots(brandID, metricSnapshotID int, stats *competitorStats, profile *models.ScoringProfile, totalResponses int, snapshotDate time.Time) error {
+	repo := db.NewCompetitorSnapshotRepository()
+	snapshots := m.competitorSnapshots(brandID, metricSnapshotID, stats, profile, totalResponses, snapshotDate)
+	for i := range snapshots {
+		if err := repo.Create(&snapshots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCompetitorTrends returns each competitor's latest snapshot and its trend over the last N days
+func (m *MetricsCalculator) GetCompetitorTrends(brandID int, days int) ([]models.CompetitorTrend, error) {
+	brand, err := db.NewBrandRepository().GetByID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := db.NewCompetitorSnapshotRepository().GetSince(brandID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	byCompetitor := map[int][]models.CompetitorSnapshot{}
+	for _, s := range snapshots {
+		byCompetitor[s.CompetitorID] = append(byCompetitor[s.CompetitorID], s)
+	}
+
+	trends := []models.CompetitorTrend{}
+	for _, c := range brand.Competitors {
+		history := byCompetitor[c.ID]
+		if history == nil {
+			history = []models.CompetitorSnapshot{}
+		}
+		trend := models.CompetitorTrend{
+			CompetitorID:   c.ID,
+			CompetitorName: c.Name,
+			Trends:         history,
+		}
+		if len(history) > 0 {
+			latest := history[len(history)-1]
+			trend.Latest = &latest
+		}
+		trends = append(trends, trend)
+	}
+	return trends, nil
+}
+
+// GetVisibilityRanking ranks the brand and its competitors by composite score in the latest run
+func (m *MetricsCalculator) GetVisibilityRanking(brandID int) (*models.VisibilityRanking, error) {
+	brand, err := db.NewBrandRepository().GetByID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := db.NewMetricRepository().GetLatestByBrandID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	competitors, err := db.NewCompetitorSnapshotRepository().GetByMetricSnapshotID(latest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.RankingEntry{{
+		EntityName:             brand.Name,
+		EntityType:             "brand",
+		VisibilityScore:        latest.VisibilityScore,
+		NormalizedMentionRate:  latest.NormalizedMentionRate,
+		WeightedPositionScore:  latest.WeightedPositionScore,
+		RecommendationRate:     latest.RecommendationRate,
+		RelativeSentimentIndex: latest.RelativeSentimentIndex,
+		MentionCount:           latest.MentionCount,
+	}}
+	for _, c := range competitors {
+		entries = append(entries, models.RankingEntry{
+			EntityName:             c.CompetitorName,
+			EntityType:             "competitor",
+			CompetitorID:           c.CompetitorID,
+			VisibilityScore:        c.VisibilityScore,
+			NormalizedMentionRate:  c.NormalizedMentionRate,
+			WeightedPositionScore:  c.WeightedPositionScore,
+			RecommendationRate:     c.RecommendationRate,
+			RelativeSentimentIndex: c.RelativeSentimentIndex,
+			MentionCount:           c.MentionCount,
+		})
+	}
+
+	// Highest score first; the brand wins ties so it is never ranked below an equal competitor
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].VisibilityScore > entries[j].VisibilityScore
+	})
+
+	ranking := &models.VisibilityRanking{
+		BrandID:          brandID,
+		MetricSnapshotID: latest.ID,
+		SnapshotDate:     latest.SnapshotDate,
+		Entries:          entries,
+	}
+	for i := range entries {
+		// Equal scores share a rank
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].VisibilityScore == entries[i-1].VisibilityScore {
+			entries[i].Rank = entries[i-1].Rank
+		}
+		if entries[i].EntityType == "brand" {
+			ranking.BrandRank = entries[i].Rank
+		}
+	}
+	return ranking, nil
+}
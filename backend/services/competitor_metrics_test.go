@@ -0,0 +1,92 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+func TestCompetitorSnapshots(t *testing.T) {
+	profile := DefaultScoringProfile(1)
+	stats := newCompetitorStats([]models.Competitor{{ID: 1, Name: "Globex"}, {ID: 2, Name: "Initech"}})
+
+	responses := [][]models.Mention{
+		{
+			{EntityType: "brand", EntityName: "Acme", Sentiment: "positive", PositionRank: 1, IsRecommendation: true},
+			{EntityType: "competitor", EntityName: "Globex", Sentiment: "neutral", PositionRank: 2},
+			{EntityType: "competitor", EntityName: "globex", Sentiment: "negative"}, // Same response: best rank stays 2
+		},
+		{
+			{EntityType: "competitor", EntityName: "Globex", Sentiment: "positive", PositionRank: 1, IsRecommendation: true},
+			{EntityType: "competitor", EntityName: "Hooli", Sentiment: "positive", PositionRank: 2}, // Untracked
+		},
+		{},
+	}
+	for _, mentions := range responses {
+		stats.addResponse(mentions, SentimentSourceRules, profile)
+	}
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := NewMetricsCalculator().competitorSnapshots(9, 42, stats, profile, len(responses), date)
+	if len(snapshots) != 2 {
+		t.Fatalf("len(snapshots) = %d, want 2", len(snapshots))
+	}
+	byID := map[int]models.CompetitorSnapshot{}
+	for _, s := range snapshots {
+		byID[s.CompetitorID] = s
+	}
+
+	globex := byID[1]
+	if globex.BrandID != 9 || globex.MetricSnapshotID != 42 || !globex.SnapshotDate.Equal(date) || globex.ResponseCount != 3 {
+		t.Errorf("Globex snapshot = %+v, want brand 9, snapshot 42, 3 responses", globex)
+	}
+	if globex.MentionCount != 3 || globex.PositiveCount != 1 || globex.NeutralCount != 1 || globex.NegativeCount != 1 {
+		t.Errorf("Globex counts = %d (%d/%d/%d), want 3 (1/1/1)", globex.MentionCount, globex.PositiveCount, globex.NeutralCount, globex.NegativeCount)
+	}
+
+	// Globex averages 3 against the brand's 5, the only other tracked mention
+	want := scoring.Components{
+		MentionRate:        2.0 / 3,
+		PositionScore:      (profile.PositionSecond + profile.PositionFirst) / 3,
+		RecommendationRate: 1.0 / 3,
+		RelativeSentiment:  (3.0 - 5.0 + 4.0) / 8.0,
+	}
+	got := scoring.Components{
+		MentionRate:        globex.NormalizedMentionRate,
+		PositionScore:      globex.WeightedPositionScore,
+		RecommendationRate: globex.RecommendationRate,
+		RelativeSentiment:  globex.RelativeSentimentIndex,
+	}
+	if !closeComponents(got, want) {
+		t.Errorf("Globex components = %+v, want %+v", got, want)
+	}
+	if math.Abs(globex.VisibilityScore-51.25) > 1e-9 {
+		t.Errorf("Globex score = %v, want 51.25", globex.VisibilityScore)
+	}
+
+	if initech := byID[2]; initech.MentionCount != 0 || initech.VisibilityScore != 0 || initech.RelativeSentimentIndex != 0 {
+		t.Errorf("unmentioned Initech = %+v, want a zero score without sentiment credit", initech)
+	}
+}
+
+func TestCompetitorSnapshotsWithoutResponses(t *testing.T) {
+	m := NewMetricsCalculator()
+	profile := DefaultScoringProfile(1)
+	stats := newCompetitorStats([]models.Competitor{{ID: 1, Name: "Globex"}})
+
+	if got := m.competitorSnapshots(1, 1, stats, profile, 0, time.Now()); got != nil {
+		t.Errorf("competitorSnapshots() with no responses = %+v, want nil", got)
+	}
+	if got := m.competitorSnapshots(1, 1, nil, profile, 3, time.Now()); got != nil {
+		t.Errorf("competitorSnapshots() without stats = %+v, want nil", got)
+	}
+}
+
+func closeComponents(a, b scoring.Components) bool {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	return near(a.MentionRate, b.MentionRate) && near(a.PositionScore, b.PositionScore) &&
+		near(a.RecommendationRate, b.RecommendationRate) && near(a.RelativeSentiment, b.RelativeSentiment)
+}
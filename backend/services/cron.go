@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule wraps every cron expression and time zone validation failure
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// cronSearchYears bounds the search for the next run: an expression with no match
+// within it (e.g. "0 0 30 2 *") never fires
+const cronSearchYears = 5
+
+// cronMacros are the shorthand expressions accepted in place of five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	dayNames = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// CronSchedule is a parsed five-field cron expression (minute, hour, day of month, month,
+// day of week) evaluated in a time zone
+type CronSchedule struct {
+	Expression string
+	Location   *time.Location
+
+	minutes, hours, days, months, weekdays uint64 // Bit i set when value i matches
+	anyDay, anyWeekday                     bool   // Day of month / week left as "*"
+}
+
+// SplitCronTimezone separates a trailing IANA time zone from a cron expression,
+// e.g. "0 6 * * MON Europe/Berlin" into "0 6 * * MON" and "Europe/Berlin"
+func SplitCronTimezone(value string) (expression, timezone string) {
+	fields := strings.Fields(value)
+	if n := len(fields); n == 6 || (n == 2 && strings.HasPrefix(fields[0], "@")) {
+		return strings.Join(fields[:n-1], " "), fields[n-1]
+	}
+	return strings.Join(fields, " "), ""
+}
+
+// ParseCron parses a cron expression in an IANA time zone (UTC when empty). Fields accept
+// "*", values, ranges ("1-5"), steps ("*/15", "8-18/2"), lists ("1,15") and month and
+// weekday names; Sunday is 0 or 7. As in standard cron, when both day of month and day
+// of week are restricted a day matching either one fires.
+func ParseCron(expression, timezone string) (*CronSchedule, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, timezone)
+	}
+
+	expression = strings.Join(strings.Fields(expression), " ")
+	fields := strings.Fields(expression)
+	if len(fields) == 1 {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown macro %q", ErrInvalidSchedule, fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields (minute hour day-of-month month day-of-week), got %d", ErrInvalidSchedule, len(fields))
+	}
+
+	s := &CronSchedule{Expression: expression, Location: loc}
+	specs := []struct {
+		name     string
+		min, max int
+		names    map[string]int
+		bits     *uint64
+	}{
+		{"minute", 0, 59, nil, &s.minutes},
+		{"hour", 0, 23, nil, &s.hours},
+		{"day of month", 1, 31, nil, &s.days},
+		{"month", 1, 12, monthNames, &s.months},
+		{"day of week", 0, 7, dayNames, &s.weekdays},
+	}
+	for i, spec := range specs {
+		bits, err := parseCronField(fields[i], spec.min, spec.max, spec.names)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSchedule, spec.name, err)
+		}
+		*spec.bits = bits
+	}
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1 // 7 is Sunday too
+	}
+	s.anyDay = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	s.anyWeekday = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w: %q never fires", ErrInvalidSchedule, expression)
+	}
+	return s, nil
+}
+
+// parseCronField parses one comma-separated field into a bit set of matching values
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = cronValue(bounds[0], names); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(bounds[1], names); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := cronValue(rangePart, names)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			if step > 1 {
+				hi = max // "5/15" means from 5 on, every 15
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// cronValue parses a number or, where the field has them, a three-letter name
+func cronValue(value string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return v, nil
+}
+
+// dayMatches applies the day-of-month / day-of-week rule to a day
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Next returns the first time strictly after "after" that the schedule fires, or the zero time when it
+// does not fire within the next five years. Wall-clock times skipped by a daylight saving
+// change do not fire; times repeated by one fire once, on their first occurrence.
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	t := after.In(s.Location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.Location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.Location)
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute) // Next wall-clock hour, whatever the zone's offset
+			continue
+		}
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		if earlier := t.Add(-time.Hour); earlier.Hour() == t.Hour() && earlier.Day() == t.Day() {
+			t = t.Add(time.Minute) // Second pass through a repeated hour
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+
+	cases := []struct {
+		expression, timezone string
+		after, want          time.Time
+	}{
+		// Mondays at 06:00 Berlin time, from a Wednesday
+		{"0 6 * * MON", "Europe/Berlin", time.Date(2026, 10, 14, 12, 0, 0, 0, berlin), time.Date(2026, 10, 19, 6, 0, 0, 0, berlin)},
+		// Strictly after: a run at the exact time moves to the next one
+		{"0 6 * * MON", "Europe/Berlin", time.Date(2026, 10, 19, 6, 0, 0, 0, berlin), time.Date(2026, 10, 26, 6, 0, 0, 0, berlin)},
+		// Steps and ranges
+		{"*/15 9-17 * * 1-5", "UTC", time.Date(2026, 10, 16, 17, 50, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		// Day of month and day of week both restricted: either matches
+		{"0 0 13 * FRI", "UTC", time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)},
+		// Sunday as 7, and macros
+		{"30 8 * * 7", "UTC", time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC), time.Date(2026, 10, 25, 8, 30, 0, 0, time.UTC)},
+		{"@monthly", "UTC", time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// 02:30 does not exist on the spring-forward day: that day is skipped
+		{"30 2 * * *", "Europe/Berlin", time.Date(2026, 3, 28, 12, 0, 0, 0, berlin), time.Date(2026, 3, 30, 2, 30, 0, 0, berlin)},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.expression, c.timezone)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", c.expression, err)
+		}
+		if got := s.Next(c.after); !got.Equal(c.want) {
+			t.Errorf("%q after %s = %s, want %s", c.expression, c.after, got, c.want)
+		}
+	}
+
+	// 02:30 happens twice on the fall-back day: it fires once
+	s, _ := ParseCron("30 2 * * *", "Europe/Berlin")
+	first := s.Next(time.Date(2026, 10, 25, 0, 0, 0, 0, berlin))
+	if second := s.Next(first); second.Sub(first) < 24*time.Hour {
+		t.Errorf("repeated 02:30 fired twice: %s then %s", first, second)
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	cases := []struct{ expression, timezone string }{
+		{"0 6 * *", "UTC"},              // Four fields
+		{"60 * * * *", "UTC"},           // Minute out of range
+		{"0 6 * * FUNDAY", "UTC"},       // Unknown day
+		{"*/0 * * * *", "UTC"},          // Zero step
+		{"0 0 30 2 *", "UTC"},           // Never fires
+		{"0 6 * * MON", "Mars/Olympus"}, // Unknown zone
+	}
+	for _, c := range cases {
+		if _, err := ParseCron(c.expression, c.timezone); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("ParseCron(%q, %q) err = %v, want ErrInvalidSchedule", c.expression, c.timezone, err)
+		}
+	}
+}
+
+func TestSplitCronTimezone(t *testing.T) {
+	expression, timezone := SplitCronTimezone("0 6 * * MON Europe/Berlin")
+	if expression != "0 6 * * MON" || timezone != "Europe/Berlin" {
+		t.Errorf("got %q, %q", expression, timezone)
+	}
+	if expression, timezone = SplitCronTimezone("@daily America/New_York"); expression != "@daily" || timezone != "America/New_York" {
+		t.Errorf("macro: got %q, %q", expression, timezone)
+	}
+	if expression, timezone = SplitCronTimezone("0 6 * * MON"); expression != "0 6 * * MON" || timezone != "" {
+		t.Errorf("no zone: got %q, %q", expression, timezone)
+	}
+}
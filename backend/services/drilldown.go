@@ -0,0 +1,174 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// drilldownTally sums the prompt snapshot counts of one run: one prompt's, or every prompt's of a category
+type drilldownTally struct {
+	metricSnapshotID int
+	date             time.Time
+	prompts          int
+	snapshot         models.PromptSnapshot // Counts only
+}
+
+// add counts one prompt snapshot
+func (t *drilldownTally) add(s models.PromptSnapshot) {
+	t.metricSnapshotID, t.date = s.MetricSnapshotID, s.SnapshotDate
+	t.prompts++
+	t.snapshot.ResponseCount += s.ResponseCount
+	t.snapshot.ResponsesWithBrand += s.ResponsesWithBrand
+	t.snapshot.MentionCount += s.MentionCount
+	t.snapshot.RecommendedResponses += s.RecommendedResponses
+	t.snapshot.RankedResponses += s.RankedResponses
+	t.snapshot.RankTotal += s.RankTotal
+	t.snapshot.SentimentSum += s.SentimentSum
+}
+
+// point derives the drill-down rates from the summed counts
+func (t *drilldownTally) point() models.DrilldownPoint {
+	s := t.snapshot
+	p := models.DrilldownPoint{
+		MetricSnapshotID:   t.metricSnapshotID,
+		SnapshotDate:       t.date,
+		Responses:          s.ResponseCount,
+		ResponsesWithBrand: s.ResponsesWithBrand,
+		Mentions:           s.MentionCount,
+		AvgSentiment:       3, // Neutral
+	}
+	if s.ResponseCount > 0 {
+		p.MentionRate = float64(s.ResponsesWithBrand) / float64(s.ResponseCount)
+		p.RecommendationRate = float64(s.RecommendedResponses) / float64(s.ResponseCount)
+	}
+	if s.RankedResponses > 0 {
+		p.AvgRank = float64(s.RankTotal) / float64(s.RankedResponses)
+	}
+	if s.MentionCount > 0 {
+		p.AvgSentiment = s.SentimentSum / float64(s.MentionCount)
+	}
+	return p
+}
+
+// drilldownSeries groups prompt snapshots into one tally per run, oldest first
+type drilldownSeries struct {
+	runs []*drilldownTally
+}
+
+// add counts a prompt snapshot under its run; snapshots arrive oldest first
+func (s *drilldownSeries) add(snapshot models.PromptSnapshot) {
+	n := len(s.runs)
+	if n == 0 || !sameRun(s.runs[n-1], snapshot) {
+		s.runs = append(s.runs, &drilldownTally{})
+		n++
+	}
+	s.runs[n-1].add(snapshot)
+}
+
+// sameRun reports whether a snapshot belongs to the run a tally collects
+func sameRun(t *drilldownTally, s models.PromptSnapshot) bool {
+	if t.metricSnapshotID > 0 || s.MetricSnapshotID > 0 {
+		return t.metricSnapshotID == s.MetricSnapshotID
+	}
+	return t.date.Equal(s.SnapshotDate)
+}
+
+// trend returns every run's point and the latest one
+func (s *drilldownSeries) trend() ([]models.DrilldownPoint, models.DrilldownPoint) {
+	points := make([]models.DrilldownPoint, len(s.runs))
+	for i, t := range s.runs {
+		points[i] = t.point()
+	}
+	return points, points[len(points)-1]
+}
+
+// GetPromptDrilldown returns the brand's mention rate, average rank, recommendation rate and
+// sentiment within each prompt over the last N days, optionally for one category only.
+// Prompts are ordered by category, then by latest mention rate, lowest first.
+func (m *MetricsCalculator) GetPromptDrilldown(brandID, days int, category string) ([]models.PromptDrilldown, error) {
+	snapshots, err := db.NewPromptSnapshotRepository().GetSince(brandID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+	prompts, err := db.NewPromptRepository().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	templates := map[int]string{}
+	for _, p := range prompts {
+		templates[p.ID] = p.Template
+	}
+
+	series := map[int]*drilldownSeries{}
+	categories := map[int]string{}
+	var order []int
+	for _, s := range snapshots {
+		if category != "" && s.Category != category {
+			continue
+		}
+		if _, ok := series[s.PromptID]; !ok {
+			series[s.PromptID] = &drilldownSeries{}
+			order = append(order, s.PromptID)
+		}
+		series[s.PromptID].add(s)
+		categories[s.PromptID] = s.Category // Latest category wins
+	}
+
+	drilldowns := []models.PromptDrilldown{}
+	for _, promptID := range order {
+		trend, latest := series[promptID].trend()
+		drilldowns = append(drilldowns, models.PromptDrilldown{
+			PromptID: promptID,
+			Prompt:   templates[promptID],
+			Category: categories[promptID],
+			Latest:   latest,
+			Trend:    trend,
+		})
+	}
+	sort.SliceStable(drilldowns, func(i, j int) bool {
+		if drilldowns[i].Category != drilldowns[j].Category {
+			return drilldowns[i].Category < drilldowns[j].Category
+		}
+		return drilldowns[i].Latest.MentionRate < drilldowns[j].Latest.MentionRate
+	})
+	return drilldowns, nil
+}
+
+// GetCategoryDrilldown returns the brand's mention rate, average rank, recommendation rate and
+// sentiment within each prompt category over the last N days, pooling the category's prompts
+// per run. Categories are ordered by latest mention rate, lowest first.
+func (m *MetricsCalculator) GetCategoryDrilldown(brandID, days int) ([]models.CategoryDrilldown, error) {
+	snapshots, err := db.NewPromptSnapshotRepository().GetSince(brandID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	series := map[string]*drilldownSeries{}
+	var order []string
+	for _, s := range snapshots {
+		if _, ok := series[s.Category]; !ok {
+			series[s.Category] = &drilldownSeries{}
+			order = append(order, s.Category)
+		}
+		series[s.Category].add(s)
+	}
+
+	drilldowns := []models.CategoryDrilldown{}
+	for _, category := range order {
+		s := series[category]
+		trend, latest := s.trend()
+		drilldowns = append(drilldowns, models.CategoryDrilldown{
+			Category: category,
+			Prompts:  s.runs[len(s.runs)-1].prompts,
+			Latest:   latest,
+			Trend:    trend,
+		})
+	}
+	sort.SliceStable(drilldowns, func(i, j int) bool {
+		return drilldowns[i].Latest.MentionRate < drilldowns[j].Latest.MentionRate
+	})
+	return drilldowns, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestPromptSnapshots(t *testing.T) {
+	profile := DefaultScoringProfile(1)
+	runs := promptRuns{}
+	runs.addResponse(1, "pricing", []models.Mention{
+		{EntityType: "brand", Sentiment: "positive", PositionRank: 1, IsRecommendation: true},
+		{EntityType: "competitor", Sentiment: "neutral", PositionRank: 2},
+	}, profile, SentimentSourceRules)
+	runs.addResponse(1, "pricing", []models.Mention{
+		{EntityType: "brand", Sentiment: "neutral", PositionRank: 3},
+		{EntityType: "brand", Sentiment: "negative"}, // Unranked repeat: best rank stays 3
+	}, profile, SentimentSourceRules)
+	runs.addResponse(2, "pricing", []models.Mention{
+		{EntityType: "competitor", Sentiment: "positive", PositionRank: 1},
+	}, profile, SentimentSourceRules)
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	byPrompt := map[int]models.PromptSnapshot{}
+	for _, s := range NewMetricsCalculator().promptSnapshots(9, 42, runs, date) {
+		byPrompt[s.PromptID] = s
+	}
+
+	want := map[int]models.PromptSnapshot{
+		1: {BrandID: 9, MetricSnapshotID: 42, PromptID: 1, Category: "pricing", SnapshotDate: date,
+			ResponseCount: 2, ResponsesWithBrand: 2, MentionCount: 3, RecommendedResponses: 1,
+			RankedResponses: 2, RankTotal: 4, SentimentSum: 9},
+		2: {BrandID: 9, MetricSnapshotID: 42, PromptID: 2, Category: "pricing", SnapshotDate: date,
+			ResponseCount: 1},
+	}
+	if !reflect.DeepEqual(byPrompt, want) {
+		t.Errorf("promptSnapshots() = %+v, want %+v", byPrompt, want)
+	}
+}
+
+func TestDrilldownSeries(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	snapshot := func(runID int, date time.Time, responses, withBrand, mentions, recommended, ranked, rankTotal int, sentimentSum float64) models.PromptSnapshot {
+		return models.PromptSnapshot{
+			MetricSnapshotID: runID, SnapshotDate: date,
+			ResponseCount: responses, ResponsesWithBrand: withBrand, MentionCount: mentions,
+			RecommendedResponses: recommended, RankedResponses: ranked, RankTotal: rankTotal, SentimentSum: sentimentSum,
+		}
+	}
+
+	cases := []struct {
+		name      string
+		snapshots []models.PromptSnapshot
+		want      []models.DrilldownPoint
+	}{
+		{
+			name: "prompts of a run are pooled",
+			snapshots: []models.PromptSnapshot{
+				snapshot(10, day1, 2, 2, 3, 1, 2, 4, 9),
+				snapshot(10, day1, 1, 0, 0, 0, 0, 0, 0),
+				snapshot(11, day2, 4, 1, 1, 1, 0, 0, 5),
+			},
+			want: []models.DrilldownPoint{
+				{MetricSnapshotID: 10, SnapshotDate: day1, Responses: 3, ResponsesWithBrand: 2, Mentions: 3,
+					MentionRate: 2.0 / 3, AvgRank: 2, RecommendationRate: 1.0 / 3, AvgSentiment: 3},
+				{MetricSnapshotID: 11, SnapshotDate: day2, Responses: 4, ResponsesWithBrand: 1, Mentions: 1,
+					MentionRate: 0.25, RecommendationRate: 0.25, AvgSentiment: 5},
+			},
+		},
+		{
+			name: "unlinked snapshots are grouped by date",
+			snapshots: []models.PromptSnapshot{
+				snapshot(0, day1, 1, 1, 1, 0, 1, 1, 1),
+				snapshot(0, day1, 1, 0, 0, 0, 0, 0, 0),
+				snapshot(0, day2, 2, 0, 0, 0, 0, 0, 0),
+			},
+			want: []models.DrilldownPoint{
+				{SnapshotDate: day1, Responses: 2, ResponsesWithBrand: 1, Mentions: 1, MentionRate: 0.5, AvgRank: 1, AvgSentiment: 1},
+				{SnapshotDate: day2, Responses: 2, AvgSentiment: 3},
+			},
+		},
+		{
+			name:      "empty run is neutral",
+			snapshots: []models.PromptSnapshot{snapshot(12, day1, 0, 0, 0, 0, 0, 0, 0)},
+			want:      []models.DrilldownPoint{{MetricSnapshotID: 12, SnapshotDate: day1, AvgSentiment: 3}},
+		},
+	}
+
+	for _, tc := range cases {
+		var series drilldownSeries
+		for _, s := range tc.snapshots {
+			series.add(s)
+		}
+		points, latest := series.trend()
+		if len(points) != len(tc.want) {
+			t.Errorf("%s: got %d points, want %d", tc.name, len(points), len(tc.want))
+			continue
+		}
+		for i := range points {
+			if !closePoint(points[i], tc.want[i]) {
+				t.Errorf("%s: point %d = %+v, want %+v", tc.name, i, points[i], tc.want[i])
+			}
+		}
+		if !closePoint(latest, tc.want[len(tc.want)-1]) {
+			t.Errorf("%s: latest = %+v, want the last point", tc.name, latest)
+		}
+	}
+}
+
+func closePoint(a, b models.DrilldownPoint) bool {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	return a.MetricSnapshotID == b.MetricSnapshotID && a.SnapshotDate.Equal(b.SnapshotDate) &&
+		a.Responses == b.Responses && a.ResponsesWithBrand == b.ResponsesWithBrand && a.Mentions == b.Mentions &&
+		near(a.MentionRate, b.MentionRate) && near(a.AvgRank, b.AvgRank) &&
+		near(a.RecommendationRate, b.RecommendationRate) && near(a.AvgSentiment, b.AvgSentiment)
+}
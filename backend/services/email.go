@@ -6,6 +6,7 @@ import (
 	"net/smtp"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Sneh16Shah/ai-visibility-tracker/db"
 	"github.com/Sneh16Shah/ai-visibility-tracker/models"
@@ -62,8 +63,9 @@ func (e *EmailService) IsEnabled() bool {
 	return e.enabled
 }
 
-// SendAlert sends a visibility alert email
-func (e *EmailService) SendAlert(toEmail string, brand *models.Brand, currentScore, threshold float64) error {
+// SendAlert sends a visibility alert email. change is the move since the previous
+// snapshot (nil when there is none) and tells the reader whether it is just noise.
+func (e *EmailService) SendAlert(toEmail string, brand *models.Brand, currentScore, threshold float64, change *models.ScoreChange) error {
 	if !e.enabled {
 		log.Println("Email not configured, skipping alert")
 		return nil
@@ -78,7 +80,7 @@ Your brand's AI visibility score has dropped below your configured threshold.
 
 Current Score: %.1f
 Alert Threshold: %.1f
-
+%s
 This means AI assistants are mentioning your brand less frequently than expected.
 
 Recommended Actions:
@@ -91,11 +93,62 @@ View Dashboard: http://localhost:5173/
 
 ---
 AI Visibility Tracker
-`, brand.Name, currentScore, threshold)
+`, brand.Name, currentScore, threshold, describeScoreChange(change))
 
 	return e.sendEmail(toEmail, subject, body)
 }
 
+// SendForecastAlert warns that a brand's score, still above its threshold, is projected to fall
+// below it within the forecast horizon
+func (e *EmailService) SendForecastAlert(toEmail string, brand *models.Brand, currentScore float64, forecast *models.VisibilityForecast) error {
+	if !e.enabled {
+		log.Println("Email not configured, skipping alert")
+		return nil
+	}
+
+	crossing := forecast.Forecast[forecast.CrossesThresholdWeeks-1]
+	subject := fmt.Sprintf("📉 AI Visibility Forecast: %s projected below %d within %d weeks", brand.Name, int(forecast.AlertThreshold), forecast.CrossesThresholdWeeks)
+
+	body := fmt.Sprintf(`
+AI Visibility Forecast Alert for %s
+
+Your brand's AI visibility score is trending towards your configured threshold.
+
+Current Score: %.1f
+Alert Threshold: %.1f
+Trend: %+.1f points per week over the last %d weeks
+Projected Score (week of %s): %.1f (95%% range %.1f - %.1f)
+
+This is a projection from past runs, not a measurement. Acting now can keep the score above the threshold.
+
+View Dashboard: http://localhost:5173/
+
+---
+AI Visibility Tracker
+`, brand.Name, currentScore, forecast.AlertThreshold, forecast.SlopePerWeek, len(forecast.History),
+		crossing.WeekStart.Format("Jan 2, 2006"), crossing.VisibilityScore, *crossing.Low, *crossing.High)
+
+	return e.sendEmail(toEmail, subject, body)
+}
+
+// describeScoreChange explains the change since the previous snapshot for an alert email
+func describeScoreChange(change *models.ScoreChange) string {
+	if change == nil {
+		return ""
+	}
+	line := fmt.Sprintf("Change Since Previous Run: %+.1f (%.1f -> %.1f)\n", change.Delta, change.FromScore, change.ToScore)
+	switch change.Verdict {
+	case models.ChangeNotSignificant:
+		line += fmt.Sprintf("This change is not statistically significant (p=%.2f, %d vs %d responses) and may be noise.\n",
+			change.PValue, change.FromResponses, change.ToResponses)
+	case models.ChangeInsufficientData:
+		line += "Not enough responses to tell whether this change is significant.\n"
+	default:
+		line += fmt.Sprintf("This change is statistically significant (p=%.3f).\n", change.PValue)
+	}
+	return line
+}
+
 // sendEmail sends a generic email
 func (e *EmailService) sendEmail(to, subject, body string) error {
 	from := e.fromEmail
@@ -124,8 +177,12 @@ func (e *EmailService) sendEmail(to, subject, body string) error {
 	return nil
 }
 
-// CheckAndSendAlerts checks all brands and sends alerts if needed
-func (e *EmailService) CheckAndSendAlerts() {
+// alertCooldown is how long an unchanged alert condition stays quiet before it is emailed again
+const alertCooldown = 7 * 24 * time.Hour
+
+// CheckAndSendAlerts checks all brands and sends alerts if needed. Brands skip reports true
+// for (e.g. ones with an analysis in progress) are left for a later check.
+func (e *EmailService) CheckAndSendAlerts(skip func(brandID int) bool) {
 	if !e.enabled {
 		return
 	}
@@ -137,29 +194,98 @@ func (e *EmailService) CheckAndSendAlerts() {
 		return
 	}
 
-	metricRepo := db.NewMetricRepository()
-
 	for _, brand := range brands {
-		// Check if brand has alert threshold set
-		if brand.AlertThreshold <= 0 {
+		if skip != nil && skip(brand.ID) {
 			continue
 		}
+		e.CheckBrandAlerts(brand)
+	}
+}
 
-		// Get latest metrics
-		latest, err := metricRepo.GetLatestByBrandID(brand.ID)
-		if err != nil {
-			continue
+// CheckBrandAlerts emails a brand's owner when its score is below the alert threshold or its
+// forecast crosses it. The condition is recorded on the brand, so an email only goes out when
+// it changes or the last one is older than alertCooldown; concurrent checks claim it atomically.
+func (e *EmailService) CheckBrandAlerts(brand models.Brand) {
+	if !e.enabled || brand.AlertThreshold <= 0 {
+		return
+	}
+
+	// Get latest metrics
+	metricRepo := db.NewMetricRepository()
+	latest, err := metricRepo.GetLatestByBrandID(brand.ID)
+	if err != nil {
+		return
+	}
+
+	state := models.AlertStateOK
+	var forecast *models.VisibilityForecast
+	if latest.VisibilityScore < brand.AlertThreshold {
+		state = models.AlertStateBelowThreshold
+	} else if brand.ForecastAlertWeeks > 0 {
+		// Otherwise check whether the forecast crosses the threshold
+		f, err := NewMetricsCalculator().ForecastVisibility(brand.ID, brand.ForecastAlertWeeks, DefaultForecastHistoryWeeks)
+		if err == nil && f.CrossesThresholdAt != nil {
+			state, forecast = models.AlertStateForecastCrossing, f
 		}
+	}
 
-		// Check if score is below threshold
-		if latest.VisibilityScore < brand.AlertThreshold {
-			// Get user email (from brand owner)
-			userEmail := getAlertEmail(brand.UserID)
-			if userEmail != "" {
-				e.SendAlert(userEmail, &brand, latest.VisibilityScore, brand.AlertThreshold)
+	brandRepo := db.NewBrandRepository()
+	if state == models.AlertStateOK {
+		// Cleared: the next time the condition holds is a change worth an email
+		if brand.LastAlertState != "" && brand.LastAlertState != models.AlertStateOK {
+			if _, err := brandRepo.ClaimAlertState(brand, models.AlertStateOK, nil); err != nil {
+				log.Printf("Error clearing alert state for brand %d: %v", brand.ID, err)
 			}
 		}
+		return
+	}
+
+	// Whole seconds, as stored, so the claim can be matched when it is released
+	now := time.Now().Truncate(time.Second)
+	if !alertDue(brand, state, now) {
+		return
+	}
+	// Get user email (from brand owner)
+	userEmail := getAlertEmail(brand.UserID)
+	if userEmail == "" {
+		return
+	}
+	claimed, err := brandRepo.ClaimAlertState(brand, state, &now)
+	if err != nil {
+		log.Printf("Error claiming alert for brand %d: %v", brand.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	if state == models.AlertStateBelowThreshold {
+		var change *models.ScoreChange
+		if recent, err := metricRepo.GetTrendsByBrandID(brand.ID, 2); err == nil && len(recent) == 2 {
+			change = snapshotChange(loadScoringProfile(brand.ID), &recent[1], &recent[0])
+		}
+		err = e.SendAlert(userEmail, &brand, latest.VisibilityScore, brand.AlertThreshold, change)
+	} else {
+		err = e.SendForecastAlert(userEmail, &brand, latest.VisibilityScore, forecast)
+	}
+	if err != nil {
+		// Put the previous state back so the next check retries
+		if err := brandRepo.ReleaseAlertState(brand, state, now); err != nil {
+			log.Printf("Error restoring alert state for brand %d: %v", brand.ID, err)
+		}
+	}
+}
+
+// alertDue reports whether a brand in an alert state should be emailed now: the state differs
+// from the one last emailed, or that email is at least alertCooldown old
+func alertDue(brand models.Brand, state string, now time.Time) bool {
+	if state == models.AlertStateOK {
+		return false
+	}
+	if state != brand.LastAlertState || brand.LastAlertSentAt == nil {
+		return true
 	}
+	return now.Sub(*brand.LastAlertSentAt) >= alertCooldown
 }
 
 // getAlertEmail gets the email for a user
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestAlertDue(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	sentAt := func(ago time.Duration) *time.Time {
+		at := now.Add(-ago)
+		return &at
+	}
+
+	tests := []struct {
+		name  string
+		brand models.Brand
+		state string
+		want  bool
+	}{
+		{"never alerted", models.Brand{}, models.AlertStateBelowThreshold, true},
+		{"ok is never emailed", models.Brand{}, models.AlertStateOK, false},
+		{"same state within cooldown", models.Brand{LastAlertState: models.AlertStateBelowThreshold, LastAlertSentAt: sentAt(time.Hour)}, models.AlertStateBelowThreshold, false},
+		{"same state after cooldown", models.Brand{LastAlertState: models.AlertStateBelowThreshold, LastAlertSentAt: sentAt(alertCooldown)}, models.AlertStateBelowThreshold, true},
+		{"state changed within cooldown", models.Brand{LastAlertState: models.AlertStateForecastCrossing, LastAlertSentAt: sentAt(time.Hour)}, models.AlertStateBelowThreshold, true},
+		{"cleared then back", models.Brand{LastAlertState: models.AlertStateOK, LastAlertSentAt: sentAt(time.Hour)}, models.AlertStateBelowThreshold, true},
+		{"same state never sent", models.Brand{LastAlertState: models.AlertStateForecastCrossing}, models.AlertStateForecastCrossing, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alertDue(tt.brand, tt.state, now); got != tt.want {
+				t.Errorf("alertDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
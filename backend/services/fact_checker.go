@@ -0,0 +1,630 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// Fact types a brand's fact sheet can hold
+const (
+	FactTypePricing      = "pricing"
+	FactTypeFeature      = "feature"
+	FactTypeIntegration  = "integration"
+	FactTypeHeadquarters = "headquarters"
+	FactTypeFounded      = "founded"
+)
+
+// Misinformation severities
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Detection methods recorded on findings
+const (
+	DetectionRules = "rules"
+	DetectionLLM   = "llm"
+)
+
+// IsValidFactType reports whether a fact type is supported
+func IsValidFactType(factType string) bool {
+	switch factType {
+	case FactTypePricing, FactTypeFeature, FactTypeIntegration, FactTypeHeadquarters, FactTypeFounded:
+		return true
+	}
+	return false
+}
+
+var (
+	pricePattern        = regexp.MustCompile(`\$\s?(\d{1,3}(?:,\d{3})*(?:\.\d{1,2})?|\d+(?:\.\d{1,2})?)`)
+	yearPattern         = regexp.MustCompile(`\b(1[89]\d{2}|20\d{2})\b`)
+	foundedPattern      = regexp.MustCompile(`(?i)\b(?:founded|established|launched|started)\s+(?:in\s+)?(1[89]\d{2}|20\d{2})\b`)
+	headquartersPattern = regexp.MustCompile(`\b(?:[Hh]eadquartered|[Hh]eadquarters (?:is|are) located|[Bb]ased|HQ(?: is)?)\s+(?:in|out of)\s+([A-Z][\w.'-]*(?:,? [A-Z][\w.'-]*){0,3})`)
+)
+
+// Phrases that deny a capability directly before or directly after its name
+var (
+	negationBefore = []string{"no", "not", "doesn't", "does not", "don't", "lacks", "lacking", "missing", "without", "isn't", "doesn't offer", "does not offer"}
+	negationAfter  = []string{"not supported", "isn't supported", "is not supported", "not available", "isn't available", "is not available", "unavailable", "is missing"}
+	freePlanWords  = []string{"free plan", "free tier", "free version", "freemium", "free forever"}
+
+	// negationFillers may sit between a denial and the capability: "lacks native SSO",
+	// "no support for SSO", "SSO support is missing"
+	negationFillers = map[string]bool{"a": true, "an": true, "any": true, "the": true, "native": true, "built-in": true, "for": true, "support": true, "integration": true, "integrations": true}
+)
+
+// priceCues mark a dollar amount as a price rather than savings, funding or revenue
+var (
+	priceCuesBefore = []string{"cost", "costs", "priced", "price", "pricing", "starts at", "starting at", "plan", "tier", "subscription", "pay"}
+	priceCuesAfter  = []string{"/mo", "/month", "per month", "a month", "/user", "per user", "per seat", "/seat", "/year", "per year"}
+)
+
+// negationWindow is how many characters before/after a capability are checked for a denial
+const negationWindow = 40
+
+// DetectedFinding is a contradiction found in a response before storage
+type DetectedFinding struct {
+	FactID   int
+	FactType string
+	Claim    string
+	Expected string
+	Severity string
+	Snippet  string
+	Method   string
+}
+
+// FactChecker compares claims made near brand mentions with the brand's verified facts
+type FactChecker struct {
+	facts []models.BrandFact
+	judge *MentionClassifier // Optional LLM verifier, shares the classifier provider
+}
+
+// NewFactChecker creates a fact checker for one brand's fact sheet
+func NewFactChecker(facts []models.BrandFact) *FactChecker {
+	return &FactChecker{facts: facts, judge: GetMentionClassifier()}
+}
+
+// CheckResponse returns every contradiction between a response and the fact sheet.
+// Rules always run; the LLM verifier adds findings the rules cannot express when available.
+func (f *FactChecker) CheckResponse(ctx context.Context, text string, mentions []DetectedMention) ([]DetectedFinding, error) {
+	if len(f.facts) == 0 {
+		return nil, nil
+	}
+
+	claims := f.brandClaims(text, mentions)
+	var findings []DetectedFinding
+	for _, sentence := range claims {
+		findings = append(findings, f.checkPricing(sentence)...)
+		findings = append(findings, f.checkCapabilities(sentence)...)
+		findings = append(findings, f.checkFounded(sentence)...)
+		findings = append(findings, f.checkHeadquarters(sentence)...)
+	}
+
+	if f.judge.IsAvailable() && len(claims) > 0 {
+		llmFindings, err := f.verifyWithLLM(ctx, text, mentions, claims)
+		if err != nil {
+			return findings, err
+		}
+		for _, lf := range llmFindings {
+			if !duplicateFinding(lf, findings) {
+				findings = append(findings, lf)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// StoreFindings saves the findings for a response
+func (f *FactChecker) StoreFindings(aiResponse *models.AIResponse, findings []DetectedFinding) error {
+	repo := db.NewFactRepository()
+	for _, finding := range findings {
+		err := repo.CreateFinding(&models.MisinformationFinding{
+			BrandID:         aiResponse.BrandID,
+			AIResponseID:    aiResponse.ID,
+			FactID:          finding.FactID,
+			FactType:        finding.FactType,
+			Claim:           finding.Claim,
+			Expected:        finding.Expected,
+			Severity:        finding.Severity,
+			Snippet:         finding.Snippet,
+			ModelName:       aiResponse.ModelName,
+			DetectionMethod: finding.Method,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// brandClaims returns the sentences that make claims about the brand: sentences with a
+// brand mention plus the whole list item the brand (or one of its products) leads. Sentences that also name a
+// competitor are skipped because their claims cannot be attributed reliably.
+func (f *FactChecker) brandClaims(text string, mentions []DetectedMention) []string {
+	var spans [][]int
+	lists := parseRankedLists(text)
+	for _, m := range mentions {
+		if m.EntityType != "brand" {
+			continue
+		}
+		start, end := sentenceBounds(text, m.Position, len(m.EntityName))
+		spans = append(spans, []int{start, end})
+
+		for _, list := range lists {
+			for _, item := range list.Items {
+				if subject, ok := itemSubject(item, mentions); ok && strings.HasPrefix(subject, "brand") && m.Position >= item.Start && m.Position < item.End {
+					spans = append(spans, f.sentencesIn(text, item.Start, item.End)...)
+				}
+			}
+		}
+	}
+
+	var sentences []string
+	seen := map[int]bool{}
+	for _, span := range spans {
+		if seen[span[0]] {
+			continue
+		}
+		seen[span[0]] = true
+		if mentionsCompetitor(span, mentions) {
+			continue
+		}
+		if sentence := strings.TrimSpace(text[span[0]:span[1]]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+// sentencesIn splits text[start:end] into sentence spans
+func (f *FactChecker) sentencesIn(text string, start, end int) [][]int {
+	var spans [][]int
+	sentenceStart := start
+	for i := start; i < end; i++ {
+		if isSentenceEnd(text, i) {
+			spans = append(spans, []int{sentenceStart, i + 1})
+			sentenceStart = i + 1
+		}
+	}
+	if sentenceStart < end {
+		spans = append(spans, []int{sentenceStart, end})
+	}
+	return spans
+}
+
+// mentionsCompetitor reports whether a competitor is mentioned inside a span
+func mentionsCompetitor(span []int, mentions []DetectedMention) bool {
+	for _, m := range mentions {
+		if m.EntityType == "competitor" && m.Position >= span[0] && m.Position < span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPricing flags prices that differ from the named tier or match no known tier,
+// and free plan claims the fact sheet does not back up
+func (f *FactChecker) checkPricing(sentence string) []DetectedFinding {
+	tiers := f.factsOfType(FactTypePricing)
+	if len(tiers) == 0 {
+		return nil
+	}
+	var findings []DetectedFinding
+	lower := strings.ToLower(sentence)
+	d := &MentionDetector{}
+
+	// Matched in the lowercased sentence: lowercasing can change its length in bytes, so
+	// offsets into sentence would not line up with the price cues
+	for _, idx := range pricePattern.FindAllStringSubmatchIndex(lower, -1) {
+		match := []string{lower[idx[0]:idx[1]], lower[idx[2]:idx[3]]}
+		claimed, ok := parseAmount(match[1])
+		if !ok || !isPriceClaim(lower, idx[0], idx[1]) {
+			continue
+		}
+
+		// A named tier must carry its own price
+		if tier := namedTier(lower, tiers, d); tier != nil {
+			if expected, ok := parsePrice(tier.Value); ok && !samePrice(claimed, expected) {
+				findings = append(findings, DetectedFinding{
+					FactID:   tier.ID,
+					FactType: FactTypePricing,
+					Claim:    fmt.Sprintf("%s tier costs %s", tier.Label, match[0]),
+					Expected: fmt.Sprintf("%s: %s", tier.Label, tier.Value),
+					Severity: SeverityHigh,
+					Snippet:  sentence,
+					Method:   DetectionRules,
+				})
+			}
+			continue
+		}
+
+		// Otherwise the price must match some tier
+		matched := false
+		var known []string
+		for _, tier := range tiers {
+			known = append(known, tierDescription(tier))
+			if expected, ok := parsePrice(tier.Value); ok && samePrice(claimed, expected) {
+				matched = true
+			}
+		}
+		if !matched {
+			findings = append(findings, DetectedFinding{
+				FactType: FactTypePricing,
+				Claim:    fmt.Sprintf("Price of %s", match[0]),
+				Expected: strings.Join(known, "; "),
+				Severity: SeverityMedium,
+				Snippet:  sentence,
+				Method:   DetectionRules,
+			})
+		}
+	}
+
+	if d.containsKeyword(lower, freePlanWords) {
+		var freeTier *models.BrandFact
+		for i := range tiers {
+			if amount, ok := parsePrice(tiers[i].Value); ok && amount == 0 {
+				freeTier = &tiers[i]
+			}
+		}
+		denied := d.containsKeyword(lower, []string{"no free", "not free", "doesn't offer a free", "does not offer a free", "lacks a free", "without a free"})
+		switch {
+		case freeTier == nil && !denied:
+			findings = append(findings, DetectedFinding{
+				FactType: FactTypePricing,
+				Claim:    "Offers a free plan",
+				Expected: "No free plan",
+				Severity: SeverityMedium,
+				Snippet:  sentence,
+				Method:   DetectionRules,
+			})
+		case freeTier != nil && denied:
+			findings = append(findings, DetectedFinding{
+				FactID:   freeTier.ID,
+				FactType: FactTypePricing,
+				Claim:    "Has no free plan",
+				Expected: tierDescription(*freeTier),
+				Severity: SeverityMedium,
+				Snippet:  sentence,
+				Method:   DetectionRules,
+			})
+		}
+	}
+	return findings
+}
+
+// checkCapabilities flags sentences that deny a feature or integration the brand has
+func (f *FactChecker) checkCapabilities(sentence string) []DetectedFinding {
+	var findings []DetectedFinding
+	lower := strings.ToLower(sentence)
+
+	for _, fact := range f.facts {
+		if fact.FactType != FactTypeFeature && fact.FactType != FactTypeIntegration {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(fact.Value))
+		if name == "" {
+			continue
+		}
+		for _, pos := range wordPositions(lower, name) {
+			before := lower[max(0, pos-negationWindow):pos]
+			after := lower[pos+len(name) : min(len(lower), pos+len(name)+negationWindow)]
+			if !deniedBefore(before) && !deniedAfter(after) {
+				continue
+			}
+			findings = append(findings, DetectedFinding{
+				FactID:   fact.ID,
+				FactType: fact.FactType,
+				Claim:    fmt.Sprintf("Does not support %s", fact.Value),
+				Expected: fmt.Sprintf("Supports %s", fact.Value),
+				Severity: SeverityHigh,
+				Snippet:  sentence,
+				Method:   DetectionRules,
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// deniedBefore reports whether the text right before a capability ends with a denial,
+// so "not only supports SSO" or "no doubt, SSO works" are not read as denials
+func deniedBefore(before string) bool {
+	words := strings.Fields(before)
+	for len(words) > 0 && negationFillers[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	text := strings.Join(words, " ")
+	for _, phrase := range negationBefore {
+		if text == phrase || strings.HasSuffix(text, " "+phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// deniedAfter reports whether the text right after a capability starts with a denial
+func deniedAfter(after string) bool {
+	words := strings.Fields(after)
+	for len(words) > 0 && negationFillers[words[0]] {
+		words = words[1:]
+	}
+	text := strings.Join(words, " ")
+	for _, phrase := range negationAfter {
+		if text == phrase || strings.HasPrefix(text, phrase+" ") || strings.HasPrefix(text, phrase+".") || strings.HasPrefix(text, phrase+",") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFounded flags a founding year that differs from the fact sheet
+func (f *FactChecker) checkFounded(sentence string) []DetectedFinding {
+	facts := f.factsOfType(FactTypeFounded)
+	if len(facts) == 0 {
+		return nil
+	}
+	expected := yearPattern.FindString(facts[0].Value)
+
+	var findings []DetectedFinding
+	for _, match := range foundedPattern.FindAllStringSubmatch(sentence, -1) {
+		if expected == "" || match[1] == expected {
+			continue
+		}
+		findings = append(findings, DetectedFinding{
+			FactID:   facts[0].ID,
+			FactType: FactTypeFounded,
+			Claim:    fmt.Sprintf("Founded in %s", match[1]),
+			Expected: fmt.Sprintf("Founded in %s", expected),
+			Severity: SeverityLow,
+			Snippet:  sentence,
+			Method:   DetectionRules,
+		})
+	}
+	return findings
+}
+
+// checkHeadquarters flags a headquarters location that differs from the fact sheet
+func (f *FactChecker) checkHeadquarters(sentence string) []DetectedFinding {
+	facts := f.factsOfType(FactTypeHeadquarters)
+	if len(facts) == 0 {
+		return nil
+	}
+
+	var findings []DetectedFinding
+	for _, match := range headquartersPattern.FindAllStringSubmatch(sentence, -1) {
+		claimed := strings.TrimRight(match[1], ".,")
+		if sameLocation(claimed, facts[0].Value) {
+			continue
+		}
+		findings = append(findings, DetectedFinding{
+			FactID:   facts[0].ID,
+			FactType: FactTypeHeadquarters,
+			Claim:    fmt.Sprintf("Headquartered in %s", claimed),
+			Expected: fmt.Sprintf("Headquartered in %s", facts[0].Value),
+			Severity: SeverityLow,
+			Snippet:  sentence,
+			Method:   DetectionRules,
+		})
+	}
+	return findings
+}
+
+// isPriceClaim reports whether the amount at lowerSentence[start:end] reads as what the product
+// costs: "$49/month" or "priced at $49", not "saves $10,000" or "raised $20 million"
+func isPriceClaim(lowerSentence string, start, end int) bool {
+	d := &MentionDetector{}
+	end = min(max(end, 0), len(lowerSentence))
+	start = min(max(start, 0), end)
+	after := lowerSentence[end:min(len(lowerSentence), end+negationWindow/2)]
+	for _, cue := range priceCuesAfter {
+		if strings.HasPrefix(strings.TrimLeft(after, " "), cue) {
+			return true
+		}
+	}
+	before := lowerSentence[max(0, start-negationWindow):start]
+	return d.containsKeyword(before, priceCuesBefore)
+}
+
+// factsOfType returns the facts of one type
+func (f *FactChecker) factsOfType(factType string) []models.BrandFact {
+	var facts []models.BrandFact
+	for _, fact := range f.facts {
+		if fact.FactType == factType {
+			facts = append(facts, fact)
+		}
+	}
+	return facts
+}
+
+// namedTier returns the pricing tier whose label appears in the sentence, if exactly one does
+func namedTier(lowerSentence string, tiers []models.BrandFact, d *MentionDetector) *models.BrandFact {
+	var found *models.BrandFact
+	for i := range tiers {
+		label := strings.ToLower(strings.TrimSpace(tiers[i].Label))
+		if label == "" || !d.containsKeyword(lowerSentence, []string{label}) {
+			continue
+		}
+		if found != nil {
+			return nil // Several tiers named, cannot tell which price belongs to which
+		}
+		found = &tiers[i]
+	}
+	return found
+}
+
+// tierDescription formats a pricing fact as "Pro: $49/month"
+func tierDescription(fact models.BrandFact) string {
+	if fact.Label == "" {
+		return fact.Value
+	}
+	return fact.Label + ": " + fact.Value
+}
+
+// parsePrice reads the amount of a pricing fact value ("$49/month", "Free")
+func parsePrice(value string) (float64, bool) {
+	if match := pricePattern.FindStringSubmatch(value); match != nil {
+		return parseAmount(match[1])
+	}
+	if strings.Contains(strings.ToLower(value), "free") {
+		return 0, true
+	}
+	return 0, false
+}
+
+// parseAmount parses "1,299.00" into a number
+func parseAmount(raw string) (float64, bool) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+	return amount, err == nil
+}
+
+// samePrice treats monthly and annual figures of the same plan as equal
+func samePrice(claimed, expected float64) bool {
+	const tolerance = 0.01
+	return math.Abs(claimed-expected) < tolerance ||
+		math.Abs(claimed*12-expected) < tolerance ||
+		math.Abs(claimed-expected*12) < tolerance
+}
+
+// sameLocation compares the city part of two locations ("Boston" vs "Boston, MA")
+func sameLocation(claimed, expected string) bool {
+	city := func(s string) string {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if i := strings.Index(s, ","); i != -1 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(s)
+	}
+	c, e := city(claimed), city(expected)
+	return c != "" && e != "" && (strings.Contains(c, e) || strings.Contains(e, c))
+}
+
+// llmFinding is one contradiction in the verifier's strict JSON reply
+type llmFinding struct {
+	FactType string `json:"fact_type"`
+	Claim    string `json:"claim"`
+	Expected string `json:"expected"`
+	Severity string `json:"severity"`
+}
+
+// verifyWithLLM asks the judge model to compare the brand claims with the fact sheet
+func (f *FactChecker) verifyWithLLM(ctx context.Context, text string, mentions []DetectedMention, claims []string) ([]DetectedFinding, error) {
+	brandName := ""
+	for _, m := range mentions {
+		if m.EntityType == "brand" {
+			brandName = m.EntityName
+			break
+		}
+	}
+
+	var sheet strings.Builder
+	for _, fact := range f.facts {
+		if fact.Label != "" {
+			fmt.Fprintf(&sheet, "- %s (%s): %s\n", fact.FactType, fact.Label, fact.Value)
+		} else {
+			fmt.Fprintf(&sheet, "- %s: %s\n", fact.FactType, fact.Value)
+		}
+	}
+
+	prompt := fmt.Sprintf(`You are a strict fact checker. Compare claims about "%s" with its verified fact sheet.
+
+Verified facts:
+%s
+Claims:
+"""
+%s
+"""
+
+Respond with ONLY a JSON array, no prose and no code fences. Each element reports one claim that CONTRADICTS a verified fact and matches exactly this schema:
+{"fact_type": "pricing" | "feature" | "integration" | "headquarters" | "founded", "claim": string (the contradicting claim, quoted from the text), "expected": string (the verified fact), "severity": "low" | "medium" | "high"}
+
+Claims the fact sheet does not cover are not contradictions. Respond with [] when nothing contradicts the facts.`,
+		brandName, sheet.String(), strings.Join(claims, "\n"))
+
+	raw, err := f.judge.provider.Query(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseFactVerdicts(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []DetectedFinding
+	for _, v := range parsed {
+		snippet := v.Claim
+		if pos := strings.Index(text, v.Claim); pos != -1 && v.Claim != "" {
+			snippet = strings.TrimSpace(sentenceAround(text, pos, len(v.Claim)))
+		}
+		findings = append(findings, DetectedFinding{
+			FactID:   f.factIDFor(v.FactType, v.Expected),
+			FactType: v.FactType,
+			Claim:    v.Claim,
+			Expected: v.Expected,
+			Severity: v.Severity,
+			Snippet:  snippet,
+			Method:   DetectionLLM,
+		})
+	}
+	return findings, nil
+}
+
+// parseFactVerdicts decodes and validates the verifier's JSON array
+func parseFactVerdicts(raw string) ([]llmFinding, error) {
+	start := strings.Index(raw, "[")
+	end := strings.LastIndex(raw, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("fact verifier returned no JSON array")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw[start : end+1]))
+	decoder.DisallowUnknownFields()
+
+	var verdicts []llmFinding
+	if err := decoder.Decode(&verdicts); err != nil {
+		return nil, fmt.Errorf("fact verifier returned invalid JSON: %w", err)
+	}
+
+	for i := range verdicts {
+		verdicts[i].FactType = strings.ToLower(strings.TrimSpace(verdicts[i].FactType))
+		verdicts[i].Severity = strings.ToLower(strings.TrimSpace(verdicts[i].Severity))
+		if !IsValidFactType(verdicts[i].FactType) {
+			return nil, fmt.Errorf("fact verifier returned unknown fact type %q", verdicts[i].FactType)
+		}
+		switch verdicts[i].Severity {
+		case SeverityLow, SeverityMedium, SeverityHigh:
+		default:
+			return nil, fmt.Errorf("fact verifier returned unknown severity %q", verdicts[i].Severity)
+		}
+	}
+	return verdicts, nil
+}
+
+// factIDFor finds the fact an LLM finding refers to, or 0
+func (f *FactChecker) factIDFor(factType, expected string) int {
+	lower := strings.ToLower(expected)
+	for _, fact := range f.facts {
+		if fact.FactType == factType && strings.Contains(lower, strings.ToLower(fact.Value)) {
+			return fact.ID
+		}
+	}
+	return 0
+}
+
+// duplicateFinding reports whether the rules already flagged the same claim
+func duplicateFinding(candidate DetectedFinding, findings []DetectedFinding) bool {
+	for _, existing := range findings {
+		if existing.FactType == candidate.FactType && strings.Contains(candidate.Snippet, existing.Snippet) {
+			return true
+		}
+	}
+	return false
+}
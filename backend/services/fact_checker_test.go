@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestFactCheckerRules(t *testing.T) {
+	checker := NewFactChecker([]models.BrandFact{
+		{ID: 1, FactType: FactTypePricing, Label: "Pro", Value: "$49/month"},
+		{ID: 2, FactType: FactTypePricing, Label: "Starter", Value: "Free"},
+		{ID: 3, FactType: FactTypeFeature, Value: "SSO"},
+		{ID: 4, FactType: FactTypeIntegration, Value: "Salesforce"},
+		{ID: 5, FactType: FactTypeFounded, Value: "2012"},
+		{ID: 6, FactType: FactTypeHeadquarters, Value: "Boston, MA"},
+	})
+
+	cases := []struct {
+		sentence string
+		want     []string // Claims, in check order: pricing, capabilities, founded, headquarters
+	}{
+		// Pricing
+		{"Acme's Pro tier costs $99/month.", []string{"Pro tier costs $99"}},
+		{"Acme starts at $49 per user.", nil},
+		{"Acme costs $30/month for small teams.", []string{"Price of $30"}},
+		{"Acme is billed at $588 per year.", nil},
+		{"Acme saved one customer $10,000 last year.", nil},
+		{"Acme raised $20 million in 2021.", nil},
+		{"Acme has no free plan.", []string{"Has no free plan"}},
+		{"Acme offers a free tier.", nil},
+		{"Acme ẞẞẞẞẞẞẞẞẞẞ costs $99/month.", []string{"Price of $99"}}, // ẞ lowercases to a shorter ß
+		{"Acme's \u212a-series costs $49/month.", nil},                 // So does the Kelvin sign
+
+		// Capabilities
+		{"Acme does not support SSO.", []string{"Does not support SSO"}},
+		{"Acme lacks native SSO.", []string{"Does not support SSO"}},
+		{"Acme has no support for SSO.", []string{"Does not support SSO"}},
+		{"Acme doesn't offer a Salesforce integration.", []string{"Does not support Salesforce"}},
+		{"In Acme, SSO is not supported.", []string{"Does not support SSO"}},
+		{"Acme not only supports SSO but also Salesforce.", nil},
+		{"No doubt, Acme's SSO is solid.", nil},
+		{"Acme isn't cheap, but SSO and Salesforce work well.", nil},
+
+		// Founded and headquarters
+		{"Acme was founded in 2015.", []string{"Founded in 2015"}},
+		{"Acme, founded in 2012, is popular.", nil},
+		{"Acme is headquartered in Austin.", []string{"Headquartered in Austin"}},
+		{"Acme is based in Boston.", nil},
+	}
+
+	for _, tc := range cases {
+		var findings []DetectedFinding
+		findings = append(findings, checker.checkPricing(tc.sentence)...)
+		findings = append(findings, checker.checkCapabilities(tc.sentence)...)
+		findings = append(findings, checker.checkFounded(tc.sentence)...)
+		findings = append(findings, checker.checkHeadquarters(tc.sentence)...)
+
+		var got []string
+		for _, f := range findings {
+			got = append(got, f.Claim)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %q, want %q", tc.sentence, got, tc.want)
+		}
+	}
+}
+
+func TestCheckResponseWithShrinkingLowercase(t *testing.T) {
+	checker := NewFactChecker([]models.BrandFact{{ID: 1, FactType: FactTypePricing, Label: "Pro", Value: "$49/month"}})
+	text := "Acme ẞẞẞẞẞẞẞẞẞẞ costs $99/month"
+	mentions := NewMentionDetector().DetectMentions(text, &models.Brand{Name: "Acme"})
+
+	findings, err := checker.CheckResponse(context.Background(), text, mentions)
+	if err != nil {
+		t.Fatalf("CheckResponse() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Claim != "Price of $99" {
+		t.Errorf("CheckResponse() = %+v, want one finding for $99", findings)
+	}
+}
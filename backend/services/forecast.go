@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// Forecast horizons and history windows, in weeks
+const (
+	DefaultForecastWeeks        = 4
+	MaxForecastWeeks            = 12
+	DefaultForecastHistoryWeeks = 12
+	MaxForecastHistoryWeeks     = 104
+)
+
+// ErrInvalidForecast is returned for a horizon or history window out of range
+var ErrInvalidForecast = errors.New("invalid forecast")
+
+// ForecastVisibility fits a linear trend to the brand's weekly average score over the last
+// historyWeeks weeks and projects it weeksAhead weeks, with 95% prediction intervals clamped
+// to 0-100. It returns scoring.ErrInsufficientHistory with fewer than three weeks of runs.
+func (m *MetricsCalculator) ForecastVisibility(brandID, weeksAhead, historyWeeks int) (*models.VisibilityForecast, error) {
+	if weeksAhead < 1 || weeksAhead > MaxForecastWeeks {
+		return nil, fmt.Errorf("%w: weeks must be between 1 and %d", ErrInvalidForecast, MaxForecastWeeks)
+	}
+	if historyWeeks < scoring.MinForecastPoints || historyWeeks > MaxForecastHistoryWeeks {
+		return nil, fmt.Errorf("%w: history must be between %d and %d weeks", ErrInvalidForecast, scoring.MinForecastPoints, MaxForecastHistoryWeeks)
+	}
+
+	brand, err := db.NewBrandRepository().GetByID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := NewMetricsQuery(historyWeeks * 7)
+	q.Granularity = models.GranularityWeek
+	q.Aggregation = models.AggregationAvg
+	weeks, err := m.QueryMetrics(brandID, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(weeks) == 0 {
+		return nil, scoring.ErrInsufficientHistory
+	}
+
+	// x is weeks since the first week, so gaps without runs keep their width
+	first := weeks[0].PeriodStart
+	weeksSince := func(t time.Time) float64 {
+		return t.Sub(first).Hours() / (24 * 7)
+	}
+	forecast := &models.VisibilityForecast{
+		BrandID:        brandID,
+		Method:         "linear_trend",
+		History:        make([]models.ForecastPoint, len(weeks)),
+		Forecast:       []models.ForecastPoint{},
+		AlertThreshold: brand.AlertThreshold,
+	}
+	xs := make([]float64, len(weeks))
+	ys := make([]float64, len(weeks))
+	for i, w := range weeks {
+		xs[i], ys[i] = weeksSince(w.PeriodStart), w.VisibilityScore
+		forecast.History[i] = models.ForecastPoint{WeekStart: w.PeriodStart, VisibilityScore: w.VisibilityScore}
+	}
+
+	trend, err := scoring.FitTrend(xs, ys)
+	if err != nil {
+		return nil, err
+	}
+	forecast.SlopePerWeek = trend.Slope
+
+	// The horizon starts after the current week, not the last week with runs, so a brand
+	// that has not run lately is not forecast for weeks already gone
+	current := periodStart(time.Now(), models.GranularityWeek)
+	for k := 1; k <= weeksAhead; k++ {
+		week := current.AddDate(0, 0, 7*k)
+		x := weeksSince(week)
+		low, high := trend.PredictionInterval(x)
+		low, high = clampScore(low), clampScore(high)
+		point := models.ForecastPoint{
+			WeekStart:       week,
+			VisibilityScore: clampScore(trend.Predict(x)),
+			Low:             &low,
+			High:            &high,
+		}
+		forecast.Forecast = append(forecast.Forecast, point)
+
+		if brand.AlertThreshold > 0 && forecast.CrossesThresholdAt == nil && point.VisibilityScore < brand.AlertThreshold {
+			forecast.CrossesThresholdAt = &point.WeekStart
+			forecast.CrossesThresholdWeeks = k
+		}
+	}
+	return forecast, nil
+}
+
+// clampScore keeps a projected score within the 0-100 score range
+func clampScore(score float64) float64 {
+	return math.Max(0, math.Min(100, score))
+}
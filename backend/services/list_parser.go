@@ -0,0 +1,307 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ListItem is one entry of a ranked list found in a response (byte offsets into the text)
+type ListItem struct {
+	Start int
+	End   int
+}
+
+// RankedList is a sequence of sibling items: a markdown list, a run of same-level
+// headings, or the body rows of a table
+type RankedList struct {
+	Kind  string // "list", "heading", "table"
+	Items []ListItem
+}
+
+// textLine is a single line of the response with its byte offsets
+type textLine struct {
+	start int
+	end   int // exclusive, excludes the newline
+	text  string
+}
+
+// splitLines splits text into lines while keeping their offsets
+func splitLines(text string) []textLine {
+	var lines []textLine
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i == len(text) || text[i] == '\n' {
+			lines = append(lines, textLine{start: start, end: i, text: text[start:i]})
+			start = i + 1
+		}
+	}
+	return lines
+}
+
+// parseRankedLists finds every markdown list, heading run and table in the text
+func parseRankedLists(text string) []RankedList {
+	lines := splitLines(text)
+	var lists []RankedList
+	lists = append(lists, parseMarkdownLists(lines, len(text))...)
+	lists = append(lists, parseHeadingLists(lines, len(text))...)
+	lists = append(lists, parseTableLists(lines)...)
+	return lists
+}
+
+// listMarker reports whether a line is a numbered or bulleted list item and its indent.
+// Bold wrappers such as "**1. HubSpot**" are tolerated.
+func listMarker(line string) (indent int, ok bool) {
+	raw := strings.TrimLeft(line, " \t")
+	indent = len(line) - len(raw)
+
+	if strings.HasPrefix(raw, "**") || strings.HasPrefix(raw, "__") {
+		// Bold prefix: only a numbered marker counts, "**Note**" is not a list item
+		return indent, isNumberedMarker(strings.TrimLeft(raw, "*_"))
+	}
+	if strings.HasPrefix(raw, "- ") || strings.HasPrefix(raw, "* ") || strings.HasPrefix(raw, "+ ") || strings.HasPrefix(raw, "• ") {
+		return indent, true
+	}
+	return indent, isNumberedMarker(raw)
+}
+
+// isNumberedMarker matches "1. ", "12) " and similar
+func isNumberedMarker(s string) bool {
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || digits > 3 || digits+1 >= len(s) {
+		return false
+	}
+	return (s[digits] == '.' || s[digits] == ')') && (s[digits+1] == ' ' || s[digits+1] == '*')
+}
+
+// headingLevel returns the markdown heading level of a line, or 0
+func headingLevel(line string) int {
+	trimmed := strings.TrimLeft(line, " ")
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level >= len(trimmed) || trimmed[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// isTableRow reports whether a line belongs to a pipe table
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+// isTableSeparator matches the "|---|:---:|" line under a table header
+func isTableSeparator(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "|") {
+		return false
+	}
+	return strings.Trim(trimmed, "|-: ") == ""
+}
+
+// openList is a markdown list still being built while scanning lines
+type openList struct {
+	indent int
+	list   RankedList
+}
+
+// parseMarkdownLists groups list item lines into lists; nested lists become their own lists
+func parseMarkdownLists(lines []textLine, textLen int) []RankedList {
+	var done []RankedList
+	var stack []*openList
+
+	closeTop := func(end int) {
+		top := stack[len(stack)-1]
+		top.list.Items[len(top.list.Items)-1].End = end
+		done = append(done, top.list)
+		stack = stack[:len(stack)-1]
+	}
+
+	for _, line := range lines {
+		indent, isItem := listMarker(line.text)
+
+		if !isItem {
+			blank := strings.TrimSpace(line.text) == ""
+			continuation := !blank && indent > 0 && headingLevel(line.text) == 0 && !isTableRow(line.text)
+			if blank || continuation {
+				continue // Item text keeps flowing
+			}
+			for len(stack) > 0 {
+				closeTop(line.start)
+			}
+			continue
+		}
+
+		// Close deeper lists that this item ends
+		for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+			closeTop(line.start)
+		}
+
+		if len(stack) > 0 && stack[len(stack)-1].indent == indent {
+			top := stack[len(stack)-1]
+			top.list.Items[len(top.list.Items)-1].End = line.start
+			top.list.Items = append(top.list.Items, ListItem{Start: line.start})
+			continue
+		}
+
+		stack = append(stack, &openList{
+			indent: indent,
+			list:   RankedList{Kind: "list", Items: []ListItem{{Start: line.start}}},
+		})
+	}
+
+	for len(stack) > 0 {
+		closeTop(textLen)
+	}
+	return done
+}
+
+// parseHeadingLists treats consecutive headings of the same level as a list,
+// each item spanning its section until the next heading of the same or higher level
+func parseHeadingLists(lines []textLine, textLen int) []RankedList {
+	var done []RankedList
+	open := map[int]*RankedList{} // level -> list being built
+
+	closeLevel := func(level, end int) {
+		if l, ok := open[level]; ok {
+			l.Items[len(l.Items)-1].End = end
+			done = append(done, *l)
+			delete(open, level)
+		}
+	}
+
+	for _, line := range lines {
+		level := headingLevel(line.text)
+		if level == 0 {
+			continue
+		}
+		// A higher-level heading ends every deeper run
+		for l := level + 1; l <= 6; l++ {
+			closeLevel(l, line.start)
+		}
+		if l, ok := open[level]; ok {
+			l.Items[len(l.Items)-1].End = line.start
+			l.Items = append(l.Items, ListItem{Start: line.start})
+		} else {
+			open[level] = &RankedList{Kind: "heading", Items: []ListItem{{Start: line.start}}}
+		}
+	}
+
+	for level := 1; level <= 6; level++ {
+		closeLevel(level, textLen)
+	}
+	return done
+}
+
+// parseTableLists turns each pipe table into a list of its body rows
+func parseTableLists(lines []textLine) []RankedList {
+	var done []RankedList
+	var current *RankedList
+
+	for i, line := range lines {
+		if !isTableRow(line.text) {
+			if current != nil && len(current.Items) > 0 {
+				done = append(done, *current)
+			}
+			current = nil
+			continue
+		}
+		if current == nil {
+			current = &RankedList{Kind: "table"}
+		}
+		if isTableSeparator(line.text) {
+			continue
+		}
+		// The header row is the one directly above the separator
+		if i+1 < len(lines) && isTableSeparator(lines[i+1].text) {
+			continue
+		}
+		current.Items = append(current.Items, ListItem{Start: line.start, End: line.end})
+	}
+	if current != nil && len(current.Items) > 0 {
+		done = append(done, *current)
+	}
+	return done
+}
+
+// entityKey groups brand aliases with the brand while keeping products and competitors apart
+func entityKey(m DetectedMention) string {
+	if m.EntityType == "brand" {
+		if m.ProductID > 0 {
+			return "brand:product:" + strconv.Itoa(m.ProductID)
+		}
+		return "brand"
+	}
+	return "competitor:" + strings.ToLower(m.EntityName)
+}
+
+// assignListRanks sets PositionRank and ListLength for every mention.
+// The "recommended list" is the list whose items lead with the most tracked entities;
+// an entity's rank is the first item it leads. Without any list, entities are
+// ranked by order of first appearance. Mentions must be sorted by position.
+func assignListRanks(text string, mentions []DetectedMention) {
+	if len(mentions) == 0 {
+		return
+	}
+
+	ranks := map[string]int{}
+	listLength := 0
+
+	if best := recommendedList(parseRankedLists(text), mentions); best != nil {
+		listLength = len(best.Items)
+		for i, item := range best.Items {
+			if subject, ok := itemSubject(item, mentions); ok {
+				if _, seen := ranks[subject]; !seen {
+					ranks[subject] = i + 1
+				}
+			}
+		}
+	} else {
+		// Prose answer: the order entities are introduced is the ranking
+		for _, m := range mentions {
+			key := entityKey(m)
+			if _, seen := ranks[key]; !seen {
+				ranks[key] = len(ranks) + 1
+			}
+		}
+		listLength = len(ranks)
+	}
+
+	for i := range mentions {
+		mentions[i].PositionRank = ranks[entityKey(mentions[i])]
+		mentions[i].ListLength = listLength
+	}
+}
+
+// recommendedList picks the list with the most entity-led items (earliest wins ties)
+func recommendedList(lists []RankedList, mentions []DetectedMention) *RankedList {
+	var best *RankedList
+	bestCount := 0
+	for i := range lists {
+		count := 0
+		for _, item := range lists[i].Items {
+			if _, ok := itemSubject(item, mentions); ok {
+				count++
+			}
+		}
+		if count > bestCount || (count == bestCount && count > 0 && lists[i].Items[0].Start < best.Items[0].Start) {
+			best = &lists[i]
+			bestCount = count
+		}
+	}
+	return best
+}
+
+// itemSubject returns the entity mentioned first inside a list item
+func itemSubject(item ListItem, mentions []DetectedMention) (string, bool) {
+	for _, m := range mentions {
+		if m.Position >= item.Start && m.Position < item.End {
+			return entityKey(m), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestParseRankedLists(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want map[string][]int // list kind -> item counts, in parse order
+	}{
+		{"numbered", "Top picks:\n1. Acme\n2. Globex\n3. Initech", map[string][]int{"list": {3}}},
+		{"bulleted", "- Acme\n- Globex\n\nDone.", map[string][]int{"list": {2}}},
+		{"bold numbered", "**1. Acme**\n**2. Globex**", map[string][]int{"list": {2}}},
+		{"bold note is not an item", "**Note** pick one\n- Acme", map[string][]int{"list": {1}}},
+		{"nested", "1. Acme\n   - fast\n   - cheap\n2. Globex", map[string][]int{"list": {2, 2}}},
+		{"continuation lines", "1. Acme\n   great support\n2. Globex", map[string][]int{"list": {2}}},
+		{"headings", "## Acme\ntext\n## Globex\ntext\n### Details\n## Initech", map[string][]int{"heading": {1, 3}}},
+		{"table", "| Tool | Price |\n|---|---|\n| Acme | $10 |\n| Globex | $20 |", map[string][]int{"table": {2}}},
+		{"prose", "Acme and Globex are both fine.", map[string][]int{}},
+	}
+
+	for _, tc := range cases {
+		got := map[string][]int{}
+		for _, l := range parseRankedLists(tc.text) {
+			got[l.Kind] = append(got[l.Kind], len(l.Items))
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for kind, counts := range tc.want {
+			if len(got[kind]) != len(counts) {
+				t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+				break
+			}
+			for i := range counts {
+				if got[kind][i] != counts[i] {
+					t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestAssignListRanks(t *testing.T) {
+	brand := &models.Brand{
+		Name:        "Acme",
+		Competitors: []models.Competitor{{Name: "Globex"}, {Name: "Initech"}, {Name: "Umbrella"}},
+	}
+
+	cases := []struct {
+		name       string
+		text       string
+		wantRanks  map[string]int
+		wantLength int
+	}{
+		{
+			"numbered list",
+			"Here are the best CRMs:\n1. Globex - solid\n2. Acme - cheap, unlike Initech\n3. Initech",
+			map[string]int{"Globex": 1, "Acme": 2, "Initech": 3}, 3,
+		},
+		{
+			"mention in intro does not change rank",
+			"Acme is popular, but my ranking is:\n- Globex\n- Initech\n- Acme",
+			map[string]int{"Globex": 1, "Initech": 2, "Acme": 3}, 3,
+		},
+		{
+			"list with most entities wins",
+			"Pros:\n- fast\n- Acme is cheap\n\nRanking:\n1. Umbrella\n2. Globex\n3. Acme",
+			map[string]int{"Umbrella": 1, "Globex": 2, "Acme": 3}, 3,
+		},
+		{
+			"nested items rank by parent",
+			"1. Initech\n   - better than Acme at scale\n2. Acme\n3. Globex",
+			map[string]int{"Initech": 1, "Acme": 2, "Globex": 3}, 3,
+		},
+		{
+			"heading sections",
+			"## Globex\nGreat for teams.\n## Acme\nCheaper than Globex.",
+			map[string]int{"Globex": 1, "Acme": 2}, 2,
+		},
+		{
+			"table rows",
+			"| Tool | Notes |\n|---|---|\n| Initech | fast |\n| Acme | cheap |",
+			map[string]int{"Initech": 1, "Acme": 2}, 2,
+		},
+		{
+			"unranked entity in list answer",
+			"1. Globex\n2. Acme\n\nUmbrella is also worth a look.",
+			map[string]int{"Globex": 1, "Acme": 2, "Umbrella": 0}, 2,
+		},
+		{
+			"prose uses order of appearance",
+			"Globex leads, Acme follows and Globex again beats Initech.",
+			map[string]int{"Globex": 1, "Acme": 2, "Initech": 3}, 3,
+		},
+	}
+
+	d := NewMentionDetector()
+	for _, tc := range cases {
+		mentions := d.DetectMentions(tc.text, brand)
+		for _, m := range mentions {
+			want, ok := tc.wantRanks[m.EntityName]
+			if !ok {
+				t.Errorf("%s: unexpected mention of %s", tc.name, m.EntityName)
+				continue
+			}
+			if m.PositionRank != want {
+				t.Errorf("%s: %s at %d ranked %d, want %d", tc.name, m.EntityName, m.Position, m.PositionRank, want)
+			}
+			if m.ListLength != tc.wantLength {
+				t.Errorf("%s: list length %d, want %d", tc.name, m.ListLength, tc.wantLength)
+			}
+		}
+	}
+}
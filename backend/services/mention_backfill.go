@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// MentionBackfill re-runs mention detection over stored AI responses.
+// No AI provider is queried: the stored response text is re-parsed with the
+// brand's current name, aliases, competitors and products.
+type MentionBackfill struct {
+	detector  *MentionDetector
+	citations *CitationExtractor
+}
+
+// NewMentionBackfill creates a new mention backfill job
+func NewMentionBackfill() *MentionBackfill {
+	return &MentionBackfill{
+		detector:  NewMentionDetector(),
+		citations: NewCitationExtractor(),
+	}
+}
+
+// mentionIdentity matches an old mention to a re-detected one
+type mentionIdentity struct {
+	entityType string
+	entityName string
+	position   int
+}
+
+func identityOf(entityType, entityName string, position int) mentionIdentity {
+	return mentionIdentity{entityType: entityType, entityName: strings.ToLower(entityName), position: position}
+}
+
+// Run re-detects the mentions of every response of a brand created within [from, to]
+// (zero bounds are open), replaces them in one transaction and recomputes the
+// latest metric snapshot, the only one calculated from the stored responses.
+// Mentions found for the first time are labelled by the LLM judge when it is available;
+// those left without a verdict are counted as unclassified.
+// With dryRun set nothing is written and the report shows what would change.
+func (b *MentionBackfill) Run(ctx context.Context, brandID int, from, to time.Time, dryRun bool) (*models.BackfillReport, error) {
+	brand, err := db.NewBrandRepository().GetByID(brandID)
+	if err != nil {
+		return nil, fmt.Errorf("brand not found: %w", err)
+	}
+
+	responses, err := db.NewAIResponseRepository().GetByBrandIDBetween(brandID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BackfillReport{
+		BrandID:          brandID,
+		DryRun:           dryRun,
+		ResponsesScanned: len(responses),
+		EntityChanges:    []models.BackfillEntityChange{},
+		Snapshots:        []models.BackfillSnapshotChange{},
+	}
+
+	mentionRepo := db.NewMentionRepository()
+	classifier := GetMentionClassifier()
+	entities := map[string]*models.BackfillEntityChange{}
+	entityChange := func(entityType, entityName string) *models.BackfillEntityChange {
+		key := evalKey(entityType, entityName)
+		change, ok := entities[key]
+		if !ok {
+			change = &models.BackfillEntityChange{EntityName: entityName, EntityType: entityType}
+			entities[key] = change
+		}
+		return change
+	}
+
+	var rewrites []db.MentionRewrite
+	for _, response := range responses {
+		oldMentions, err := mentionRepo.GetByResponseID(response.ID)
+		if err != nil {
+			return nil, err
+		}
+		detected := b.detector.DetectMentions(response.ResponseText, brand)
+
+		report.MentionsBefore += len(oldMentions)
+		report.MentionsAfter += len(detected)
+		for _, m := range oldMentions {
+			entityChange(m.EntityType, m.EntityName).Before++
+		}
+		for _, m := range detected {
+			entityChange(m.EntityType, m.EntityName).After++
+		}
+
+		changed, added := b.diff(report, oldMentions, detected)
+		if !changed {
+			continue
+		}
+		report.ResponsesChanged++
+		if len(added) > 0 {
+			switch {
+			case !classifier.IsAvailable():
+				report.Unclassified += len(added)
+			case !dryRun:
+				report.Unclassified += b.classifyAdded(ctx, classifier, response.ResponseText, detected, added)
+			}
+		}
+		rewrites = append(rewrites, b.buildRewrite(response, detected))
+	}
+
+	for _, change := range entities {
+		if change.Before != change.After {
+			report.EntityChanges = append(report.EntityChanges, *change)
+		}
+	}
+	sort.Slice(report.EntityChanges, func(i, j int) bool {
+		if report.EntityChanges[i].EntityType != report.EntityChanges[j].EntityType {
+			return report.EntityChanges[i].EntityType < report.EntityChanges[j].EntityType
+		}
+		return report.EntityChanges[i].EntityName < report.EntityChanges[j].EntityName
+	})
+
+	if len(rewrites) == 0 {
+		return report, nil
+	}
+
+	if !dryRun {
+		if err := mentionRepo.ReplaceForResponses(rewrites); err != nil {
+			return nil, fmt.Errorf("failed to replace mentions: %w", err)
+		}
+		log.Printf("🔁 Backfill for brand %d rewrote mentions of %d/%d responses", brandID, report.ResponsesChanged, report.ResponsesScanned)
+	}
+
+	// Responses come back oldest first, so the first rewrite is the earliest one
+	if err := b.recomputeSnapshots(report, brandID, rewrites[0].Response.CreatedAt, dryRun); err != nil {
+		return nil, fmt.Errorf("failed to recompute snapshots: %w", err)
+	}
+
+	return report, nil
+}
+
+// diff compares a response's stored mentions with the re-detected ones, adds the
+// differences to the report and carries the LLM verdicts of unchanged mentions over.
+// It returns whether anything changed and the indexes of the mentions found for the first time.
+func (b *MentionBackfill) diff(report *models.BackfillReport, oldMentions []models.Mention, detected []DetectedMention) (bool, []int) {
+	old := map[mentionIdentity]models.Mention{}
+	for _, m := range oldMentions {
+		old[identityOf(m.EntityType, m.EntityName, m.Position)] = m
+	}
+
+	changed := false
+	matched := 0
+	var added []int
+	for i := range detected {
+		m := &detected[i]
+		prev, ok := old[identityOf(m.EntityType, m.EntityName, m.Position)]
+		if !ok {
+			report.Added++
+			added = append(added, i)
+			changed = true
+			continue
+		}
+		matched++
+
+		// The judge's verdict is about the same text, so it still applies
+		m.AISentiment = prev.AISentiment
+		m.AIConfidence = prev.AIConfidence
+		m.AIIsRecommendation = prev.AIIsRecommendation
+		m.AIRationale = prev.AIRationale
+		m.ClassifierModel = prev.ClassifierModel
+
+		if prev.Sentiment != m.Sentiment {
+			report.SentimentChanged++
+			changed = true
+		}
+		if prev.PositionRank != m.PositionRank || prev.ListLength != m.ListLength {
+			report.RankChanged++
+			changed = true
+		}
+		if prev.IsRecommendation != m.IsRecommendation {
+			report.RecommendationChanged++
+			changed = true
+		}
+		if prev.ProductID != m.ProductID || prev.ContextSnippet != m.ContextSnippet {
+			changed = true
+		}
+	}
+
+	if removed := len(oldMentions) - matched; removed > 0 {
+		report.Removed += removed
+		changed = true
+	}
+	return changed, added
+}
+
+// classifyAdded asks the judge for a verdict on the mentions found for the first time
+// and returns how many of them it failed to label
+func (b *MentionBackfill) classifyAdded(ctx context.Context, classifier *MentionClassifier, responseText string, detected []DetectedMention, added []int) int {
+	batch := make([]DetectedMention, len(added))
+	for i, idx := range added {
+		batch[i] = detected[idx]
+	}
+	errs := classifier.ClassifyMentions(ctx, responseText, batch)
+	for _, msg := range errs {
+		log.Printf("Warning: %s", msg)
+	}
+	for i, idx := range added {
+		detected[idx] = batch[i]
+	}
+	return len(errs)
+}
+
+// buildRewrite converts re-detected mentions, their aspects and the response's citations
+// into the rows that replace the stored ones
+func (b *MentionBackfill) buildRewrite(response models.AIResponse, detected []DetectedMention) db.MentionRewrite {
+	rw := db.MentionRewrite{Response: response}
+
+	for _, m := range detected {
+		rw.Mentions = append(rw.Mentions, models.Mention{
+			AIResponseID:       response.ID,
+			EntityName:         m.EntityName,
+			EntityType:         m.EntityType,
+			Sentiment:          m.Sentiment,
+			ContextSnippet:     m.ContextSnippet,
+			Position:           m.Position,
+			IsRecommendation:   m.IsRecommendation,
+			PositionRank:       m.PositionRank,
+			ListLength:         m.ListLength,
+			ProductID:          m.ProductID,
+			ProductName:        m.ProductName,
+			AISentiment:        m.AISentiment,
+			AIConfidence:       m.AIConfidence,
+			AIIsRecommendation: m.AIIsRecommendation,
+			AIRationale:        m.AIRationale,
+			ClassifierModel:    m.ClassifierModel,
+		})
+
+		var aspects []models.MentionAspect
+		for _, a := range m.Aspects {
+			aspects = append(aspects, models.MentionAspect{
+				EntityName: m.EntityName,
+				EntityType: m.EntityType,
+				Aspect:     a.Aspect,
+				Sentiment:  a.Sentiment,
+				Snippet:    a.Snippet,
+			})
+		}
+		rw.Aspects = append(rw.Aspects, aspects)
+	}
+
+	for _, c := range b.citations.ExtractCitations(response.ResponseText, detected) {
+		rw.Citations = append(rw.Citations, models.ResponseCitation{
+			URL:        c.URL,
+			Domain:     c.Domain,
+			SourceName: c.SourceName,
+			Position:   c.Position,
+		})
+		rw.CitationMentions = append(rw.CitationMentions, c.MentionIndex)
+	}
+
+	return rw
+}
+
+// recomputeSnapshots rescores the brand's latest snapshot and its competitor, model,
+// category, prompt and product snapshots. Each analysis run replaces the brand's
+// responses, so the stored responses are the latest run and only the snapshot
+// calculated from it can be rebuilt; earlier snapshots in the window are left as they
+// are and counted in the report. The snapshot and its children are replaced in one
+// transaction. A dry run only lists what would be rescored.
+func (b *MentionBackfill) recomputeSnapshots(report *models.BackfillReport, brandID int, since time.Time, dryRun bool) error {
+	metricRepo := db.NewMetricRepository()
+	snapshots, err := metricRepo.GetCreatedSince(brandID, since)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+	latest := snapshots[len(snapshots)-1]
+	if report.SnapshotsNotRecomputed = len(snapshots) - 1; report.SnapshotsNotRecomputed > 0 {
+		report.Note = fmt.Sprintf("Only the latest snapshot was recomputed: responses of earlier runs are not kept, so %d older snapshots keep their scores", report.SnapshotsNotRecomputed)
+	}
+
+	change := models.BackfillSnapshotChange{SnapshotID: latest.ID, SnapshotDate: latest.SnapshotDate, Before: latest.VisibilityScore}
+	if dryRun {
+		report.Snapshots = append(report.Snapshots, change)
+		return nil
+	}
+
+	m := NewMetricsCalculator()
+	profile := loadScoringProfile(brandID)
+	recomputed, stats, err := m.calculateSnapshot(brandID, profile)
+	if err != nil {
+		return err
+	}
+	if recomputed == nil {
+		return nil
+	}
+
+	recomputed.ID = latest.ID
+	recomputed.SnapshotDate = latest.SnapshotDate
+	children := db.SnapshotChildren{
+		Products:    m.productSnapshots(brandID, stats.products, profile, recomputed.ResponseCount, recomputed.CategoryAvgSentiment, latest.SnapshotDate),
+		Competitors: m.competitorSnapshots(brandID, latest.ID, stats.competitors, profile, recomputed.ResponseCount, latest.SnapshotDate),
+		Models:      m.modelSnapshots(brandID, latest.ID, stats.models, profile, latest.SnapshotDate),
+		Categories:  m.categorySnapshots(brandID, latest.ID, stats.categories, profile, latest.SnapshotDate),
+		Prompts:     m.promptSnapshots(brandID, latest.ID, stats.prompts, latest.SnapshotDate),
+	}
+	if err := metricRepo.ReplaceSnapshot(recomputed, children); err != nil {
+		return err
+	}
+
+	change.After = &recomputed.VisibilityScore
+	report.Snapshots = append(report.Snapshots, change)
+	return nil
+}
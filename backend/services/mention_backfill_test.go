@@ -0,0 +1,168 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestBackfillDiff(t *testing.T) {
+	judged := models.Mention{
+		EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "positive",
+		PositionRank: 1, ListLength: 3, IsRecommendation: true,
+		AISentiment: "positive", AIConfidence: 0.9, AIIsRecommendation: true,
+		AIRationale: "Recommended first", ClassifierModel: "judge-1",
+	}
+	acme := DetectedMention{EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "positive", PositionRank: 1, ListLength: 3, IsRecommendation: true}
+	rival := DetectedMention{EntityName: "Rival", EntityType: "competitor", Position: 40, Sentiment: "neutral"}
+
+	cases := []struct {
+		name        string
+		old         []models.Mention
+		detected    []DetectedMention
+		wantChanged bool
+		wantAdded   []int
+		want        models.BackfillReport
+	}{
+		{
+			name:     "unchanged",
+			old:      []models.Mention{judged},
+			detected: []DetectedMention{acme},
+		},
+		{
+			name:        "added",
+			old:         []models.Mention{judged},
+			detected:    []DetectedMention{acme, rival},
+			wantChanged: true,
+			wantAdded:   []int{1},
+			want:        models.BackfillReport{Added: 1},
+		},
+		{
+			name:        "removed",
+			old:         []models.Mention{judged, {EntityName: "Rival", EntityType: "competitor", Position: 40}},
+			detected:    []DetectedMention{acme},
+			wantChanged: true,
+			want:        models.BackfillReport{Removed: 1},
+		},
+		{
+			name:        "name matched case-insensitively",
+			old:         []models.Mention{{EntityName: "ACME", EntityType: "brand", Position: 0, Sentiment: "positive", PositionRank: 1, ListLength: 3, IsRecommendation: true}},
+			detected:    []DetectedMention{acme},
+			wantChanged: false,
+		},
+		{
+			name:        "moved mention is removed and added",
+			old:         []models.Mention{judged},
+			detected:    []DetectedMention{{EntityName: "Acme", EntityType: "brand", Position: 12, Sentiment: "positive"}},
+			wantChanged: true,
+			wantAdded:   []int{0},
+			want:        models.BackfillReport{Added: 1, Removed: 1},
+		},
+		{
+			name:        "sentiment changed",
+			old:         []models.Mention{judged},
+			detected:    []DetectedMention{{EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "negative", PositionRank: 1, ListLength: 3, IsRecommendation: true}},
+			wantChanged: true,
+			want:        models.BackfillReport{SentimentChanged: 1},
+		},
+		{
+			name:        "rank and recommendation changed",
+			old:         []models.Mention{judged},
+			detected:    []DetectedMention{{EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "positive", PositionRank: 2, ListLength: 3}},
+			wantChanged: true,
+			want:        models.BackfillReport{RankChanged: 1, RecommendationChanged: 1},
+		},
+		{
+			name:        "product attribution changed",
+			old:         []models.Mention{judged},
+			detected:    []DetectedMention{{EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "positive", PositionRank: 1, ListLength: 3, IsRecommendation: true, ProductID: 7}},
+			wantChanged: true,
+		},
+	}
+
+	b := NewMentionBackfill()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			detected := append([]DetectedMention(nil), tc.detected...)
+			var report models.BackfillReport
+			changed, added := b.diff(&report, tc.old, detected)
+
+			if changed != tc.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tc.wantChanged)
+			}
+			if !reflect.DeepEqual(added, tc.wantAdded) {
+				t.Errorf("added = %v, want %v", added, tc.wantAdded)
+			}
+			if !reflect.DeepEqual(report, tc.want) {
+				t.Errorf("report = %+v, want %+v", report, tc.want)
+			}
+		})
+	}
+}
+
+func TestBackfillDiffCarriesVerdict(t *testing.T) {
+	old := []models.Mention{{
+		EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "neutral",
+		AISentiment: "positive", AIConfidence: 0.8, AIIsRecommendation: true,
+		AIRationale: "Praised for support", ClassifierModel: "judge-1",
+	}}
+	detected := []DetectedMention{
+		{EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "positive"},
+		{EntityName: "Rival", EntityType: "competitor", Position: 30, Sentiment: "neutral"},
+	}
+
+	var report models.BackfillReport
+	NewMentionBackfill().diff(&report, old, detected)
+
+	got := detected[0]
+	if got.AISentiment != "positive" || got.AIConfidence != 0.8 || !got.AIIsRecommendation ||
+		got.AIRationale != "Praised for support" || got.ClassifierModel != "judge-1" {
+		t.Errorf("matched mention verdict = %+v, want the stored verdict", got)
+	}
+	if got.Sentiment != "positive" {
+		t.Errorf("matched mention sentiment = %q, want the re-detected %q", got.Sentiment, "positive")
+	}
+	if added := detected[1]; added.AISentiment != "" || added.ClassifierModel != "" {
+		t.Errorf("added mention verdict = %+v, want none", added)
+	}
+}
+
+func TestBackfillBuildRewrite(t *testing.T) {
+	response := models.AIResponse{ID: 5, ResponseText: "Acme has great support. Details at https://www.g2.com/products/acme."}
+	detected := []DetectedMention{{
+		EntityName: "Acme", EntityType: "brand", Position: 0, Sentiment: "positive",
+		PositionRank: 1, ListLength: 2, ProductID: 3, ProductName: "Acme CRM",
+		Aspects:     []DetectedAspect{{Aspect: "support", Sentiment: "positive", Snippet: "great support"}},
+		AISentiment: "positive", AIConfidence: 0.7, ClassifierModel: "judge-1",
+	}}
+
+	rw := NewMentionBackfill().buildRewrite(response, detected)
+
+	if rw.Response.ID != 5 {
+		t.Errorf("Response.ID = %d, want 5", rw.Response.ID)
+	}
+	if len(rw.Mentions) != 1 {
+		t.Fatalf("len(Mentions) = %d, want 1", len(rw.Mentions))
+	}
+	want := models.Mention{
+		AIResponseID: 5, EntityName: "Acme", EntityType: "brand", Sentiment: "positive",
+		PositionRank: 1, ListLength: 2, ProductID: 3, ProductName: "Acme CRM",
+		AISentiment: "positive", AIConfidence: 0.7, ClassifierModel: "judge-1",
+	}
+	if rw.Mentions[0] != want {
+		t.Errorf("Mentions[0] = %+v, want %+v", rw.Mentions[0], want)
+	}
+
+	wantAspects := [][]models.MentionAspect{{{EntityName: "Acme", EntityType: "brand", Aspect: "support", Sentiment: "positive", Snippet: "great support"}}}
+	if !reflect.DeepEqual(rw.Aspects, wantAspects) {
+		t.Errorf("Aspects = %+v, want %+v", rw.Aspects, wantAspects)
+	}
+
+	if len(rw.Citations) != 1 || rw.Citations[0].Domain != "g2.com" {
+		t.Fatalf("Citations = %+v, want one g2.com citation", rw.Citations)
+	}
+	if len(rw.CitationMentions) != len(rw.Citations) {
+		t.Errorf("len(CitationMentions) = %d, want %d", len(rw.CitationMentions), len(rw.Citations))
+	}
+}
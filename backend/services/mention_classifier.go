@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/ai"
+	"github.com/Sneh16Shah/ai-visibility-tracker/config"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// Sentiment sources a brand can choose to drive its composite score
+const (
+	SentimentSourceRules = scoring.SentimentSourceRules
+	SentimentSourceLLM   = scoring.SentimentSourceLLM
+)
+
+// classifierWindow is how many characters around a mention are sent to the judge.
+// Wider than the stored snippet so the model can see the sentence it sits in.
+const classifierWindow = 300
+
+// MentionClassifier uses an LLM as a judge for mention sentiment and recommendation
+type MentionClassifier struct {
+	provider ai.Provider
+}
+
+// Global singleton for the classifier (nil when disabled)
+var mentionClassifier *MentionClassifier
+
+// MentionClassification is the strict JSON verdict returned by the judge
+type MentionClassification struct {
+	Sentiment        string  `json:"sentiment"`
+	Confidence       float64 `json:"confidence"`
+	IsRecommendation bool    `json:"is_recommendation"`
+	Rationale        string  `json:"rationale"`
+}
+
+// InitMentionClassifier initializes the classifier from CLASSIFIER_PROVIDER
+func InitMentionClassifier(cfg *config.Config) *MentionClassifier {
+	var provider ai.Provider
+
+	switch cfg.ClassifierProvider {
+	case "":
+		log.Println("🧑‍⚖️ Mention classifier disabled (set CLASSIFIER_PROVIDER to enable)")
+		return nil
+	case "ollama":
+		provider = ai.NewOllamaProvider("http://localhost:11434", "llama2")
+	case "openai":
+		if cfg.OpenAIKey != "" {
+			provider = ai.NewOpenAIProvider(cfg.OpenAIKey)
+		}
+	case "gemini":
+		if cfg.GeminiKey != "" {
+			provider = ai.NewGeminiProvider(cfg.GeminiKey)
+		}
+	case "groq":
+		if cfg.GroqKey != "" {
+			provider = ai.NewGroqProvider(cfg.GroqKey)
+		}
+	case "openrouter":
+		if cfg.OpenRouterKey != "" {
+			provider = ai.NewOpenRouterProvider(cfg.OpenRouterKey)
+		}
+	}
+
+	if provider == nil {
+		log.Printf("⚠️ Mention classifier provider %q is unknown or missing its API key - classifier disabled", cfg.ClassifierProvider)
+		return nil
+	}
+
+	mentionClassifier = &MentionClassifier{provider: provider}
+	log.Printf("🧑‍⚖️ Mention classifier enabled (%s)", provider.GetModelName())
+	return mentionClassifier
+}
+
+// GetMentionClassifier returns the classifier, or nil when disabled
+func GetMentionClassifier() *MentionClassifier {
+	return mentionClassifier
+}
+
+// IsAvailable checks if the classifier can be used
+func (c *MentionClassifier) IsAvailable() bool {
+	return c != nil && c.provider != nil && c.provider.IsAvailable()
+}
+
+// GetModelName returns the judge model name
+func (c *MentionClassifier) GetModelName() string {
+	if c == nil || c.provider == nil {
+		return ""
+	}
+	return c.provider.GetModelName()
+}
+
+// Classify asks the judge for a verdict on a single mention in context
+func (c *MentionClassifier) Classify(ctx context.Context, entityName, text string) (*MentionClassification, error) {
+	if !c.IsAvailable() {
+		return nil, ai.ErrProviderNotReady
+	}
+
+	prompt := fmt.Sprintf(`You are a strict classifier. Judge how the following text talks about "%s".
+
+Text:
+"""
+%s
+"""
+
+Respond with ONLY a JSON object, no prose and no code fences, matching exactly this schema:
+{"sentiment": "positive" | "neutral" | "negative", "confidence": number between 0 and 1, "is_recommendation": boolean, "rationale": string (one short sentence)}
+
+"is_recommendation" is true only if the text explicitly recommends or endorses "%s" itself, not another product.`,
+		entityName, text, entityName)
+
+	raw, err := c.provider.Query(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseClassification(raw)
+}
+
+// ClassifyMentions fills the AI fields of each detected mention in place.
+// Failures are returned as messages and leave the rule-based labels untouched.
+func (c *MentionClassifier) ClassifyMentions(ctx context.Context, responseText string, mentions []DetectedMention) []string {
+	var errs []string
+	for i := range mentions {
+		m := &mentions[i]
+		verdict, err := c.Classify(ctx, m.EntityName, classifierContext(responseText, m.Position, len(m.EntityName)))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Classifier failed for %s: %s", m.EntityName, err.Error()))
+			continue
+		}
+		m.AISentiment = verdict.Sentiment
+		m.AIConfidence = verdict.Confidence
+		m.AIIsRecommendation = verdict.IsRecommendation
+		m.AIRationale = verdict.Rationale
+		m.ClassifierModel = c.GetModelName()
+	}
+	return errs
+}
+
+// classifierContext extracts the text window around a mention for the judge,
+// widened to rune boundaries so multi-byte characters are never split
+func classifierContext(text string, position, length int) string {
+	start := max(0, position-classifierWindow)
+	end := min(len(text), position+length+classifierWindow)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	return text[start:end]
+}
+
+// parseClassification decodes and validates the judge's JSON verdict
+func parseClassification(raw string) (*MentionClassification, error) {
+	// Models sometimes wrap JSON in code fences or prose - keep only the object
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("classifier returned no JSON object")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw[start : end+1]))
+	decoder.DisallowUnknownFields()
+
+	var verdict MentionClassification
+	if err := decoder.Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("classifier returned invalid JSON: %w", err)
+	}
+
+	verdict.Sentiment = strings.ToLower(strings.TrimSpace(verdict.Sentiment))
+	switch verdict.Sentiment {
+	case "positive", "neutral", "negative":
+	default:
+		return nil, fmt.Errorf("classifier returned unknown sentiment %q", verdict.Sentiment)
+	}
+	if verdict.Confidence < 0 || verdict.Confidence > 1 {
+		return nil, fmt.Errorf("classifier confidence %.2f out of range", verdict.Confidence)
+	}
+
+	return &verdict, nil
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -25,7 +27,20 @@ type DetectedMention struct {
 	ContextSnippet   string
 	Position         int
 	IsRecommendation bool // True if explicitly recommended
-	PositionRank     int  // 1=first, 2=second, 3+=later (within response)
+	PositionRank     int  // Rank of the entity in the response's recommended list (1=first)
+	ListLength       int  // Number of items in that list
+	Aspects          []DetectedAspect
+
+	// Product the mention is attributed to (brand mentions only)
+	ProductID   int
+	ProductName string
+
+	// LLM-as-judge verdict (empty when the classifier did not run)
+	AISentiment        string
+	AIConfidence       float64
+	AIIsRecommendation bool
+	AIRationale        string
+	ClassifierModel    string
 }
 
 // DetectMentions finds all brand and competitor mentions in AI response text
@@ -45,6 +60,9 @@ func (d *MentionDetector) DetectMentions(responseText string, brand *models.Bran
 		mentions = append(mentions, aliasMentions...)
 	}
 
+	// Attribute brand mentions to products ("Acme CRM") and catch product-only names
+	mentions = d.attributeProducts(responseText, lowerText, brand.Products, mentions)
+
 	// Detect competitor mentions
 	for _, competitor := range brand.Competitors {
 		compMentions := d.findEntityMentions(responseText, lowerText, competitor.Name, "competitor")
@@ -54,24 +72,64 @@ func (d *MentionDetector) DetectMentions(responseText string, brand *models.Bran
 	// Sort mentions by position to assign position ranks
 	sortMentionsByPosition(mentions)
 
-	// Calculate position ranks per entity type
-	brandRank := 0
-	for i := range mentions {
-		if mentions[i].EntityType == "brand" {
-			brandRank++
-			mentions[i].PositionRank = brandRank
-		}
+	// Rank every entity by its place in the answer's recommended list
+	assignListRanks(responseText, mentions)
 
+	for i := range mentions {
 		// Analyze sentiment for each mention
 		mentions[i].Sentiment = d.analyzeSentiment(mentions[i].ContextSnippet)
 
 		// Check if this mention is an explicit recommendation
 		mentions[i].IsRecommendation = d.isRecommendation(lowerText, mentions[i].EntityName, mentions[i].Position)
+
+		// Break sentiment down by product aspect (pricing, support, ...)
+		mentions[i].Aspects = d.extractAspects(responseText, mentions[i].Position, len(mentions[i].EntityName))
 	}
 
 	return mentions
 }
 
+// attributeProducts links brand mentions to the product named at the same spot.
+// Product names that do not overlap a brand mention become brand mentions of their own.
+// Longer names are matched first so "Acme CRM Pro" wins over "Acme CRM".
+func (d *MentionDetector) attributeProducts(originalText, lowerText string, products []models.Product, mentions []DetectedMention) []DetectedMention {
+	type productName struct {
+		product models.Product
+		name    string
+	}
+	var names []productName
+	for _, p := range products {
+		names = append(names, productName{product: p, name: p.Name})
+		for _, a := range p.Aliases {
+			names = append(names, productName{product: p, name: a.Alias})
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool { return len(names[i].name) > len(names[j].name) })
+
+	for _, pn := range names {
+		for _, pm := range d.findEntityMentions(originalText, lowerText, pn.name, "brand") {
+			attributed := false
+			for i := range mentions {
+				m := &mentions[i]
+				if m.EntityType != "brand" || m.Position >= pm.Position+len(pm.EntityName) || pm.Position >= m.Position+len(m.EntityName) {
+					continue
+				}
+				if m.ProductID == 0 {
+					m.ProductID = pn.product.ID
+					m.ProductName = pn.product.Name
+				}
+				attributed = true
+			}
+			if !attributed {
+				pm.ProductID = pn.product.ID
+				pm.ProductName = pn.product.Name
+				mentions = append(mentions, pm)
+			}
+		}
+	}
+	return mentions
+}
+
 // sortMentionsByPosition sorts mentions by their position in the text
 func sortMentionsByPosition(mentions []DetectedMention) {
 	for i := 0; i < len(mentions)-1; i++ {
@@ -109,26 +167,28 @@ var recommendationPatterns = []string{
 	"is perfect for",
 }
 
-// isRecommendation checks if a mention is explicitly recommended
+// isRecommendation checks if a mention is explicitly recommended. Only phrases in the
+// mention's own sentence count, so "I recommend X" does not make every other entity in
+// the answer a recommendation.
 func (d *MentionDetector) isRecommendation(lowerText, entityName string, entityPosition int) bool {
-	lowerEntity := strings.ToLower(entityName)
+	start, end := sentenceBounds(lowerText, entityPosition, len(entityName))
+	sentence := lowerText[start:end]
 
 	for _, pattern := range recommendationPatterns {
-		patternPos := strings.Index(lowerText, pattern)
-		if patternPos == -1 {
-			continue
-		}
+		searchStart := 0
+		for {
+			pos := strings.Index(sentence[searchStart:], pattern)
+			if pos == -1 {
+				break
+			}
+			patternPos := start + searchStart + pos
 
-		// Check if entity name is within 150 characters of the recommendation phrase
-		// This accounts for phrases like "For your use case, I recommend X because..."
-		distance := abs(patternPos - entityPosition)
-		if distance < 150 {
-			// Additional check: entity should appear after the pattern or very close before
-			// e.g., "I recommend Salesforce" or "Salesforce is my recommendation"
-			entityPos := strings.Index(lowerText, lowerEntity)
-			if entityPos >= patternPos-50 { // Entity can be up to 50 chars before pattern
+			// Entity should appear after the pattern or very close before
+			// e.g., "I recommend Salesforce" or "Salesforce is my top pick"
+			if entityPosition >= patternPos-50 {
 				return true
 			}
+			searchStart += pos + len(pattern)
 		}
 	}
 
@@ -236,8 +296,11 @@ var (
 
 // analyzeSentiment performs rule-based sentiment analysis on context
 func (d *MentionDetector) analyzeSentiment(context string) string {
-	lowerContext := strings.ToLower(context)
+	return d.classifySentiment(strings.ToLower(context), positiveWords, negativeWords)
+}
 
+// classifySentiment scores lowercase text against the given polarity lexicons
+func (d *MentionDetector) classifySentiment(lowerContext string, positiveWords, negativeWords []string) string {
 	positiveScore := 0
 	negativeScore := 0
 
@@ -295,14 +358,15 @@ func (d *MentionDetector) hasNearbyNegation(text, targetWord string) bool {
 	return false
 }
 
-// StoreMentions saves detected mentions to the database
-func (d *MentionDetector) StoreMentions(aiResponseID int, mentions []DetectedMention) ([]models.Mention, error) {
+// StoreMentions saves detected mentions (and their aspect sentiment) to the database
+func (d *MentionDetector) StoreMentions(aiResponse *models.AIResponse, mentions []DetectedMention) ([]models.Mention, error) {
 	repo := db.NewMentionRepository()
+	aspectRepo := db.NewAspectRepository()
 	var storedMentions []models.Mention
 
 	for _, m := range mentions {
 		mention, err := repo.Create(
-			aiResponseID,
+			aiResponse.ID,
 			m.EntityName,
 			m.EntityType,
 			m.Sentiment,
@@ -310,10 +374,43 @@ func (d *MentionDetector) StoreMentions(aiResponseID int, mentions []DetectedMen
 			m.Position,
 			m.IsRecommendation,
 			m.PositionRank,
+			m.ListLength,
 		)
 		if err != nil {
 			return storedMentions, err
 		}
+		if m.ProductID > 0 {
+			if err := repo.UpdateProduct(mention.ID, m.ProductID, m.ProductName); err != nil {
+				return storedMentions, err
+			}
+			mention.ProductID = m.ProductID
+			mention.ProductName = m.ProductName
+		}
+		if m.AISentiment != "" {
+			if err := repo.UpdateClassification(mention.ID, m.AISentiment, m.AIConfidence, m.AIIsRecommendation, m.AIRationale, m.ClassifierModel); err != nil {
+				return storedMentions, err
+			}
+			mention.AISentiment = m.AISentiment
+			mention.AIConfidence = m.AIConfidence
+			mention.AIIsRecommendation = m.AIIsRecommendation
+			mention.AIRationale = m.AIRationale
+			mention.ClassifierModel = m.ClassifierModel
+		}
+		for _, a := range m.Aspects {
+			err := aspectRepo.Create(&models.MentionAspect{
+				BrandID:    aiResponse.BrandID,
+				MentionID:  mention.ID,
+				EntityName: m.EntityName,
+				EntityType: m.EntityType,
+				Aspect:     a.Aspect,
+				Sentiment:  a.Sentiment,
+				Snippet:    a.Snippet,
+				ModelName:  aiResponse.ModelName,
+			})
+			if err != nil {
+				return storedMentions, err
+			}
+		}
 		storedMentions = append(storedMentions, *mention)
 	}
 
@@ -335,10 +432,18 @@ func min(a, b int) int {
 	return b
 }
 
-// AnalyzeSentimentWithAI uses AI for more accurate sentiment (optional enhancement)
-func (d *MentionDetector) AnalyzeSentimentWithAI(context string) string {
-	// For now, use rule-based. Can be enhanced with AI later.
-	return d.analyzeSentiment(context)
+// AnalyzeSentimentWithAI asks the configured classifier for the sentiment of a context,
+// falling back to the rule-based label when the classifier is disabled or fails
+func (d *MentionDetector) AnalyzeSentimentWithAI(ctx context.Context, entityName, snippet string) string {
+	classifier := GetMentionClassifier()
+	if !classifier.IsAvailable() {
+		return d.analyzeSentiment(snippet)
+	}
+	verdict, err := classifier.Classify(ctx, entityName, snippet)
+	if err != nil {
+		return d.analyzeSentiment(snippet)
+	}
+	return verdict.Sentiment
 }
 
 // ExtractKeyPhrases extracts key phrases around the mention
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestIsRecommendationScopedToSentence(t *testing.T) {
+	cases := []struct {
+		text, entity string
+		want         bool
+	}{
+		{"I recommend Acme for most teams.", "Acme", true},
+		{"Acme is the best choice for startups.", "Acme", true},
+		// The recommendation belongs to Acme's sentence, not Globex's
+		{"I recommend Acme for most teams. Globex is another CRM.", "Globex", false},
+		{"Globex is another CRM. For your use case I'd suggest Acme.", "Globex", false},
+		{"Globex is another CRM. For your use case I'd suggest Acme.", "Acme", true},
+		// Entity far before the phrase in a long sentence
+		{"Globex, which has been around for many years and serves a wide range of industries worldwide, is fine but honestly I recommend Acme.", "Globex", false},
+	}
+
+	d := NewMentionDetector()
+	for _, tc := range cases {
+		lower := strings.ToLower(tc.text)
+		pos := strings.LastIndex(lower, strings.ToLower(tc.entity))
+		if got := d.isRecommendation(lower, tc.entity, pos); got != tc.want {
+			t.Errorf("isRecommendation(%q, %s) = %v, want %v", tc.text, tc.entity, got, tc.want)
+		}
+	}
+}
+
+func TestClassifierContextKeepsRunesWhole(t *testing.T) {
+	text := strings.Repeat("é", classifierWindow) + "Acme" + strings.Repeat("ü", classifierWindow)
+	position := strings.Index(text, "Acme")
+	context := classifierContext(text, position, len("Acme"))
+	if !utf8.ValidString(context) {
+		t.Fatalf("context is not valid UTF-8: %q", context)
+	}
+	if !strings.Contains(context, "Acme") {
+		t.Errorf("context %q lost the mention", context)
+	}
+}
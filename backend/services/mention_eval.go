@@ -0,0 +1,285 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// Fields scored by the mention detection evaluation
+const (
+	EvalFieldMention        = "mention"
+	EvalFieldSentiment      = "sentiment"
+	EvalFieldRecommendation = "recommendation"
+	EvalFieldRank           = "rank"
+)
+
+// EvalFields lists the scored fields in report order
+var EvalFields = []string{EvalFieldMention, EvalFieldSentiment, EvalFieldRecommendation, EvalFieldRank}
+
+// EvalCase is one labelled response in the evaluation corpus
+type EvalCase struct {
+	ID       string            `json:"id"`
+	Response string            `json:"response"`
+	Brand    EvalBrand         `json:"brand"`
+	Expected []EvalExpectation `json:"expected"`
+}
+
+// EvalBrand is the tracked brand configuration for a case
+type EvalBrand struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Competitors []string `json:"competitors,omitempty"`
+}
+
+// EvalExpectation is the labelled outcome for one entity in a response.
+// Entities are labelled once per response, not once per occurrence.
+type EvalExpectation struct {
+	Entity      string `json:"entity"`         // Brand name or competitor name (aliases count as the brand)
+	Type        string `json:"type"`           // "brand" or "competitor"
+	Sentiment   string `json:"sentiment"`      // "positive", "neutral", "negative"
+	Recommended bool   `json:"recommended"`    // Explicitly recommended
+	Rank        int    `json:"rank,omitempty"` // Rank in the recommended list (0 = unranked)
+	Note        string `json:"note,omitempty"` // Why the label is what it is
+}
+
+// EvalScore is precision/recall/F1 for one field
+type EvalScore struct {
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// EvalError describes one disagreement between the detector and the labels
+type EvalError struct {
+	CaseID   string `json:"case_id"`
+	Field    string `json:"field"`
+	Entity   string `json:"entity"`
+	Expected string `json:"expected"`
+	Got      string `json:"got"`
+}
+
+// EvalReport is the result of running the detector over a corpus
+type EvalReport struct {
+	Cases  int                  `json:"cases"`
+	Scores map[string]EvalScore `json:"scores"`
+	Errors []EvalError          `json:"errors"`
+}
+
+// LoadEvalCorpus reads a JSON array of labelled cases
+func LoadEvalCorpus(path string) ([]EvalCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cases []EvalCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("invalid corpus %s: %w", path, err)
+	}
+	return cases, nil
+}
+
+// LoadEvalBaseline reads the minimum F1 per field that detector changes must keep
+func LoadEvalBaseline(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline map[string]float64
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// EvaluateMentionDetector runs the rule-based detector over every case and scores each field
+func EvaluateMentionDetector(cases []EvalCase) *EvalReport {
+	detector := NewMentionDetector()
+	counts := map[string]*EvalScore{}
+	for _, field := range EvalFields {
+		counts[field] = &EvalScore{}
+	}
+	report := &EvalReport{Cases: len(cases)}
+
+	for _, c := range cases {
+		predicted := predictEntities(detector.DetectMentions(c.Response, c.Brand.toModel()), c.Brand)
+		expected := map[string]EvalExpectation{}
+		for _, e := range c.Expected {
+			expected[evalKey(e.Type, e.Entity)] = e
+		}
+
+		for _, key := range unionKeys(predicted, expected) {
+			p, havePred := predicted[key]
+			e, haveExp := expected[key]
+			entity := p.Entity
+			if haveExp {
+				entity = e.Entity
+			}
+
+			score := func(field string, predValue, expValue string, predPositive, expPositive bool) {
+				s := counts[field]
+				switch {
+				case predPositive && expPositive && predValue == expValue:
+					s.TruePositives++
+					return
+				case predPositive && expPositive:
+					s.FalsePositives++
+					s.FalseNegatives++
+				case predPositive:
+					s.FalsePositives++
+				case expPositive:
+					s.FalseNegatives++
+				default:
+					return
+				}
+				report.Errors = append(report.Errors, EvalError{CaseID: c.ID, Field: field, Entity: entity, Expected: expValue, Got: predValue})
+			}
+
+			score(EvalFieldMention, presence(havePred), presence(haveExp), havePred, haveExp)
+			score(EvalFieldSentiment, p.Sentiment, e.Sentiment, havePred, haveExp)
+			score(EvalFieldRecommendation, fmt.Sprint(p.Recommended), fmt.Sprint(e.Recommended), havePred && p.Recommended, haveExp && e.Recommended)
+			score(EvalFieldRank, fmt.Sprint(p.Rank), fmt.Sprint(e.Rank), havePred && p.Rank > 0, haveExp && e.Rank > 0)
+		}
+	}
+
+	report.Scores = map[string]EvalScore{}
+	for field, s := range counts {
+		s.Precision = ratio(s.TruePositives, s.TruePositives+s.FalsePositives)
+		s.Recall = ratio(s.TruePositives, s.TruePositives+s.FalseNegatives)
+		if s.Precision+s.Recall > 0 {
+			s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+		}
+		report.Scores[field] = *s
+	}
+	return report
+}
+
+// Regressions returns the fields whose F1 fell below the baseline minus the tolerance
+func (r *EvalReport) Regressions(baseline map[string]float64, tolerance float64) []string {
+	var failed []string
+	for _, field := range EvalFields {
+		minF1, ok := baseline[field]
+		if !ok {
+			continue
+		}
+		if got := r.Scores[field].F1; got < minF1-tolerance {
+			failed = append(failed, fmt.Sprintf("%s F1 %.3f is below baseline %.3f", field, got, minF1))
+		}
+	}
+	return failed
+}
+
+// Summary formats the scores as a table
+func (r *EvalReport) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Mention detection evaluation (%d cases)\n", r.Cases)
+	fmt.Fprintf(&b, "%-15s %5s %5s %5s %9s %7s %7s\n", "field", "tp", "fp", "fn", "precision", "recall", "f1")
+	for _, field := range EvalFields {
+		s := r.Scores[field]
+		fmt.Fprintf(&b, "%-15s %5d %5d %5d %9.3f %7.3f %7.3f\n", field, s.TruePositives, s.FalsePositives, s.FalseNegatives, s.Precision, s.Recall, s.F1)
+	}
+	return b.String()
+}
+
+// toModel converts the case's brand config into the model the detector expects
+func (b EvalBrand) toModel() *models.Brand {
+	brand := &models.Brand{Name: b.Name}
+	for _, a := range b.Aliases {
+		brand.Aliases = append(brand.Aliases, models.BrandAlias{Alias: a})
+	}
+	for _, c := range b.Competitors {
+		brand.Competitors = append(brand.Competitors, models.Competitor{Name: c})
+	}
+	return brand
+}
+
+// predictEntities collapses detected mentions into one prediction per entity:
+// the majority sentiment (earliest mention breaks ties), any recommendation, the best rank
+func predictEntities(mentions []DetectedMention, brand EvalBrand) map[string]EvalExpectation {
+	type votes struct {
+		prediction EvalExpectation
+		sentiment  map[string]int
+		order      []string
+	}
+	byEntity := map[string]*votes{}
+
+	for _, m := range mentions {
+		name := m.EntityName
+		if m.EntityType == "brand" {
+			name = brand.Name // Aliases count as the brand
+		}
+		key := evalKey(m.EntityType, name)
+		v, ok := byEntity[key]
+		if !ok {
+			v = &votes{prediction: EvalExpectation{Entity: name, Type: m.EntityType}, sentiment: map[string]int{}}
+			byEntity[key] = v
+		}
+		if v.sentiment[m.Sentiment] == 0 {
+			v.order = append(v.order, m.Sentiment)
+		}
+		v.sentiment[m.Sentiment]++
+		if m.IsRecommendation {
+			v.prediction.Recommended = true
+		}
+		if m.PositionRank > 0 && (v.prediction.Rank == 0 || m.PositionRank < v.prediction.Rank) {
+			v.prediction.Rank = m.PositionRank
+		}
+	}
+
+	predicted := map[string]EvalExpectation{}
+	for key, v := range byEntity {
+		best := ""
+		for _, label := range v.order {
+			if best == "" || v.sentiment[label] > v.sentiment[best] {
+				best = label
+			}
+		}
+		v.prediction.Sentiment = best
+		predicted[key] = v.prediction
+	}
+	return predicted
+}
+
+// evalKey identifies an entity within a case
+func evalKey(entityType, name string) string {
+	return entityType + ":" + strings.ToLower(name)
+}
+
+// unionKeys returns the keys of both maps in a stable order
+func unionKeys(a, b map[string]EvalExpectation) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]EvalExpectation{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// presence labels whether an entity was detected/expected
+func presence(found bool) string {
+	if found {
+		return "present"
+	}
+	return "absent"
+}
+
+// ratio divides safely, returning 0 for an empty denominator
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
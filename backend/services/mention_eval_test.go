@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+)
+
+// evalTolerance absorbs rounding in the stored baseline
+const evalTolerance = 0.001
+
+// TestMentionDetectionEval gates detector changes on the labelled corpus: a change that
+// lowers any field's F1 below testdata/mention_eval/baseline.json fails the build.
+// Raise the baseline when a change improves the scores.
+func TestMentionDetectionEval(t *testing.T) {
+	cases, err := LoadEvalCorpus("testdata/mention_eval/corpus.json")
+	if err != nil {
+		t.Fatalf("load corpus: %v", err)
+	}
+	baseline, err := LoadEvalBaseline("testdata/mention_eval/baseline.json")
+	if err != nil {
+		t.Fatalf("load baseline: %v", err)
+	}
+
+	report := EvaluateMentionDetector(cases)
+	t.Log("\n" + report.Summary())
+	for _, e := range report.Errors {
+		t.Logf("%s: %s %s expected=%s got=%s", e.CaseID, e.Field, e.Entity, e.Expected, e.Got)
+	}
+
+	for _, failure := range report.Regressions(baseline, evalTolerance) {
+		t.Error(failure)
+	}
+}
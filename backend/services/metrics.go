@@ -3,26 +3,29 @@ package services
 import (
 	"log"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/Sneh16Shah/ai-visibility-tracker/ai"
 	"github.com/Sneh16Shah/ai-visibility-tracker/db"
 	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
 )
 
-// Score weights as per specification
+// Default score weights as per specification (brands can override them with a scoring profile)
 const (
-	WeightMentionRate = 0.40 // 40%
-	WeightPosition    = 0.25 // 25%
-	WeightRecommend   = 0.20 // 20%
-	WeightSentiment   = 0.15 // 15%
+	WeightMentionRate = scoring.WeightMentionRate
+	WeightPosition    = scoring.WeightPosition
+	WeightRecommend   = scoring.WeightRecommend
+	WeightSentiment   = scoring.WeightSentiment
 )
 
-// Position weights for mentions within a response
+// Default position weights for mentions within a response
 const (
-	PositionFirst  = 1.0
-	PositionSecond = 0.7
-	PositionLater  = 0.4
+	PositionFirst  = scoring.PositionFirst
+	PositionSecond = scoring.PositionSecond
+	PositionLater  = scoring.PositionLater
 )
 
 // MetricsCalculator handles all metrics calculations
@@ -35,198 +38,160 @@ func NewMetricsCalculator() *MetricsCalculator {
 
 // CalculateAndStoreMetrics calculates all metrics for a brand and stores a snapshot
 func (m *MetricsCalculator) CalculateAndStoreMetrics(brandID int) (*models.MetricSnapshot, error) {
-	// Get only the latest run AI responses for this brand (not historical)
-	responseRepo := db.NewAIResponseRepository()
-	responses, err := responseRepo.GetLatestRunByBrandID(brandID)
+	profile := loadScoringProfile(brandID)
+	snapshot, stats, err := m.calculateSnapshot(brandID, profile)
 	if err != nil {
 		return nil, err
 	}
-
-	totalResponses := len(responses)
-	if totalResponses == 0 {
+	if snapshot == nil {
 		// Return empty snapshot if no responses
 		return m.createEmptySnapshot(brandID)
 	}
 
-	// Aggregate mention data across all responses
-	mentionRepo := db.NewMentionRepository()
+	// Confidence Score (based on historical variance)
+	snapshot.ConfidenceScore, snapshot.ConfidenceLevel = m.calculateConfidenceScore(brandID)
 
-	var totalMentions int
-	var brandMentions int
-	var positiveCount int
-	var neutralCount int
-	var negativeCount int
-	var responsesWithBrand int
-	var responsesWithRecommendation int
-	var totalPositionScore float64
-	var brandSentimentSum float64
-	var categorySentimentSum float64
-	var categoryMentionCount int
+	log.Printf("📊 Composite Score for brand %d: %.1f (profile v%d, MentionRate=%.2f, Position=%.2f, Recommend=%.2f, Sentiment=%.2f)",
+		brandID, snapshot.VisibilityScore, profile.Version, snapshot.NormalizedMentionRate, snapshot.WeightedPositionScore, snapshot.RecommendationRate, snapshot.RelativeSentimentIndex)
 
-	for _, response := range responses {
-		mentions, err := mentionRepo.GetByResponseID(response.ID)
-		if err != nil {
-			continue
-		}
-
-		hasBrand := false
-		hasRecommendation := false
-		brandMentionInResponse := 0
-
-		for _, mention := range mentions {
-			totalMentions++
-
-			// Calculate sentiment score (1=negative, 3=neutral, 5=positive)
-			sentimentValue := 3.0
-			switch mention.Sentiment {
-			case "positive":
-				sentimentValue = 5.0
-			case "negative":
-				sentimentValue = 1.0
-			}
-
-			if mention.EntityType == "brand" {
-				brandMentions++
-				hasBrand = true
-				brandMentionInResponse++
-
-				// Count sentiment for brand mentions only
-				switch mention.Sentiment {
-				case "positive":
-					positiveCount++
-				case "negative":
-					negativeCount++
-				default:
-					neutralCount++
-				}
-
-				// Calculate position weight based on PositionRank
-				switch mention.PositionRank {
-				case 1:
-					totalPositionScore += PositionFirst // 1.0
-				case 2:
-					totalPositionScore += PositionSecond // 0.7
-				default:
-					totalPositionScore += PositionLater // 0.4
-				}
-
-				// Check for recommendation
-				if mention.IsRecommendation {
-					hasRecommendation = true
-				}
-
-				// Track brand sentiment
-				brandSentimentSum += sentimentValue
-			} else {
-				// Competitor mention - contributes to category average
-				categorySentimentSum += sentimentValue
-				categoryMentionCount++
-			}
-		}
-
-		if hasBrand {
-			responsesWithBrand++
-		}
-		if hasRecommendation {
-			responsesWithRecommendation++
-		}
+	// Store snapshot
+	metricRepo := db.NewMetricRepository()
+	storedSnapshot, err := metricRepo.Create(snapshot)
+	if err != nil {
+		return nil, err
 	}
 
-	// 1. Normalized Mention Rate (0-1): responses with brand / total responses
-	normalizedMentionRate := 0.0
-	if totalResponses > 0 {
-		normalizedMentionRate = float64(responsesWithBrand) / float64(totalResponses)
+	// Per-product and per-competitor snapshots sit next to the brand-level snapshot
+	if err := m.storeProductSnapshots(brandID, stats.products, profile, snapshot.ResponseCount, snapshot.CategoryAvgSentiment, snapshot.SnapshotDate); err != nil {
+		log.Printf("Warning: failed to store product snapshots for brand %d: %v", brandID, err)
 	}
-
-	// 2. Weighted Position Score (0-1): normalize position scores
-	// Max possible = 1.0 per response, normalize to 0-1
-	weightedPositionScore := 0.0
-	if totalResponses > 0 {
-		weightedPositionScore = totalPositionScore / float64(totalResponses)
-		// Clamp to 0-1 (can exceed 1 if multiple brand mentions)
-		if weightedPositionScore > 1.0 {
-			weightedPositionScore = 1.0
-		}
+	if err := m.storeCompetitorSnapshots(brandID, storedSnapshot.ID, stats.competitors, profile, snapshot.ResponseCount, snapshot.SnapshotDate); err != nil {
+		log.Printf("Warning: failed to store competitor snapshots for brand %d: %v", brandID, err)
+	}
+	if err := m.storeModelSnapshots(brandID, storedSnapshot.ID, stats.models, profile, snapshot.SnapshotDate); err != nil {
+		log.Printf("Warning: failed to store model snapshots for brand %d: %v", brandID, err)
+	}
+	if err := m.storeCategorySnapshots(brandID, storedSnapshot.ID, stats.categories, profile, snapshot.SnapshotDate); err != nil {
+		log.Printf("Warning: failed to store category snapshots for brand %d: %v", brandID, err)
+	}
+	if err := m.storePromptSnapshots(brandID, storedSnapshot.ID, stats.prompts, snapshot.SnapshotDate); err != nil {
+		log.Printf("Warning: failed to store prompt snapshots for brand %d: %v", brandID, err)
 	}
 
-	// 3. Recommendation Rate (0-1): responses with explicit recommendation / total
-	recommendationRate := 0.0
-	if totalResponses > 0 {
-		recommendationRate = float64(responsesWithRecommendation) / float64(totalResponses)
+	// Flag unusual moves now that the run's series are complete
+	if _, err := NewAnomalyDetector().Detect(brandID, storedSnapshot); err != nil {
+		log.Printf("Warning: anomaly detection failed for brand %d: %v", brandID, err)
 	}
 
-	// 4. Relative Sentiment Index (0-1)
-	// Brand sentiment vs category average, normalized to 0-1
-	brandAvgSentiment := 3.0 // Default neutral
-	if brandMentions > 0 {
-		brandAvgSentiment = brandSentimentSum / float64(brandMentions)
+	return storedSnapshot, nil
+}
+
+// runStats holds the per-product, per-competitor, per-model, per-category and per-prompt accumulators of a run
+type runStats struct {
+	products    productStats
+	competitors *competitorStats
+	models      segmentRuns
+	categories  segmentRuns
+	prompts     promptRuns
+}
+
+// calculateSnapshot computes the composite score from the brand's latest run under a scoring profile.
+// It returns a nil snapshot when there are no responses; confidence is left to the caller.
+func (m *MetricsCalculator) calculateSnapshot(brandID int, profile *models.ScoringProfile) (*models.MetricSnapshot, *runStats, error) {
+	// Get only the latest run AI responses for this brand (not historical)
+	responseRepo := db.NewAIResponseRepository()
+	responses, err := responseRepo.GetLatestRunByBrandID(brandID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	categoryAvgSentiment := 3.0 // Default neutral
-	if categoryMentionCount > 0 {
-		categoryAvgSentiment = categorySentimentSum / float64(categoryMentionCount)
+	totalResponses := len(responses)
+	if totalResponses == 0 {
+		return nil, nil, nil
 	}
 
-	// Calculate relative sentiment: difference ranges from -4 to +4
-	// Normalize to 0-1: (diff + 4) / 8
-	relativeDiff := brandAvgSentiment - categoryAvgSentiment
-	relativeSentimentIndex := (relativeDiff + 4.0) / 8.0
-	if relativeSentimentIndex < 0 {
-		relativeSentimentIndex = 0
+	// Brand decides whether rule-based or LLM labels drive the score
+	sentimentSource := SentimentSourceRules
+	var products []models.Product
+	var competitors []models.Competitor
+	if brand, err := db.NewBrandRepository().GetByID(brandID); err == nil {
+		sentimentSource = brand.SentimentSource
+		products = brand.Products
+		competitors = brand.Competitors
 	}
-	if relativeSentimentIndex > 1 {
-		relativeSentimentIndex = 1
+	stats := &runStats{
+		products:    newProductStats(products),
+		competitors: newCompetitorStats(competitors),
+		models:      segmentRuns{},
+		categories:  segmentRuns{},
+		prompts:     promptRuns{},
 	}
 
-	// 5. Composite Visibility Score (0-100)
-	visibilityScore := (WeightMentionRate*normalizedMentionRate +
-		WeightPosition*weightedPositionScore +
-		WeightRecommend*recommendationRate +
-		WeightSentiment*relativeSentimentIndex) * 100
+	// Prompt categories of the run's responses (inactive prompts included)
+	promptCategories, err := db.NewPromptRepository().GetCategories()
+	if err != nil {
+		log.Printf("Warning: failed to load prompt categories for brand %d: %v", brandID, err)
+	}
 
-	// 6. Citation/Response Share (percentage of responses mentioning brand)
-	citationShare := normalizedMentionRate * 100
+	// Aggregate mention data across all responses
+	mentionRepo := db.NewMentionRepository()
+	run := scoring.NewRun(profile, sentimentSource)
 
-	// 7. Confidence Score (based on historical variance)
-	confidenceScore, confidenceLevel := m.calculateConfidenceScore(brandID)
+	for _, response := range responses {
+		modelName := response.ModelName
+		if modelName == "" {
+			modelName = unknownModel
+		}
+		category := promptCategories[response.PromptID]
+		if category == "" {
+			category = uncategorizedPrompts
+		}
+
+		mentions, err := mentionRepo.GetByResponseID(response.ID)
+		if err != nil {
+			// Still counts towards the run, as a response without mentions
+			mentions = nil
+		}
+
+		run.AddResponse(mentions)
+		stats.models.addResponse(modelName, mentions, profile, sentimentSource)
+		stats.categories.addResponse(category, mentions, profile, sentimentSource)
+		stats.prompts.addResponse(response.PromptID, category, mentions, profile, sentimentSource)
+		stats.products.addResponse(mentions, sentimentSource, profile)
+		stats.competitors.addResponse(mentions, sentimentSource, profile)
+	}
+
+	components := run.Components()
+	rankFirst, rankSecond := run.RankFirst, run.RankSecond
 
 	// Create snapshot with all component scores
 	snapshot := &models.MetricSnapshot{
 		BrandID:         brandID,
-		VisibilityScore: visibilityScore,
-		CitationShare:   citationShare,
-		MentionCount:    brandMentions,
-		PositiveCount:   positiveCount,
-		NeutralCount:    neutralCount,
-		NegativeCount:   negativeCount,
+		VisibilityScore: scoring.Score(profile, components),
+		CitationShare:   components.MentionRate * 100, // Percentage of responses mentioning the brand
+		MentionCount:    run.Brand.Mentions,
+		PositiveCount:   run.Brand.Positive,
+		NeutralCount:    run.Brand.Neutral,
+		NegativeCount:   run.Brand.Negative,
 		SnapshotDate:    time.Now(),
 
 		// Component scores (0-1)
-		NormalizedMentionRate:  normalizedMentionRate,
-		WeightedPositionScore:  weightedPositionScore,
-		RecommendationRate:     recommendationRate,
-		RelativeSentimentIndex: relativeSentimentIndex,
+		NormalizedMentionRate:  components.MentionRate,
+		WeightedPositionScore:  components.PositionScore,
+		RecommendationRate:     components.RecommendationRate,
+		RelativeSentimentIndex: components.RelativeSentiment,
 
-		// Confidence
-		ConfidenceScore: confidenceScore,
-		ConfidenceLevel: confidenceLevel,
+		// Scoring profile and the rank counts behind the position score
+		ScoringProfileVersion: profile.Version,
+		RankFirstCount:        &rankFirst,
+		RankSecondCount:       &rankSecond,
 
 		// Metadata
-		ResponseCount:        totalResponses,
-		CategoryAvgSentiment: categoryAvgSentiment,
+		ResponseCount:        run.Responses,
+		CategoryAvgSentiment: run.CategoryAvgSentiment(),
 	}
 
-	log.Printf("📊 Composite Score for brand %d: %.1f (MentionRate=%.2f, Position=%.2f, Recommend=%.2f, Sentiment=%.2f)",
-		brandID, visibilityScore, normalizedMentionRate, weightedPositionScore, recommendationRate, relativeSentimentIndex)
-
-	// Store snapshot
-	metricRepo := db.NewMetricRepository()
-	storedSnapshot, err := metricRepo.Create(snapshot)
-	if err != nil {
-		return nil, err
-	}
-
-	return storedSnapshot, nil
+	return snapshot, stats, nil
 }
 
 // createEmptySnapshot creates an empty metric snapshot for brands with no data
@@ -301,8 +266,8 @@ func (m *MetricsCalculator) calculateCitationShare(brandMentions, totalMentions
 	return float64(brandMentions) / float64(totalMentions) * 100
 }
 
-// GetDashboardMetrics returns aggregated metrics for the dashboard
-func (m *MetricsCalculator) GetDashboardMetrics(brandID int) (*models.DashboardData, error) {
+// GetDashboardMetrics returns aggregated metrics for the dashboard, with the trend series selected by q
+func (m *MetricsCalculator) GetDashboardMetrics(brandID int, q models.MetricsQuery) (*models.DashboardData, error) {
 	metricRepo := db.NewMetricRepository()
 	brandRepo := db.NewBrandRepository()
 
@@ -313,8 +278,17 @@ func (m *MetricsCalculator) GetDashboardMetrics(brandID int) (*models.DashboardD
 		return m.getEmptyDashboardData(), nil
 	}
 
-	// Get trends (last 7 days)
-	trends, _ := metricRepo.GetTrendsByBrandID(brandID, 7)
+	trends, err := m.QueryMetrics(brandID, q)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := db.NewAnnotationRepository().GetBetween(brandID, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	if annotations == nil {
+		annotations = []models.Annotation{}
+	}
 
 	// Get brand info for competitor breakdown
 	brand, err := brandRepo.GetByID(brandID)
@@ -322,28 +296,38 @@ func (m *MetricsCalculator) GetDashboardMetrics(brandID int) (*models.DashboardD
 		return nil, err
 	}
 
-	// Calculate citation breakdown
-	citationBreakdown := m.calculateCitationBreakdown(brandID, brand)
-
-	// Calculate competitor comparison
-	competitorData := m.calculateCompetitorMetrics(brandID, brand)
+	// Share of voice and competitor comparison come from the latest run's mentions
+	runMentions := m.latestRunMentions(brandID)
+	citationBreakdown := m.calculateCitationBreakdown(brand, runMentions)
+	competitorData := m.calculateCompetitorMetrics(brand, runMentions)
+	voiceByModel, voiceByCategory := m.calculateShareOfVoiceGroups(brand, runMentions)
 
-	// Calculate per-model visibility
-	modelVisibility := m.calculateModelVisibility(brandID)
+	// Per-model visibility in the latest run
+	modelVisibility := m.calculateModelVisibility(latest)
 
 	// Calculate sentiment score (1-5 scale)
 	sentimentScore := m.calculateSentimentScore(latest.PositiveCount, latest.NeutralCount, latest.NegativeCount)
 
+	// Change since the previous snapshot, whatever range the trends cover
+	var scoreChange *models.ScoreChange
+	if recent, err := metricRepo.GetTrendsByBrandID(brandID, 2); err == nil && len(recent) == 2 {
+		scoreChange = snapshotChange(loadScoringProfile(brandID), &recent[1], &recent[0])
+	}
+
 	return &models.DashboardData{
 		VisibilityScore:   latest.VisibilityScore,
 		CitationShare:     latest.CitationShare,
 		TotalMentions:     latest.MentionCount,
 		SentimentScore:    sentimentScore,
 		Trends:            trends,
+		Annotations:       annotations,
 		CitationBreakdown: citationBreakdown,
 		CompetitorData:    competitorData,
 		ModelVisibility:   modelVisibility,
 
+		ShareOfVoiceByModel:    voiceByModel,
+		ShareOfVoiceByCategory: voiceByCategory,
+
 		// Component scores
 		NormalizedMentionRate:  latest.NormalizedMentionRate,
 		WeightedPositionScore:  latest.WeightedPositionScore,
@@ -354,12 +338,70 @@ func (m *MetricsCalculator) GetDashboardMetrics(brandID int) (*models.DashboardD
 		ConfidenceScore: latest.ConfidenceScore,
 		ConfidenceLevel: latest.ConfidenceLevel,
 
+		ScoreChange: scoreChange,
+
 		// Metadata
 		ResponseCount:        latest.ResponseCount,
 		CategoryAvgSentiment: latest.CategoryAvgSentiment,
 	}, nil
 }
 
+// GetAspectSentiment returns daily aspect sentiment per brand/competitor over the last N days
+func (m *MetricsCalculator) GetAspectSentiment(brandID int, days int) ([]models.AspectSentimentPoint, error) {
+	since := time.Now().AddDate(0, 0, -days)
+	points, err := db.NewAspectRepository().GetDailyAggregates(brandID, since)
+	if err != nil {
+		return nil, err
+	}
+	if points == nil {
+		points = []models.AspectSentimentPoint{}
+	}
+	return points, nil
+}
+
+// GetCitedDomains returns the most cited domains overall and per brand/competitor
+func (m *MetricsCalculator) GetCitedDomains(brandID int, days int, limit int) (*models.CitationReport, error) {
+	since := time.Now().AddDate(0, 0, -days)
+	repo := db.NewCitationRepository()
+
+	domains, err := repo.GetTopDomains(brandID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	if domains == nil {
+		domains = []models.CitedDomain{}
+	}
+
+	rows, err := repo.GetDomainsByEntity(brandID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are ordered by entity, then by citation count: keep the first N per entity
+	byEntity := []models.EntityCitedDomains{}
+	for _, row := range rows {
+		last := len(byEntity) - 1
+		if last < 0 || byEntity[last].EntityName != row.EntityName || byEntity[last].EntityType != row.EntityType {
+			byEntity = append(byEntity, models.EntityCitedDomains{
+				EntityName: row.EntityName,
+				EntityType: row.EntityType,
+				Domains:    []models.CitedDomain{},
+			})
+			last++
+		}
+		if len(byEntity[last].Domains) < limit {
+			byEntity[last].Domains = append(byEntity[last].Domains, row)
+		}
+	}
+
+	return &models.CitationReport{
+		BrandID:  brandID,
+		Days:     days,
+		Domains:  domains,
+		ByEntity: byEntity,
+	}, nil
+}
+
 // calculateSentimentScore converts counts to 1-5 scale
 func (m *MetricsCalculator) calculateSentimentScore(positive, neutral, negative int) float64 {
 	total := positive + neutral + negative
@@ -372,168 +414,217 @@ func (m *MetricsCalculator) calculateSentimentScore(positive, neutral, negative
 	return score
 }
 
-// calculateCitationBreakdown calculates share for each entity
-func (m *MetricsCalculator) calculateCitationBreakdown(brandID int, brand *models.Brand) []models.CitationBreakdown {
-	// TODO: Get actual mention counts from database
-	// For now, return placeholder data
-	breakdown := []models.CitationBreakdown{
-		{Name: brand.Name, Value: 35, Color: "#6366f1"},
+// Share of voice colors: the brand first, then competitors in tracked order (cycled)
+const brandVoiceColor = "#6366f1"
+
+var competitorVoiceColors = []string{"#10b981", "#f59e0b", "#ef4444", "#8b5cf6", "#06b6d4", "#ec4899"}
+
+// entityVoice counts one tracked entity's mentions
+type entityVoice struct {
+	name     string
+	mentions int
+	positive int
+	neutral  int
+	negative int
+}
+
+// voiceTally counts mentions per tracked entity: the brand (aliases and products
+// included) followed by its competitors. Untracked names are ignored.
+type voiceTally struct {
+	entities []*entityVoice
+	index    map[string]*entityVoice
+	total    int
+}
+
+func newVoiceTally(brand *models.Brand) *voiceTally {
+	t := &voiceTally{index: map[string]*entityVoice{}}
+	brandVoice := &entityVoice{name: brand.Name}
+	t.entities = append(t.entities, brandVoice)
+	t.index["brand"] = brandVoice
+	for _, comp := range brand.Competitors {
+		key := "competitor:" + strings.ToLower(comp.Name)
+		if _, ok := t.index[key]; ok {
+			continue
+		}
+		v := &entityVoice{name: comp.Name}
+		t.entities = append(t.entities, v)
+		t.index[key] = v
+	}
+	return t
+}
+
+// add counts a mention under its entity with the sentiment that drives the score
+func (t *voiceTally) add(mention models.Mention, sentimentSource string) {
+	key := "brand"
+	if mention.EntityType != "brand" {
+		key = "competitor:" + strings.ToLower(mention.EntityName)
+	}
+	v, ok := t.index[key]
+	if !ok {
+		return
+	}
+
+	sentiment, _ := scoring.EffectiveLabels(mention, sentimentSource)
+	v.mentions++
+	t.total++
+	switch sentiment {
+	case "positive":
+		v.positive++
+	case "negative":
+		v.negative++
+	default:
+		v.neutral++
 	}
+}
 
-	colors := []string{"#10b981", "#f59e0b", "#ef4444", "#8b5cf6"}
-	for i, comp := range brand.Competitors {
-		if i >= len(colors) {
-			break
+// breakdown returns each entity's share of the tracked mentions (0-100)
+func (t *voiceTally) breakdown() []models.CitationBreakdown {
+	breakdown := []models.CitationBreakdown{}
+	for i, v := range t.entities {
+		color := brandVoiceColor
+		if i > 0 {
+			color = competitorVoiceColors[(i-1)%len(competitorVoiceColors)]
+		}
+		share := 0.0
+		if t.total > 0 {
+			share = math.Round(float64(v.mentions)/float64(t.total)*1000) / 10
 		}
 		breakdown = append(breakdown, models.CitationBreakdown{
-			Name:  comp.Name,
-			Value: float64(25 - i*5), // Descending values
-			Color: colors[i],
+			Name:     v.name,
+			Value:    share,
+			Color:    color,
+			Mentions: v.mentions,
 		})
 	}
-
 	return breakdown
 }
 
-// calculateCompetitorMetrics calculates metrics for each competitor
-func (m *MetricsCalculator) calculateCompetitorMetrics(brandID int, brand *models.Brand) []models.CompetitorMetrics {
-	// TODO: Get actual competitor metrics from database
-	// For now, return placeholder data
-	metrics := []models.CompetitorMetrics{
-		{Name: brand.Name, Mentions: 35, Positive: 28, Neutral: 5, Negative: 2},
+// latestRunMentions loads the mentions of the brand's latest run, the same responses
+// the dashboard's composite score is calculated from
+func (m *MetricsCalculator) latestRunMentions(brandID int) []db.ContextMention {
+	responses, err := db.NewAIResponseRepository().GetLatestRunByBrandID(brandID)
+	if err != nil || len(responses) == 0 {
+		return nil
 	}
-
-	for i, comp := range brand.Competitors {
-		mentions := 28 - (i * 5)
-		if mentions < 10 {
-			mentions = 10
+	since := responses[0].CreatedAt
+	for _, r := range responses {
+		if r.CreatedAt.Before(since) {
+			since = r.CreatedAt
 		}
-		metrics = append(metrics, models.CompetitorMetrics{
-			Name:     comp.Name,
-			Mentions: mentions,
-			Positive: int(float64(mentions) * 0.7),
-			Neutral:  int(float64(mentions) * 0.2),
-			Negative: int(float64(mentions) * 0.1),
-		})
 	}
 
-	return metrics
-}
-
-// Model color mapping for known AI models
-var modelColors = map[string]string{
-	// OpenRouter models
-	"Gemma 3 27B":      "#4285f4",
-	"Llama 3.3 70B":    "#0668e1",
-	"Qwen3 Coder":      "#6366f1",
-	"DeepSeek Chimera": "#00d4aa",
-	// Groq
-	"Groq Llama 3.3": "#f55036",
-	// Legacy/other models
-	"gpt-4":       "#10a37f",
-	"gpt-4-turbo": "#10a37f",
-	"claude-3":    "#d4a574",
-	"gemini-pro":  "#4285f4",
+	mentions, err := db.NewMentionRepository().GetByBrandIDSince(brandID, since)
+	if err != nil {
+		log.Printf("Warning: failed to load mentions for brand %d: %v", brandID, err)
+		return nil
+	}
+	return mentions
 }
 
-// calculateModelVisibility calculates visibility scores per AI model
-func (m *MetricsCalculator) calculateModelVisibility(brandID int) []models.ModelVisibility {
-	responseRepo := db.NewAIResponseRepository()
-	mentionRepo := db.NewMentionRepository()
-
-	// Get all responses for this brand
-	responses, err := responseRepo.GetByBrandID(brandID)
-	if err != nil || len(responses) == 0 {
-		return []models.ModelVisibility{}
+// calculateCitationBreakdown calculates each tracked entity's share of voice
+func (m *MetricsCalculator) calculateCitationBreakdown(brand *models.Brand, mentions []db.ContextMention) []models.CitationBreakdown {
+	tally := newVoiceTally(brand)
+	for _, cm := range mentions {
+		tally.add(cm.Mention, brand.SentimentSource)
 	}
+	return tally.breakdown()
+}
 
-	// Group responses by model and calculate average scores
-	type modelData struct {
-		totalScore    int // Sum of all response scores
-		responseCount int // Number of responses
-		mentions      int // Total brand mentions
+// calculateCompetitorMetrics counts mentions and sentiment for the brand and each competitor
+func (m *MetricsCalculator) calculateCompetitorMetrics(brand *models.Brand, mentions []db.ContextMention) []models.CompetitorMetrics {
+	tally := newVoiceTally(brand)
+	for _, cm := range mentions {
+		tally.add(cm.Mention, brand.SentimentSource)
 	}
-	modelStats := make(map[string]*modelData)
 
-	// Get brand info for score calculation
-	brandRepo := db.NewBrandRepository()
-	brand, err := brandRepo.GetByID(brandID)
-	if err != nil {
-		log.Printf("calculateModelVisibility: could not get brand: %v", err)
-		return []models.ModelVisibility{}
+	metrics := []models.CompetitorMetrics{}
+	for _, v := range tally.entities {
+		metrics = append(metrics, models.CompetitorMetrics{
+			Name:     v.name,
+			Mentions: v.mentions,
+			Positive: v.positive,
+			Neutral:  v.neutral,
+			Negative: v.negative,
+		})
 	}
+	return metrics
+}
 
-	for _, resp := range responses {
-		modelName := resp.ModelName
-		if modelName == "" {
-			modelName = "Unknown"
+// calculateShareOfVoiceGroups splits share of voice by AI model and by prompt category
+func (m *MetricsCalculator) calculateShareOfVoiceGroups(brand *models.Brand, mentions []db.ContextMention) ([]models.ShareOfVoiceGroup, []models.ShareOfVoiceGroup) {
+	byModel := map[string]*voiceTally{}
+	byCategory := map[string]*voiceTally{}
+	tallyFor := func(groups map[string]*voiceTally, group string) *voiceTally {
+		t, ok := groups[group]
+		if !ok {
+			t = newVoiceTally(brand)
+			groups[group] = t
 		}
+		return t
+	}
 
-		if modelStats[modelName] == nil {
-			modelStats[modelName] = &modelData{}
+	for _, cm := range mentions {
+		model := cm.ModelName
+		if model == "" {
+			model = "Unknown"
 		}
-
-		// Get mentions for this response
-		mentions, err := mentionRepo.GetByResponseID(resp.ID)
-		if err != nil {
-			// Still count the response but with score 0
-			modelStats[modelName].responseCount++
-			continue
+		category := cm.PromptCategory
+		if category == "" {
+			category = "Uncategorized"
 		}
+		tallyFor(byModel, model).add(cm.Mention, brand.SentimentSource)
+		tallyFor(byCategory, category).add(cm.Mention, brand.SentimentSource)
+	}
 
-		// Calculate score for THIS response using same logic as compare.go
-		score := calculateResponseScore(mentions, brand.Name)
-		modelStats[modelName].totalScore += score
-		modelStats[modelName].responseCount++
+	return voiceGroups(byModel), voiceGroups(byCategory)
+}
 
-		// Count brand mentions
-		for _, mention := range mentions {
-			if mention.EntityType == "brand" {
-				modelStats[modelName].mentions++
-			}
+// voiceGroups converts grouped tallies into groups sorted by name, skipping empty ones
+func voiceGroups(groups map[string]*voiceTally) []models.ShareOfVoiceGroup {
+	names := make([]string, 0, len(groups))
+	for name, t := range groups {
+		if t.total > 0 {
+			names = append(names, name)
 		}
 	}
+	sort.Strings(names)
 
-	// Convert to ModelVisibility slice with averaged scores
-	var result []models.ModelVisibility
-	for modelName, stats := range modelStats {
-		if stats.responseCount == 0 {
-			continue
-		}
-
-		// Calculate average score
-		avgScore := float64(stats.totalScore) / float64(stats.responseCount)
-
-		// Get color for this model
-		color := "#888888" // Default gray
-		for key, c := range modelColors {
-			if strings.EqualFold(modelName, key) || strings.Contains(strings.ToLower(modelName), strings.ToLower(key)) {
-				color = c
-				break
-			}
-		}
+	result := []models.ShareOfVoiceGroup{}
+	for _, name := range names {
+		t := groups[name]
+		result = append(result, models.ShareOfVoiceGroup{
+			Group:         name,
+			TotalMentions: t.total,
+			Breakdown:     t.breakdown(),
+		})
+	}
+	return result
+}
 
-		log.Printf("calculateModelVisibility: model=%s, responses=%d, totalScore=%d, avgScore=%.1f",
-			modelName, stats.responseCount, stats.totalScore, avgScore)
+// calculateModelVisibility returns each AI model's score in the brand's latest run, from the
+// model snapshots stored with it. Runs stored before model snapshots existed have none.
+func (m *MetricsCalculator) calculateModelVisibility(latest *models.MetricSnapshot) []models.ModelVisibility {
+	snapshots, err := db.NewModelSnapshotRepository().GetByMetricSnapshotID(latest.ID)
+	if err != nil {
+		log.Printf("calculateModelVisibility: could not load model snapshots for snapshot %d: %v", latest.ID, err)
+		return []models.ModelVisibility{}
+	}
 
+	result := []models.ModelVisibility{}
+	for _, s := range snapshots {
+		info := ai.LookupModel(s.ModelName)
 		result = append(result, models.ModelVisibility{
-			Model:    modelName,
-			ModelID:  modelName,
-			Color:    color,
-			Score:    avgScore,
-			Mentions: stats.mentions,
+			Model:     info.Name,
+			ModelID:   s.ModelName,
+			Color:     info.Color,
+			Score:     s.VisibilityScore,
+			Mentions:  s.MentionCount,
+			Responses: s.ResponseCount,
 		})
 	}
-
-	log.Printf("calculateModelVisibility: returning %d model visibility entries", len(result))
 	return result
 }
 
-// contains checks if s contains substr (case insensitive)
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && len(substr) > 0)
-}
-
 // getEmptyDashboardData returns empty dashboard data
 func (m *MetricsCalculator) getEmptyDashboardData() *models.DashboardData {
 	return &models.DashboardData{
@@ -541,53 +632,13 @@ func (m *MetricsCalculator) getEmptyDashboardData() *models.DashboardData {
 		CitationShare:     0,
 		TotalMentions:     0,
 		SentimentScore:    3.0,
-		Trends:            []models.MetricSnapshot{},
+		Trends:            []models.MetricPoint{},
+		Annotations:       []models.Annotation{},
 		CitationBreakdown: []models.CitationBreakdown{},
 		CompetitorData:    []models.CompetitorMetrics{},
 		ModelVisibility:   []models.ModelVisibility{},
-	}
-}
-
-// calculateResponseScore calculates the visibility score for a single response
-// This uses the same logic as calculateVisibilityScore in compare.go
-func calculateResponseScore(mentions []models.Mention, brandName string) int {
-	var brandMention *models.Mention
-	competitorCount := 0
-
-	for i := range mentions {
-		if mentions[i].EntityType == "brand" {
-			brandMention = &mentions[i]
-		} else if mentions[i].EntityType == "competitor" {
-			competitorCount++
-		}
-	}
-
-	if brandMention == nil {
-		return 0
-	}
-
-	// Base score: 50 for being mentioned
-	score := 50
 
-	// Sentiment bonus
-	switch brandMention.Sentiment {
-	case "positive":
-		score += 25
-	case "negative":
-		score -= 25
+		ShareOfVoiceByModel:    []models.ShareOfVoiceGroup{},
+		ShareOfVoiceByCategory: []models.ShareOfVoiceGroup{},
 	}
-
-	// Citation share bonus
-	totalMentions := 1 + competitorCount
-	citationShare := (1.0 / float64(totalMentions)) * 25
-	score += int(citationShare)
-
-	if score > 100 {
-		score = 100
-	}
-	if score < 0 {
-		score = 0
-	}
-
-	return score
 }
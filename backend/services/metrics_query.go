@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// ErrInvalidMetricsQuery wraps every metrics query validation failure
+var ErrInvalidMetricsQuery = errors.New("invalid metrics query")
+
+// NewMetricsQuery returns a query over the last N days, one point per run
+func NewMetricsQuery(days int) models.MetricsQuery {
+	now := time.Now()
+	return models.MetricsQuery{
+		From:        now.AddDate(0, 0, -days),
+		To:          now,
+		Granularity: models.GranularityRun,
+		Aggregation: models.AggregationLast,
+	}
+}
+
+// ValidateMetricsQuery checks the range, granularity, aggregation and filters of a query
+func ValidateMetricsQuery(q models.MetricsQuery) error {
+	if q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidMetricsQuery)
+	}
+	switch q.Granularity {
+	case models.GranularityRun, models.GranularityDay, models.GranularityWeek, models.GranularityMonth:
+	default:
+		return fmt.Errorf("%w: granularity must be run, day, week or month", ErrInvalidMetricsQuery)
+	}
+	switch q.Aggregation {
+	case models.AggregationLast, models.AggregationAvg, models.AggregationMin, models.AggregationMax:
+	default:
+		return fmt.Errorf("%w: aggregation must be last, avg, min or max", ErrInvalidMetricsQuery)
+	}
+	if q.Model != "" && q.Category != "" {
+		return fmt.Errorf("%w: filter by model or by category, not both", ErrInvalidMetricsQuery)
+	}
+	return nil
+}
+
+// QueryMetrics returns a brand's metric series for a query, oldest point first
+func (m *MetricsCalculator) QueryMetrics(brandID int, q models.MetricsQuery) ([]models.MetricPoint, error) {
+	if err := ValidateMetricsQuery(q); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := m.querySnapshots(brandID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []models.MetricPoint{}
+	var bucket []models.MetricSnapshot
+	var bucketStart time.Time
+	for _, s := range snapshots {
+		start := periodStart(s.CreatedAt, q.Granularity)
+		if len(bucket) > 0 && !start.Equal(bucketStart) {
+			points = append(points, aggregatePoint(bucket, bucketStart, q))
+			bucket = nil
+		}
+		bucketStart = start
+		bucket = append(bucket, s)
+	}
+	if len(bucket) > 0 {
+		points = append(points, aggregatePoint(bucket, bucketStart, q))
+	}
+	return points, nil
+}
+
+// querySnapshots loads the series a query filters on, as brand snapshots oldest first.
+// Model and category snapshots only carry the score, components and counts.
+func (m *MetricsCalculator) querySnapshots(brandID int, q models.MetricsQuery) ([]models.MetricSnapshot, error) {
+	switch {
+	case q.Model != "":
+		rows, err := db.NewModelSnapshotRepository().GetBetween(brandID, q.Model, q.From, q.To)
+		if err != nil {
+			return nil, err
+		}
+		snapshots := make([]models.MetricSnapshot, len(rows))
+		for i, r := range rows {
+			snapshots[i] = segmentSnapshot(brandID, r.MetricSnapshotID, r.SnapshotDate, r.VisibilityScore, r.MentionCount, r.ResponseCount, r.ScoringProfileVersion,
+				r.NormalizedMentionRate, r.WeightedPositionScore, r.RecommendationRate, r.RelativeSentimentIndex)
+		}
+		return snapshots, nil
+
+	case q.Category != "":
+		rows, err := db.NewCategorySnapshotRepository().GetBetween(brandID, q.Category, q.From, q.To)
+		if err != nil {
+			return nil, err
+		}
+		snapshots := make([]models.MetricSnapshot, len(rows))
+		for i, r := range rows {
+			snapshots[i] = segmentSnapshot(brandID, r.MetricSnapshotID, r.SnapshotDate, r.VisibilityScore, r.MentionCount, r.ResponseCount, r.ScoringProfileVersion,
+				r.NormalizedMentionRate, r.WeightedPositionScore, r.RecommendationRate, r.RelativeSentimentIndex)
+		}
+		return snapshots, nil
+
+	default:
+		return db.NewMetricRepository().GetBetween(brandID, q.From, q.To)
+	}
+}
+
+// segmentSnapshot presents a model or category snapshot as a brand snapshot. Segment
+// snapshots do not store the mentions of other entities, so citation share is left at 0.
+func segmentSnapshot(brandID, metricSnapshotID int, date time.Time, score float64, mentions, responses, profileVersion int,
+	mentionRate, positionScore, recommendationRate, sentimentIndex float64) models.MetricSnapshot {
+	return models.MetricSnapshot{
+		ID:                     metricSnapshotID,
+		BrandID:                brandID,
+		VisibilityScore:        score,
+		MentionCount:           mentions,
+		SnapshotDate:           date,
+		CreatedAt:              date,
+		NormalizedMentionRate:  mentionRate,
+		WeightedPositionScore:  positionScore,
+		RecommendationRate:     recommendationRate,
+		RelativeSentimentIndex: sentimentIndex,
+		ScoringProfileVersion:  profileVersion,
+		ResponseCount:          responses,
+	}
+}
+
+// periodStart is the start of the bucket a run falls in, in the server's time zone.
+// Weeks start on Monday.
+func periodStart(t time.Time, granularity string) time.Time {
+	t = t.In(time.Local)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	switch granularity {
+	case models.GranularityDay:
+		return day
+	case models.GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	case models.GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+	default:
+		return t
+	}
+}
+
+// aggregatePoint combines the snapshots of one bucket. "last" keeps the latest run as is;
+// avg/min/max apply to every numeric field independently, counts rounded to whole numbers.
+func aggregatePoint(bucket []models.MetricSnapshot, start time.Time, q models.MetricsQuery) models.MetricPoint {
+	point := models.MetricPoint{
+		MetricSnapshot: bucket[len(bucket)-1],
+		PeriodStart:    start,
+		Runs:           len(bucket),
+	}
+	if q.Granularity != models.GranularityRun {
+		point.SnapshotDate = start
+	}
+	if q.Aggregation == models.AggregationLast || len(bucket) == 1 {
+		return point
+	}
+
+	p := &point.MetricSnapshot
+	floats := []func(s *models.MetricSnapshot) *float64{
+		func(s *models.MetricSnapshot) *float64 { return &s.VisibilityScore },
+		func(s *models.MetricSnapshot) *float64 { return &s.CitationShare },
+		func(s *models.MetricSnapshot) *float64 { return &s.NormalizedMentionRate },
+		func(s *models.MetricSnapshot) *float64 { return &s.WeightedPositionScore },
+		func(s *models.MetricSnapshot) *float64 { return &s.RecommendationRate },
+		func(s *models.MetricSnapshot) *float64 { return &s.RelativeSentimentIndex },
+		func(s *models.MetricSnapshot) *float64 { return &s.ConfidenceScore },
+		func(s *models.MetricSnapshot) *float64 { return &s.CategoryAvgSentiment },
+	}
+	for _, field := range floats {
+		values := make([]float64, len(bucket))
+		for i := range bucket {
+			values[i] = *field(&bucket[i])
+		}
+		*field(p) = aggregate(values, q.Aggregation)
+	}
+
+	ints := []func(s *models.MetricSnapshot) *int{
+		func(s *models.MetricSnapshot) *int { return &s.MentionCount },
+		func(s *models.MetricSnapshot) *int { return &s.PositiveCount },
+		func(s *models.MetricSnapshot) *int { return &s.NeutralCount },
+		func(s *models.MetricSnapshot) *int { return &s.NegativeCount },
+		func(s *models.MetricSnapshot) *int { return &s.ResponseCount },
+	}
+	for _, field := range ints {
+		values := make([]float64, len(bucket))
+		for i := range bucket {
+			values[i] = float64(*field(&bucket[i]))
+		}
+		*field(p) = int(math.Round(aggregate(values, q.Aggregation)))
+	}
+
+	// The rank counts belong to a single run
+	p.RankFirstCount, p.RankSecondCount = nil, nil
+	return point
+}
+
+// aggregate combines values with avg, min or max
+func aggregate(values []float64, aggregation string) float64 {
+	result := values[0]
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+		switch aggregation {
+		case models.AggregationMin:
+			result = math.Min(result, v)
+		case models.AggregationMax:
+			result = math.Max(result, v)
+		}
+	}
+	if aggregation == models.AggregationAvg {
+		return sum / float64(len(values))
+	}
+	return result
+}
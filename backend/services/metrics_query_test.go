@@ -0,0 +1,83 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestPeriodStart(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+	}
+
+	cases := []struct {
+		name        string
+		t           time.Time
+		granularity string
+		want        time.Time
+	}{
+		{"day", at(2026, 3, 18, 15, 30), models.GranularityDay, at(2026, 3, 18, 0, 0)},
+		{"run keeps the time", at(2026, 3, 18, 15, 30), models.GranularityRun, at(2026, 3, 18, 15, 30)},
+		{"week from Wednesday", at(2026, 3, 18, 15, 30), models.GranularityWeek, at(2026, 3, 16, 0, 0)},
+		{"week on Monday midnight", at(2026, 3, 16, 0, 0), models.GranularityWeek, at(2026, 3, 16, 0, 0)},
+		{"week on Sunday night", at(2026, 3, 22, 23, 59), models.GranularityWeek, at(2026, 3, 16, 0, 0)},
+		{"week across new year", at(2025, 1, 1, 9, 0), models.GranularityWeek, at(2024, 12, 30, 0, 0)},
+		{"week across month end", at(2026, 3, 1, 12, 0), models.GranularityWeek, at(2026, 2, 23, 0, 0)},
+		{"month last day", at(2026, 1, 31, 23, 59), models.GranularityMonth, at(2026, 1, 1, 0, 0)},
+		{"month first day", at(2026, 2, 1, 0, 0), models.GranularityMonth, at(2026, 2, 1, 0, 0)},
+		{"month leap day", at(2028, 2, 29, 12, 0), models.GranularityMonth, at(2028, 2, 1, 0, 0)},
+	}
+
+	for _, tc := range cases {
+		if got := periodStart(tc.t, tc.granularity); !got.Equal(tc.want) {
+			t.Errorf("%s: periodStart(%s, %s) = %s, want %s", tc.name, tc.t, tc.granularity, got, tc.want)
+		}
+	}
+}
+
+func TestAggregatePoint(t *testing.T) {
+	start := time.Date(2026, 3, 16, 0, 0, 0, 0, time.Local)
+	rank := 2
+	bucket := []models.MetricSnapshot{
+		{VisibilityScore: 40, MentionCount: 3, NormalizedMentionRate: 0.2, SnapshotDate: start.Add(time.Hour)},
+		{VisibilityScore: 70, MentionCount: 4, NormalizedMentionRate: 0.6, SnapshotDate: start.Add(25 * time.Hour)},
+		{VisibilityScore: 55, MentionCount: 4, NormalizedMentionRate: 0.4, SnapshotDate: start.Add(49 * time.Hour), RankFirstCount: &rank},
+	}
+
+	cases := []struct {
+		aggregation  string
+		wantScore    float64
+		wantMentions int
+		wantRate     float64
+	}{
+		{models.AggregationLast, 55, 4, 0.4},
+		{models.AggregationAvg, 55, 4, 0.4}, // 11/3 mentions rounds to 4
+		{models.AggregationMin, 40, 3, 0.2},
+		{models.AggregationMax, 70, 4, 0.6},
+	}
+
+	for _, tc := range cases {
+		q := models.MetricsQuery{Granularity: models.GranularityWeek, Aggregation: tc.aggregation}
+		point := aggregatePoint(bucket, start, q)
+		if point.VisibilityScore != tc.wantScore || point.MentionCount != tc.wantMentions || math.Abs(point.NormalizedMentionRate-tc.wantRate) > 1e-9 {
+			t.Errorf("%s: got score %.2f, mentions %d, rate %.2f; want %.2f, %d, %.2f", tc.aggregation,
+				point.VisibilityScore, point.MentionCount, point.NormalizedMentionRate, tc.wantScore, tc.wantMentions, tc.wantRate)
+		}
+		if point.Runs != 3 || !point.PeriodStart.Equal(start) || !point.SnapshotDate.Equal(start) {
+			t.Errorf("%s: got runs %d, period %s, date %s", tc.aggregation, point.Runs, point.PeriodStart, point.SnapshotDate)
+		}
+		// Rank counts describe one run and only survive "last"
+		if keepsRank := point.RankFirstCount != nil; keepsRank != (tc.aggregation == models.AggregationLast) {
+			t.Errorf("%s: rank first count kept = %v", tc.aggregation, keepsRank)
+		}
+	}
+
+	// Per-run points keep their own date
+	point := aggregatePoint(bucket[:1], bucket[0].SnapshotDate, models.MetricsQuery{Granularity: models.GranularityRun, Aggregation: models.AggregationLast})
+	if !point.SnapshotDate.Equal(bucket[0].SnapshotDate) || point.Runs != 1 {
+		t.Errorf("run point: got date %s, runs %d", point.SnapshotDate, point.Runs)
+	}
+}
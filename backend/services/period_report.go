@@ -0,0 +1,377 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// ErrInvalidReportPeriod wraps every report period validation failure
+var ErrInvalidReportPeriod = errors.New("invalid report period")
+
+// ReportPeriods returns the calendar week, month or quarter containing date and the one
+// before it, in the server's time zone. The current period may still be in progress.
+func ReportPeriods(period string, date time.Time) (current, previous models.ReportPeriod, err error) {
+	var start time.Time
+	var months, days int
+	switch period {
+	case models.PeriodWeek:
+		start, days = periodStart(date, models.GranularityWeek), 7
+	case models.PeriodMonth:
+		start, months = periodStart(date, models.GranularityMonth), 1
+	case models.PeriodQuarter:
+		month := periodStart(date, models.GranularityMonth)
+		offset := (int(month.Month()) - 1) % 3 // Months since the quarter started
+		start, months = month.AddDate(0, -offset, 0), 3
+	default:
+		return current, previous, fmt.Errorf("%w: period must be week, month or quarter", ErrInvalidReportPeriod)
+	}
+
+	previousStart := start.AddDate(0, -months, -days)
+	current = models.ReportPeriod{From: start, To: start.AddDate(0, months, days).Add(-time.Nanosecond)}
+	previous = models.ReportPeriod{From: previousStart, To: start.Add(-time.Nanosecond)}
+	return current, previous, nil
+}
+
+// CustomReportPeriods compares [from, to] with the range of the same length just before it
+func CustomReportPeriods(from, to time.Time) (current, previous models.ReportPeriod, err error) {
+	if !from.Before(to) {
+		return current, previous, fmt.Errorf("%w: from must be before to", ErrInvalidReportPeriod)
+	}
+	length := to.Sub(from)
+	current = models.ReportPeriod{From: from, To: to}
+	previous = models.ReportPeriod{From: from.Add(-length - time.Nanosecond), To: from.Add(-time.Nanosecond)}
+	return current, previous, nil
+}
+
+// GeneratePeriodReport compares a brand's metrics, competitor share of voice and per-model
+// scores between two periods, flagging which changes are statistically significant
+func (m *MetricsCalculator) GeneratePeriodReport(brandID int, period string, current, previous models.ReportPeriod) (*models.PeriodReport, error) {
+	brand, err := db.NewBrandRepository().GetByID(brandID)
+	if err != nil {
+		return nil, err
+	}
+	profile := loadScoringProfile(brandID)
+
+	cur, err := loadPeriodData(brandID, current)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := loadPeriodData(brandID, previous)
+	if err != nil {
+		return nil, err
+	}
+	current.Runs, current.Responses = cur.brand.runs, cur.brand.responses
+	previous.Runs, previous.Responses = prev.brand.runs, prev.brand.responses
+
+	scoreChange := scoring.CompareRuns(profile, prev.brand.sample(), cur.brand.sample())
+	scoreChange.FromDate, scoreChange.ToDate = previous.From, current.From
+
+	report := &models.PeriodReport{
+		BrandID:     brand.ID,
+		BrandName:   brand.Name,
+		Period:      period,
+		Current:     current,
+		Previous:    previous,
+		Metrics:     brandPeriodDeltas(prev, cur, scoreChange),
+		ScoreChange: scoreChange,
+		Competitors: competitorPeriodDeltas(profile, prev, cur),
+		Models:      modelPeriodDeltas(profile, prev, cur),
+		GeneratedAt: time.Now(),
+	}
+	return report, nil
+}
+
+// periodPool pools the runs of a period: rates are means weighted by each run's responses
+// (plain means when no run recorded its response count), counts are totals
+type periodPool struct {
+	runs      int
+	responses int
+	weighted  map[string]float64
+	plain     map[string]float64
+	totals    map[string]int
+}
+
+func newPeriodPool() *periodPool {
+	return &periodPool{weighted: map[string]float64{}, plain: map[string]float64{}, totals: map[string]int{}}
+}
+
+// add pools one run's rates and counts
+func (p *periodPool) add(responses int, rates map[string]float64, counts map[string]int) {
+	p.runs++
+	p.responses += responses
+	for metric, v := range rates {
+		p.weighted[metric] += v * float64(responses)
+		p.plain[metric] += v
+	}
+	for metric, v := range counts {
+		p.totals[metric] += v
+	}
+}
+
+// rate is the pooled value of a rate metric
+func (p *periodPool) rate(metric string) float64 {
+	switch {
+	case p.responses > 0:
+		return p.weighted[metric] / float64(p.responses)
+	case p.runs > 0:
+		return p.plain[metric] / float64(p.runs)
+	default:
+		return 0
+	}
+}
+
+// sample is the pooled period as a significance test input
+func (p *periodPool) sample() scoring.Sample {
+	return scoring.Sample{
+		Score: p.rate("visibility_score"),
+		Components: scoring.Components{
+			MentionRate:        p.rate("normalized_mention_rate"),
+			PositionScore:      p.rate("weighted_position_score"),
+			RecommendationRate: p.rate("recommendation_rate"),
+			RelativeSentiment:  p.rate("relative_sentiment_index"),
+		},
+		Responses: p.responses,
+	}
+}
+
+// addComponents pools the score and components shared by every snapshot kind
+func (p *periodPool) addComponents(responses int, score, mentionRate, positionScore, recommendationRate, sentimentIndex float64, counts map[string]int) {
+	p.add(responses, map[string]float64{
+		"visibility_score":         score,
+		"normalized_mention_rate":  mentionRate,
+		"weighted_position_score":  positionScore,
+		"recommendation_rate":      recommendationRate,
+		"relative_sentiment_index": sentimentIndex,
+	}, counts)
+}
+
+// periodData is a period's pooled brand, competitor and model snapshots
+type periodData struct {
+	brand           *periodPool
+	competitors     map[int]*periodPool
+	competitorNames map[int]string
+	models          map[string]*periodPool
+
+	// Mentions behind share of voice, counted over the runs that stored competitor snapshots
+	// (all runs when none did)
+	brandVoice int
+	totalVoice int
+}
+
+// loadPeriodData loads and pools every snapshot stored within a period
+func loadPeriodData(brandID int, period models.ReportPeriod) (*periodData, error) {
+	snapshots, err := db.NewMetricRepository().GetBetween(brandID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	competitorSnapshots, err := db.NewCompetitorSnapshotRepository().GetBetween(brandID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	modelSnapshots, err := db.NewModelSnapshotRepository().GetAllBetween(brandID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &periodData{
+		brand:           newPeriodPool(),
+		competitors:     map[int]*periodPool{},
+		competitorNames: map[int]string{},
+		models:          map[string]*periodPool{},
+	}
+
+	voiceRuns := map[int]bool{}
+	for _, s := range competitorSnapshots {
+		pool, ok := data.competitors[s.CompetitorID]
+		if !ok {
+			pool = newPeriodPool()
+			data.competitors[s.CompetitorID] = pool
+			data.competitorNames[s.CompetitorID] = s.CompetitorName
+		}
+		pool.addComponents(s.ResponseCount, s.VisibilityScore, s.NormalizedMentionRate, s.WeightedPositionScore,
+			s.RecommendationRate, s.RelativeSentimentIndex, map[string]int{"mention_count": s.MentionCount})
+		data.totalVoice += s.MentionCount
+		voiceRuns[s.MetricSnapshotID] = true
+	}
+
+	for _, s := range snapshots {
+		data.brand.add(s.ResponseCount, map[string]float64{
+			"visibility_score":         s.VisibilityScore,
+			"citation_share":           s.CitationShare,
+			"normalized_mention_rate":  s.NormalizedMentionRate,
+			"weighted_position_score":  s.WeightedPositionScore,
+			"recommendation_rate":      s.RecommendationRate,
+			"relative_sentiment_index": s.RelativeSentimentIndex,
+			"confidence_score":         s.ConfidenceScore,
+			"category_avg_sentiment":   s.CategoryAvgSentiment,
+		}, map[string]int{
+			"mention_count":  s.MentionCount,
+			"positive_count": s.PositiveCount,
+			"neutral_count":  s.NeutralCount,
+			"negative_count": s.NegativeCount,
+			"response_count": s.ResponseCount,
+		})
+		if len(voiceRuns) == 0 || voiceRuns[s.ID] {
+			data.brandVoice += s.MentionCount
+		}
+	}
+	data.totalVoice += data.brandVoice
+
+	for _, s := range modelSnapshots {
+		pool, ok := data.models[s.ModelName]
+		if !ok {
+			pool = newPeriodPool()
+			data.models[s.ModelName] = pool
+		}
+		pool.addComponents(s.ResponseCount, s.VisibilityScore, s.NormalizedMentionRate, s.WeightedPositionScore,
+			s.RecommendationRate, s.RelativeSentimentIndex, map[string]int{"mention_count": s.MentionCount})
+	}
+	return data, nil
+}
+
+// shareOfVoice is the percent of a period's brand and competitor mentions that mentions is
+func (d *periodData) shareOfVoice(mentions int) float64 {
+	if d.totalVoice == 0 {
+		return 0
+	}
+	return float64(mentions) / float64(d.totalVoice) * 100
+}
+
+// brandPeriodDeltas compares every component of the brand's snapshots
+func brandPeriodDeltas(prev, cur *periodData, scoreChange models.ScoreChange) []models.MetricDelta {
+	mentionRateP := scoreChange.MentionRate.PValue
+	recommendationP := scoreChange.RecommendationRate.PValue
+	scoreP := scoreChange.PValue
+	sov := voiceDelta("share_of_voice", prev.brandVoice, prev, cur.brandVoice, cur)
+
+	deltas := []models.MetricDelta{
+		rateDelta("visibility_score", prev.brand, cur.brand, &scoreP),
+		citationShareDelta(prev, cur),
+		sov,
+		rateDelta("normalized_mention_rate", prev.brand, cur.brand, &mentionRateP),
+		rateDelta("weighted_position_score", prev.brand, cur.brand, nil),
+		rateDelta("recommendation_rate", prev.brand, cur.brand, &recommendationP),
+		rateDelta("relative_sentiment_index", prev.brand, cur.brand, nil),
+		rateDelta("confidence_score", prev.brand, cur.brand, nil),
+		rateDelta("category_avg_sentiment", prev.brand, cur.brand, nil),
+	}
+	for _, metric := range []string{"mention_count", "positive_count", "neutral_count", "negative_count", "response_count"} {
+		deltas = append(deltas, metricDelta(metric, float64(prev.brand.totals[metric]), float64(cur.brand.totals[metric]), nil))
+	}
+	return deltas
+}
+
+// competitorPeriodDeltas compares each competitor's share of voice and score, largest current share first
+func competitorPeriodDeltas(profile *models.ScoringProfile, prev, cur *periodData) []models.CompetitorPeriodDelta {
+	names := map[int]string{}
+	for id, name := range prev.competitorNames {
+		names[id] = name
+	}
+	for id, name := range cur.competitorNames {
+		names[id] = name
+	}
+
+	deltas := []models.CompetitorPeriodDelta{}
+	for id, name := range names {
+		from, to := poolOrEmpty(prev.competitors[id]), poolOrEmpty(cur.competitors[id])
+		deltas = append(deltas, models.CompetitorPeriodDelta{
+			CompetitorID:    id,
+			CompetitorName:  name,
+			ShareOfVoice:    voiceDelta("share_of_voice", from.totals["mention_count"], prev, to.totals["mention_count"], cur),
+			VisibilityScore: scoreDelta(profile, from, to),
+		})
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].ShareOfVoice.Current != deltas[j].ShareOfVoice.Current {
+			return deltas[i].ShareOfVoice.Current > deltas[j].ShareOfVoice.Current
+		}
+		return deltas[i].CompetitorName < deltas[j].CompetitorName
+	})
+	return deltas
+}
+
+// modelPeriodDeltas compares the brand's score and mention rate within each AI model, by model name
+func modelPeriodDeltas(profile *models.ScoringProfile, prev, cur *periodData) []models.ModelPeriodDelta {
+	seen := map[string]bool{}
+	var names []string
+	for _, pools := range []map[string]*periodPool{prev.models, cur.models} {
+		for name := range pools {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	deltas := []models.ModelPeriodDelta{}
+	for _, name := range names {
+		from, to := poolOrEmpty(prev.models[name]), poolOrEmpty(cur.models[name])
+		mentionRate := scoring.CompareProportions(from.rate("normalized_mention_rate"), from.responses,
+			to.rate("normalized_mention_rate"), to.responses)
+		deltas = append(deltas, models.ModelPeriodDelta{
+			ModelName:       name,
+			VisibilityScore: scoreDelta(profile, from, to),
+			MentionRate:     rateDelta("normalized_mention_rate", from, to, &mentionRate.PValue),
+		})
+	}
+	return deltas
+}
+
+// poolOrEmpty stands in an empty pool for an entity absent from a period
+func poolOrEmpty(p *periodPool) *periodPool {
+	if p == nil {
+		return newPeriodPool()
+	}
+	return p
+}
+
+// scoreDelta compares two pooled scores with the run significance test
+func scoreDelta(profile *models.ScoringProfile, from, to *periodPool) models.MetricDelta {
+	change := scoring.CompareRuns(profile, from.sample(), to.sample())
+	return rateDelta("visibility_score", from, to, &change.PValue)
+}
+
+// voiceDelta compares a share of voice, tested as a proportion of each period's mentions
+func voiceDelta(metric string, prevMentions int, prev *periodData, curMentions int, cur *periodData) models.MetricDelta {
+	from, to := prev.shareOfVoice(prevMentions), cur.shareOfVoice(curMentions)
+	change := scoring.CompareProportions(from/100, prev.totalVoice, to/100, cur.totalVoice)
+	return metricDelta(metric, from, to, &change.PValue)
+}
+
+// citationShareDelta compares the brand's citation share (brand mentions as a percent of all
+// entity mentions), tested as a proportion of each period's mentions
+func citationShareDelta(prev, cur *periodData) models.MetricDelta {
+	from, to := prev.brand.rate("citation_share"), cur.brand.rate("citation_share")
+	change := scoring.CompareProportions(from/100, prev.totalVoice, to/100, cur.totalVoice)
+	return metricDelta("citation_share", from, to, &change.PValue)
+}
+
+// rateDelta compares a pooled rate metric
+func rateDelta(metric string, from, to *periodPool, pValue *float64) models.MetricDelta {
+	return metricDelta(metric, from.rate(metric), to.rate(metric), pValue)
+}
+
+// metricDelta builds the absolute and relative change of a metric; it is significant when
+// a test was run and its p-value is below the significance level
+func metricDelta(metric string, previous, current float64, pValue *float64) models.MetricDelta {
+	delta := models.MetricDelta{
+		Metric:   metric,
+		Previous: previous,
+		Current:  current,
+		Delta:    current - previous,
+		PValue:   pValue,
+	}
+	if previous != 0 {
+		relative := delta.Delta / previous
+		delta.RelativeDelta = &relative
+	}
+	delta.Significant = pValue != nil && *pValue < scoring.SignificanceLevel
+	return delta
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestReportPeriods(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+	lastNano := func(t time.Time) time.Time { return t.Add(-time.Nanosecond) }
+
+	cases := []struct {
+		name           string
+		period         string
+		date           time.Time
+		curFrom, curTo time.Time // curTo is the start of the next period
+		prevFrom       time.Time
+	}{
+		{"week mid-week", models.PeriodWeek, day(2026, 3, 18).Add(15 * time.Hour), day(2026, 3, 16), day(2026, 3, 23), day(2026, 3, 9)},
+		{"week on Sunday night", models.PeriodWeek, day(2026, 3, 22).Add(23*time.Hour + 59*time.Minute), day(2026, 3, 16), day(2026, 3, 23), day(2026, 3, 9)},
+		{"week across new year", models.PeriodWeek, day(2025, 1, 1), day(2024, 12, 30), day(2025, 1, 6), day(2024, 12, 23)},
+		{"month", models.PeriodMonth, day(2026, 3, 31), day(2026, 3, 1), day(2026, 4, 1), day(2026, 2, 1)},
+		{"month across new year", models.PeriodMonth, day(2026, 1, 15), day(2026, 1, 1), day(2026, 2, 1), day(2025, 12, 1)},
+		{"quarter first day", models.PeriodQuarter, day(2026, 4, 1), day(2026, 4, 1), day(2026, 7, 1), day(2026, 1, 1)},
+		{"quarter last day", models.PeriodQuarter, day(2026, 3, 31).Add(23 * time.Hour), day(2026, 1, 1), day(2026, 4, 1), day(2025, 10, 1)},
+		{"quarter mid", models.PeriodQuarter, day(2026, 8, 15), day(2026, 7, 1), day(2026, 10, 1), day(2026, 4, 1)},
+		{"fourth quarter", models.PeriodQuarter, day(2026, 12, 31), day(2026, 10, 1), day(2027, 1, 1), day(2026, 7, 1)},
+	}
+
+	for _, tc := range cases {
+		current, previous, err := ReportPeriods(tc.period, tc.date)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if !current.From.Equal(tc.curFrom) || !current.To.Equal(lastNano(tc.curTo)) {
+			t.Errorf("%s: current %s - %s, want %s - %s", tc.name, current.From, current.To, tc.curFrom, lastNano(tc.curTo))
+		}
+		if !previous.From.Equal(tc.prevFrom) || !previous.To.Equal(lastNano(tc.curFrom)) {
+			t.Errorf("%s: previous %s - %s, want %s - %s", tc.name, previous.From, previous.To, tc.prevFrom, lastNano(tc.curFrom))
+		}
+	}
+
+	if _, _, err := ReportPeriods("year", day(2026, 3, 18)); !errors.Is(err, ErrInvalidReportPeriod) {
+		t.Errorf("unknown period: got %v, want ErrInvalidReportPeriod", err)
+	}
+}
+
+func TestCitationShareDeltaUsesMentionCounts(t *testing.T) {
+	period := func(share float64, responses, totalVoice int) *periodData {
+		pool := newPeriodPool()
+		pool.add(responses, map[string]float64{"citation_share": share, "normalized_mention_rate": 0.5}, nil)
+		return &periodData{brand: pool, totalVoice: totalVoice}
+	}
+
+	// Same share change, tested on few vs many mentions
+	few := citationShareDelta(period(30, 10, 10), period(50, 10, 10))
+	many := citationShareDelta(period(30, 10, 1000), period(50, 10, 1000))
+	if few.Previous != 30 || few.Current != 50 {
+		t.Fatalf("got %.1f -> %.1f, want 30 -> 50", few.Previous, few.Current)
+	}
+	if few.Significant || !many.Significant {
+		t.Errorf("significant: 10 mentions %v (p=%.3f), 1000 mentions %v (p=%.3f); want false, true",
+			few.Significant, *few.PValue, many.Significant, *many.PValue)
+	}
+}
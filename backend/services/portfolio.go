@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// ErrInvalidPortfolioSort is returned for an unknown portfolio sort field
+var ErrInvalidPortfolioSort = errors.New("invalid portfolio sort")
+
+// Portfolio sort fields
+const (
+	PortfolioSortScore      = "score"
+	PortfolioSortChange     = "change"
+	PortfolioSortName       = "name"
+	PortfolioSortConfidence = "confidence"
+	PortfolioSortAlert      = "alert"
+)
+
+// confidenceRank and alertRank order the text fields a portfolio sorts on, lowest first
+var (
+	confidenceRank = map[string]int{"low": 0, "medium": 1, "high": 2}
+	alertRank      = map[string]int{
+		models.AlertStateNoData:         0,
+		models.AlertStateOK:             1,
+		models.AlertStateAnomaly:        2,
+		models.AlertStateBelowThreshold: 3,
+	}
+)
+
+// GetPortfolio returns the latest standing of every brand a user owns, optionally of one
+// industry (case-insensitive), sorted by sortBy. It runs a fixed number of queries
+// however many brands the user has.
+func (m *MetricsCalculator) GetPortfolio(userID int, industry, sortBy string, descending bool) ([]models.PortfolioEntry, error) {
+	less, ok := portfolioOrder[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: sort must be score, change, name, confidence or alert", ErrInvalidPortfolioSort)
+	}
+
+	brands, err := db.NewBrandRepository().GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	metricRepo := db.NewMetricRepository()
+	now := time.Now()
+	latest, err := metricRepo.GetLatestByUserID(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	weekAgo, err := metricRepo.GetLatestByUserID(userID, now.AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+	competitorSnapshots, err := db.NewCompetitorSnapshotRepository().GetLatestRunByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	openAnomalies, err := db.NewAnomalyRepository().CountOpenByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := portfolioEntries(brands, industry, latest, weekAgo, competitorSnapshots, openAnomalies)
+	sortPortfolio(entries, less, descending)
+	return entries, nil
+}
+
+// portfolioEntries builds the entry of every brand of the industry (all brands when empty)
+// from each brand's latest snapshot, its latest snapshot a week ago, the competitor
+// snapshots of its latest run and its open anomaly count
+func portfolioEntries(brands []models.Brand, industry string, latest, weekAgo map[int]models.MetricSnapshot, competitorSnapshots []models.CompetitorSnapshot, openAnomalies map[int]int) []models.PortfolioEntry {
+	// Highest-scoring competitor of each brand's latest run
+	topCompetitor := map[int]models.CompetitorSnapshot{}
+	for _, s := range competitorSnapshots {
+		if top, ok := topCompetitor[s.BrandID]; !ok || s.VisibilityScore > top.VisibilityScore {
+			topCompetitor[s.BrandID] = s
+		}
+	}
+
+	entries := []models.PortfolioEntry{}
+	for _, brand := range brands {
+		if industry != "" && !strings.EqualFold(brand.Industry, industry) {
+			continue
+		}
+		entry := models.PortfolioEntry{
+			BrandID:        brand.ID,
+			BrandName:      brand.Name,
+			Industry:       brand.Industry,
+			AlertThreshold: brand.AlertThreshold,
+			OpenAnomalies:  openAnomalies[brand.ID],
+			AlertState:     models.AlertStateNoData,
+		}
+		if snapshot, ok := latest[brand.ID]; ok {
+			entry.VisibilityScore = snapshot.VisibilityScore
+			entry.ConfidenceLevel = snapshot.ConfidenceLevel
+			lastRun := snapshot.CreatedAt
+			entry.LastRunAt = &lastRun
+			if previous, ok := weekAgo[brand.ID]; ok && previous.ID != snapshot.ID {
+				change := snapshot.VisibilityScore - previous.VisibilityScore
+				entry.WeekChange = &change
+			}
+
+			switch {
+			case brand.AlertThreshold > 0 && snapshot.VisibilityScore < brand.AlertThreshold:
+				entry.AlertState = models.AlertStateBelowThreshold
+			case entry.OpenAnomalies > 0:
+				entry.AlertState = models.AlertStateAnomaly
+			default:
+				entry.AlertState = models.AlertStateOK
+			}
+		}
+		if top, ok := topCompetitor[brand.ID]; ok {
+			entry.TopCompetitor = top.CompetitorName
+			entry.TopCompetitorScore = top.VisibilityScore
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// sortPortfolio orders entries by a portfolioOrder comparison, keeping ties in brand order
+func sortPortfolio(entries []models.PortfolioEntry, less func(a, b models.PortfolioEntry) bool, descending bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if descending {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+}
+
+// portfolioOrder compares two portfolio entries by each sort field, ascending.
+// Brands without a week-over-week change sort below every change.
+var portfolioOrder = map[string]func(a, b models.PortfolioEntry) bool{
+	PortfolioSortScore: func(a, b models.PortfolioEntry) bool {
+		return a.VisibilityScore < b.VisibilityScore
+	},
+	PortfolioSortChange: func(a, b models.PortfolioEntry) bool {
+		if a.WeekChange == nil || b.WeekChange == nil {
+			return a.WeekChange == nil && b.WeekChange != nil
+		}
+		return *a.WeekChange < *b.WeekChange
+	},
+	PortfolioSortName: func(a, b models.PortfolioEntry) bool {
+		return strings.ToLower(a.BrandName) < strings.ToLower(b.BrandName)
+	},
+	PortfolioSortConfidence: func(a, b models.PortfolioEntry) bool {
+		return confidenceRank[a.ConfidenceLevel] < confidenceRank[b.ConfidenceLevel]
+	},
+	PortfolioSortAlert: func(a, b models.PortfolioEntry) bool {
+		return alertRank[a.AlertState] < alertRank[b.AlertState]
+	},
+}
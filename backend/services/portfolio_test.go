@@ -0,0 +1,114 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// testPortfolio is four brands of two industries: Acme is below its threshold, Globex has an
+// open anomaly, Initech is healthy and Hooli was never analyzed
+func testPortfolio(industry string) []models.PortfolioEntry {
+	brands := []models.Brand{
+		{ID: 1, Name: "Acme", Industry: "CRM", AlertThreshold: 50},
+		{ID: 2, Name: "globex", Industry: "crm"},
+		{ID: 3, Name: "Initech", Industry: "Analytics"},
+		{ID: 4, Name: "Hooli", Industry: "CRM"},
+	}
+	latest := map[int]models.MetricSnapshot{
+		1: {ID: 11, VisibilityScore: 40, ConfidenceLevel: "high"},
+		2: {ID: 12, VisibilityScore: 70, ConfidenceLevel: "low"},
+		3: {ID: 13, VisibilityScore: 55, ConfidenceLevel: "medium"},
+	}
+	weekAgo := map[int]models.MetricSnapshot{
+		1: {ID: 1, VisibilityScore: 50},
+		2: {ID: 12, VisibilityScore: 70}, // Same run as the latest: no change
+		3: {ID: 3, VisibilityScore: 50},
+	}
+	competitors := []models.CompetitorSnapshot{
+		{BrandID: 1, CompetitorName: "Rival", VisibilityScore: 30},
+		{BrandID: 1, CompetitorName: "Bigco", VisibilityScore: 60},
+	}
+	return portfolioEntries(brands, industry, latest, weekAgo, competitors, map[int]int{2: 1})
+}
+
+func TestPortfolioEntries(t *testing.T) {
+	entries := testPortfolio("")
+	if len(entries) != 4 {
+		t.Fatalf("len(entries) = %d, want 4", len(entries))
+	}
+
+	acme, globex, initech, hooli := entries[0], entries[1], entries[2], entries[3]
+	if acme.AlertState != models.AlertStateBelowThreshold || acme.WeekChange == nil || *acme.WeekChange != -10 {
+		t.Errorf("Acme = %+v, want below threshold with change -10", acme)
+	}
+	if acme.TopCompetitor != "Bigco" || acme.TopCompetitorScore != 60 {
+		t.Errorf("Acme top competitor = %q %.0f, want Bigco 60", acme.TopCompetitor, acme.TopCompetitorScore)
+	}
+	if globex.AlertState != models.AlertStateAnomaly || globex.WeekChange != nil || globex.OpenAnomalies != 1 {
+		t.Errorf("Globex = %+v, want anomaly without change", globex)
+	}
+	if initech.AlertState != models.AlertStateOK || initech.LastRunAt == nil {
+		t.Errorf("Initech = %+v, want ok with a last run", initech)
+	}
+	if hooli.AlertState != models.AlertStateNoData || hooli.LastRunAt != nil || hooli.WeekChange != nil {
+		t.Errorf("Hooli = %+v, want no data", hooli)
+	}
+}
+
+func TestPortfolioIndustryFilter(t *testing.T) {
+	cases := []struct {
+		industry string
+		want     []string
+	}{
+		{"", []string{"Acme", "globex", "Initech", "Hooli"}},
+		{"CRM", []string{"Acme", "globex", "Hooli"}},
+		{"crm", []string{"Acme", "globex", "Hooli"}},
+		{"analytics", []string{"Initech"}},
+		{"Retail", nil},
+	}
+
+	for _, tc := range cases {
+		var got []string
+		for _, e := range testPortfolio(tc.industry) {
+			got = append(got, e.BrandName)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("industry %q: got %v, want %v", tc.industry, got, tc.want)
+		}
+	}
+}
+
+func TestSortPortfolio(t *testing.T) {
+	cases := []struct {
+		sortBy     string
+		descending bool
+		want       []string
+	}{
+		{PortfolioSortScore, false, []string{"Hooli", "Acme", "Initech", "globex"}},
+		{PortfolioSortScore, true, []string{"globex", "Initech", "Acme", "Hooli"}},
+		{PortfolioSortName, false, []string{"Acme", "globex", "Hooli", "Initech"}},
+		{PortfolioSortName, true, []string{"Initech", "Hooli", "globex", "Acme"}},
+		// Brands without a change sort below every change, in brand order
+		{PortfolioSortChange, false, []string{"globex", "Hooli", "Acme", "Initech"}},
+		{PortfolioSortChange, true, []string{"Initech", "Acme", "globex", "Hooli"}},
+		// Hooli has no confidence level and ranks with "low"
+		{PortfolioSortConfidence, false, []string{"globex", "Hooli", "Initech", "Acme"}},
+		{PortfolioSortAlert, false, []string{"Hooli", "Initech", "globex", "Acme"}},
+		{PortfolioSortAlert, true, []string{"Acme", "globex", "Initech", "Hooli"}},
+	}
+
+	for _, tc := range cases {
+		entries := testPortfolio("")
+		sortPortfolio(entries, portfolioOrder[tc.sortBy], tc.descending)
+
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.BrandName)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("sort %s (descending %v): got %v, want %v", tc.sortBy, tc.descending, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// productStat accumulates one product's mention data across a run's responses
+type productStat struct {
+	product models.Product
+	scoring.Stat
+}
+
+// productStats tracks every product of a brand, keyed by product ID
+type productStats map[int]*productStat
+
+// newProductStats creates empty accumulators for a brand's products
+func newProductStats(products []models.Product) productStats {
+	stats := productStats{}
+	for _, p := range products {
+		stats[p.ID] = &productStat{product: p}
+	}
+	return stats
+}
+
+// addResponse counts the product mentions of one response
+func (s productStats) addResponse(mentions []models.Mention, sentimentSource string, profile *models.ScoringProfile) {
+	tally := scoring.NewRankTally()
+
+	for _, mention := range mentions {
+		stat, ok := s[mention.ProductID]
+		if !ok || mention.EntityType != "brand" {
+			continue
+		}
+		sentiment, isRecommendation := scoring.EffectiveLabels(mention, sentimentSource)
+		stat.AddMention(sentiment)
+		tally.Add(mention.ProductID, mention, isRecommendation)
+	}
+
+	for productID, rank := range tally.BestRank {
+		s[productID].AddResponse(profile, rank, tally.Recommended[productID])
+	}
+}
+
+// productSnapshots scores every product with the brand's profile.
+// Product sentiment is compared with the same category average as the brand.
+func (m *MetricsCalculator) productSnapshots(brandID int, stats productStats, profile *models.ScoringProfile, totalResponses int, categoryAvgSentiment float64, snapshotDate time.Time) []models.ProductSnapshot {
+	if totalResponses == 0 {
+		return nil
+	}
+	var snapshots []models.ProductSnapshot
+	for _, stat := range stats {
+		components := stat.Components(totalResponses, categoryAvgSentiment)
+
+		snapshots = append(snapshots, models.ProductSnapshot{
+			BrandID:                brandID,
+			ProductID:              stat.product.ID,
+			VisibilityScore:        scoring.Score(profile, components),
+			MentionCount:           stat.Mentions,
+			PositiveCount:          stat.Positive,
+			NeutralCount:           stat.Neutral,
+			NegativeCount:          stat.Negative,
+			SnapshotDate:           snapshotDate,
+			NormalizedMentionRate:  components.MentionRate,
+			WeightedPositionScore:  components.PositionScore,
+			RecommendationRate:     components.RecommendationRate,
+			RelativeSentimentIndex: components.RelativeSentiment,
+			ResponseCount:          totalResponses,
+			ScoringProfileVersion:  profile.Version,
+		})
+	}
+	return snapshots
+}
+
+// storeProductSnapshots stores the product snapshots of a run
+func (m *MetricsCalculator) storeProductSnapshots(brandID int, stats productStats, profile *models.ScoringProfile, totalResponses int, categoryAvgSentiment float64, snapshotDate time.Time) error {
+	repo := db.NewProductRepository()
+	snapshots := m.productSnapshots(brandID, stats, profile, totalResponses, categoryAvgSentiment, snapshotDate)
+	for i := range snapshots {
+		if err := repo.CreateSnapshot(&snapshots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProductMetrics returns each product's latest snapshot and its trend over the last N days
+func (m *MetricsCalculator) GetProductMetrics(brandID int, days int) ([]models.ProductMetrics, error) {
+	repo := db.NewProductRepository()
+	products, err := repo.GetByBrandID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := repo.GetSnapshots(brandID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := map[int][]models.ProductSnapshot{}
+	for _, s := range snapshots {
+		byProduct[s.ProductID] = append(byProduct[s.ProductID], s)
+	}
+
+	metrics := []models.ProductMetrics{}
+	for _, p := range products {
+		trends := byProduct[p.ID]
+		if trends == nil {
+			trends = []models.ProductSnapshot{}
+		}
+		pm := models.ProductMetrics{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			Trends:      trends,
+		}
+		if len(trends) > 0 {
+			latest := trends[len(trends)-1]
+			pm.Latest = &latest
+		}
+		metrics = append(metrics, pm)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestDetectMentionsAttributesProducts(t *testing.T) {
+	brand := &models.Brand{
+		Name: "Acme",
+		Products: []models.Product{
+			{ID: 1, Name: "Acme CRM"},
+			{ID: 2, Name: "Acme CRM Pro"},
+			{ID: 3, Name: "Roadrunner", Aliases: []models.ProductAlias{{Alias: "RR Tracker"}}},
+		},
+	}
+
+	cases := []struct {
+		name string
+		text string
+		want []int // Product ID of each brand mention, in detection order
+	}{
+		{"brand only", "Acme is a solid choice.", []int{0}},
+		{"product name", "Acme CRM is a solid choice.", []int{1}},
+		{"longest name wins", "Acme CRM Pro is a solid choice.", []int{2}},
+		{"product without the brand name", "Roadrunner is a solid choice.", []int{3}},
+		{"product alias", "RR Tracker is a solid choice.", []int{3}},
+		{"brand and product apart", "Acme is fine, but Roadrunner is better.", []int{0, 3}},
+	}
+
+	d := NewMentionDetector()
+	for _, tc := range cases {
+		var got []int
+		for _, m := range d.DetectMentions(tc.text, brand) {
+			if m.EntityType == "brand" {
+				got = append(got, m.ProductID)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: product IDs = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestProductSnapshots(t *testing.T) {
+	profile := DefaultScoringProfile(1)
+	stats := newProductStats([]models.Product{{ID: 1, Name: "Acme CRM"}, {ID: 2, Name: "Roadrunner"}})
+
+	responses := [][]models.Mention{
+		{
+			{EntityType: "brand", ProductID: 1, Sentiment: "positive", PositionRank: 1, IsRecommendation: true},
+			{EntityType: "brand", ProductID: 1, Sentiment: "neutral", PositionRank: 3},
+			{EntityType: "brand", Sentiment: "negative"}, // Brand mention without a product
+		},
+		{
+			{EntityType: "brand", ProductID: 1, Sentiment: "negative", PositionRank: 2},
+			{EntityType: "competitor", ProductID: 2, Sentiment: "positive"}, // Only brand mentions carry products
+		},
+		{},
+		{},
+	}
+	for _, mentions := range responses {
+		stats.addResponse(mentions, SentimentSourceRules, profile)
+	}
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	byID := map[int]models.ProductSnapshot{}
+	for _, s := range NewMetricsCalculator().productSnapshots(9, stats, profile, len(responses), 3.0, date) {
+		byID[s.ProductID] = s
+	}
+	if len(byID) != 2 {
+		t.Fatalf("got %d product snapshots, want 2", len(byID))
+	}
+
+	crm := byID[1]
+	if crm.BrandID != 9 || !crm.SnapshotDate.Equal(date) || crm.ResponseCount != 4 {
+		t.Errorf("Acme CRM snapshot = %+v, want brand 9 over 4 responses", crm)
+	}
+	if crm.MentionCount != 3 || crm.PositiveCount != 1 || crm.NeutralCount != 1 || crm.NegativeCount != 1 {
+		t.Errorf("Acme CRM counts = %d (%d/%d/%d), want 3 (1/1/1)", crm.MentionCount, crm.PositiveCount, crm.NeutralCount, crm.NegativeCount)
+	}
+
+	// In 2 of 4 responses, best ranks 1 and 2, recommended once, averaging neutral against a neutral category
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	wantPosition := (profile.PositionFirst + profile.PositionSecond) / 4
+	if !near(crm.NormalizedMentionRate, 0.5) || !near(crm.WeightedPositionScore, wantPosition) ||
+		!near(crm.RecommendationRate, 0.25) || !near(crm.RelativeSentimentIndex, 0.5) {
+		t.Errorf("Acme CRM components = %.4f/%.4f/%.4f/%.4f, want 0.5/%.4f/0.25/0.5",
+			crm.NormalizedMentionRate, crm.WeightedPositionScore, crm.RecommendationRate, crm.RelativeSentimentIndex, wantPosition)
+	}
+	wantScore := (profile.WeightMentionRate*0.5 + profile.WeightPosition*wantPosition +
+		profile.WeightRecommend*0.25 + profile.WeightSentiment*0.5) * 100
+	if !near(crm.VisibilityScore, wantScore) {
+		t.Errorf("Acme CRM score = %v, want %v", crm.VisibilityScore, wantScore)
+	}
+
+	if rr := byID[2]; rr.MentionCount != 0 || rr.VisibilityScore != 0 {
+		t.Errorf("unmentioned Roadrunner = %+v, want no mentions and a zero score", rr)
+	}
+
+	if got := NewMetricsCalculator().productSnapshots(9, stats, profile, 0, 3.0, date); got != nil {
+		t.Errorf("productSnapshots() with no responses = %+v, want nil", got)
+	}
+}
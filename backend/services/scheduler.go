@@ -2,134 +2,424 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Sneh16Shah/ai-visibility-tracker/ai"
+	"github.com/Sneh16Shah/ai-visibility-tracker/config"
 	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
 )
 
-// Scheduler handles scheduled analysis runs
+// Scheduler runs brands' scheduled analyses in the background. Due brands are claimed every
+// tick and each run waits for its brand's jitter delay and one of a bounded number of slots.
 type Scheduler struct {
-	stopChan chan bool
-	running  bool
+	concurrency int
+	maxJitter   time.Duration
+
+	mu             sync.Mutex
+	running        bool
+	cancel         context.CancelFunc
+	done           chan struct{}  // Closed when the dispatch loop exits
+	runs           sync.WaitGroup // Queued and running analyses
+	slots          chan struct{}  // One token per running analysis
+	inFlight       map[int]bool   // Brand IDs queued or running
+	lastAlertCheck time.Time
 }
 
+// schedulerTick is how often due brands are looked up: cron schedules have minute precision
+const schedulerTick = time.Minute
+
+// alertCheckInterval is how often alert emails are checked, whatever the tick
+const alertCheckInterval = time.Hour
+
 // Global scheduler instance
 var scheduler *Scheduler
 
-// InitScheduler initializes and starts the scheduler
-func InitScheduler() *Scheduler {
+// InitScheduler initializes the scheduler from config; Start launches it
+func InitScheduler(cfg *config.Config) *Scheduler {
+	concurrency := cfg.SchedulerConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
 	scheduler = &Scheduler{
-		stopChan: make(chan bool),
-		running:  false,
+		concurrency: concurrency,
+		maxJitter:   cfg.SchedulerMaxJitter,
+		slots:       make(chan struct{}, concurrency),
+		inFlight:    map[int]bool{},
 	}
 	return scheduler
 }
 
-// GetScheduler returns the global scheduler
+// GetScheduler returns the global scheduler (nil when it is disabled)
 func GetScheduler() *Scheduler {
 	return scheduler
 }
 
-// Start begins the scheduler background goroutine
+// Start begins the scheduler background goroutine. Runs a previous process left unfinished
+// are marked failed first.
 func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.running {
 		return
 	}
+
+	if n, err := db.NewScheduledRunRepository().FailInterrupted(); err != nil {
+		log.Printf("Error closing interrupted scheduled runs: %v", err)
+	} else if n > 0 {
+		log.Printf("⏰ Marked %d interrupted scheduled runs as failed", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
 	s.running = true
-	go s.run()
-	log.Println("⏰ Scheduler started - checking for scheduled analyses every hour")
+	go s.run(ctx)
+	log.Printf("⏰ Scheduler started - checking every minute, %d concurrent runs, up to %s jitter", s.concurrency, s.maxJitter)
 }
 
-// Stop stops the scheduler
-func (s *Scheduler) Stop() {
+// Stop stops dispatching runs and waits up to drainTimeout for in-progress analyses to finish.
+// Runs still waiting for their jitter delay or a slot are recorded as skipped.
+func (s *Scheduler) Stop(drainTimeout time.Duration) error {
+	s.mu.Lock()
 	if !s.running {
-		return
+		s.mu.Unlock()
+		return nil
 	}
-	s.stopChan <- true
 	s.running = false
-	log.Println("⏰ Scheduler stopped")
+	s.cancel()
+	s.mu.Unlock()
+	<-s.done
+
+	drained := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		log.Println("⏰ Scheduler stopped")
+		return nil
+	case <-time.After(drainTimeout):
+		return fmt.Errorf("scheduler stopped with runs still in progress for brands %v", s.InFlight())
+	}
+}
+
+// Running reports whether the scheduler is dispatching runs
+func (s *Scheduler) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
 }
 
-// run is the main scheduler loop
-func (s *Scheduler) run() {
-	ticker := time.NewTicker(1 * time.Hour) // Check every hour
+// InFlight returns the IDs of the brands with a queued or running analysis
+func (s *Scheduler) InFlight() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int, 0, len(s.inFlight))
+	for id := range s.inFlight {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// run is the main scheduler loop. It checks once at start so runs missed while the
+// server was down are not delayed by a tick.
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(schedulerTick)
 	defer ticker.Stop()
 
+	s.checkScheduledRuns(ctx)
 	for {
 		select {
-		case <-s.stopChan:
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			s.checkScheduledRuns()
+			s.checkScheduledRuns(ctx)
 		}
 	}
 }
 
-// checkScheduledRuns checks all brands for scheduled analysis
-func (s *Scheduler) checkScheduledRuns() {
-	log.Println("⏰ Checking for scheduled analyses...")
-
+// checkScheduledRuns dispatches every brand whose next run is due, then checks alerts of the others
+func (s *Scheduler) checkScheduledRuns(ctx context.Context) {
 	brandRepo := db.NewBrandRepository()
-	brands, err := brandRepo.GetAllBrands()
+	runRepo := db.NewScheduledRunRepository()
+	now := time.Now()
+	brands, err := brandRepo.GetDueBrands(now)
 	if err != nil {
 		log.Printf("Error fetching brands for scheduling: %v", err)
 		return
 	}
 
-	now := time.Now()
-
 	for _, brand := range brands {
-		if brand.ScheduleFrequency == "" || brand.ScheduleFrequency == "disabled" {
+		s.mu.Lock()
+		busy := s.inFlight[brand.ID]
+		s.mu.Unlock()
+		if busy {
 			continue
 		}
 
-		// Check if it's time to run
-		shouldRun := false
-
-		switch brand.ScheduleFrequency {
-		case "daily":
-			// Run if last run was more than 24 hours ago
-			if brand.LastScheduledRun.IsZero() || now.Sub(brand.LastScheduledRun) > 24*time.Hour {
-				shouldRun = true
-			}
-		case "weekly":
-			// Run if last run was more than 7 days ago
-			if brand.LastScheduledRun.IsZero() || now.Sub(brand.LastScheduledRun) > 7*24*time.Hour {
-				shouldRun = true
-			}
+		// Claim the run by moving the brand to its next run time before starting it, so the
+		// next tick does not pick it up again. The claim only succeeds while the brand is still
+		// due at the time read, so of several instances exactly one starts the run. Runs missed
+		// while the server was down collapse into this one.
+		scheduledFor := brand.NextRunAt
+		brand.LastScheduledRun = now
+		next, err := NextScheduledRun(brand, now)
+		if err != nil {
+			log.Printf("Invalid schedule for brand %d, disabling next runs: %v", brand.ID, err)
 		}
-
-		if shouldRun {
-			log.Printf("⏰ Running scheduled analysis for brand: %s", brand.Name)
-			s.runScheduledAnalysis(brand.ID)
-
-			// Update last run time
-			brandRepo.UpdateLastScheduledRun(brand.ID, now)
+		claimed, err := brandRepo.ClaimScheduledRun(brand.ID, *scheduledFor, now, next)
+		if err != nil {
+			log.Printf("Error claiming scheduled run for brand %d: %v", brand.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		runID, err := runRepo.Create(brand.ID, scheduledFor)
+		if err != nil {
+			log.Printf("Error recording scheduled run for brand %d: %v", brand.ID, err)
+			continue
 		}
+
+		s.mu.Lock()
+		s.inFlight[brand.ID] = true
+		s.mu.Unlock()
+		s.runs.Add(1)
+		go s.execute(ctx, brand, runID)
 	}
 
-	// Also check for email alerts
+	// Also check for email alerts. Brands with a run queued or in progress are checked when it
+	// finishes instead, against the snapshot it produces.
+	if now.Sub(s.lastAlertCheck) < alertCheckInterval {
+		return
+	}
+	s.lastAlertCheck = now
 	emailSvc := GetEmailService()
 	if emailSvc != nil && emailSvc.IsEnabled() {
-		emailSvc.CheckAndSendAlerts()
+		emailSvc.CheckAndSendAlerts(func(brandID int) bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.inFlight[brandID]
+		})
 	}
 }
 
-// runScheduledAnalysis runs analysis for a brand
-func (s *Scheduler) runScheduledAnalysis(brandID int) {
+// execute waits for the brand's jitter delay and a free slot, runs the analysis, records its
+// outcome and checks the brand's alerts. Once started, an analysis runs to completion even if
+// the scheduler stops.
+func (s *Scheduler) execute(ctx context.Context, brand models.Brand, runID int) {
+	runRepo := db.NewScheduledRunRepository()
+	defer func() {
+		s.mu.Lock()
+		delete(s.inFlight, brand.ID)
+		s.mu.Unlock()
+		s.runs.Done()
+	}()
+
+	select {
+	case <-ctx.Done():
+		runRepo.Finish(runID, models.RunSkipped, 0, "scheduler stopped before the run started")
+		return
+	case <-time.After(s.jitter(brand.ID)):
+	}
+	select {
+	case <-ctx.Done():
+		runRepo.Finish(runID, models.RunSkipped, 0, "scheduler stopped before the run started")
+		return
+	case s.slots <- struct{}{}:
+	}
+	defer func() { <-s.slots }()
+
+	if err := runRepo.MarkStarted(runID); err != nil {
+		log.Printf("Error recording scheduled run %d start: %v", runID, err)
+	}
+	log.Printf("⏰ Running scheduled analysis for brand: %s", brand.Name)
+
+	result, err := s.runScheduledAnalysis(brand.ID)
+	status, responses, message := scheduledRunOutcome(result, err)
+	if err := runRepo.Finish(runID, status, responses, message); err != nil {
+		log.Printf("Error recording scheduled run %d outcome: %v", runID, err)
+	}
+	if status == models.RunSucceeded {
+		log.Printf("✅ Scheduled analysis completed for brand %d", brand.ID)
+	} else {
+		log.Printf("⚠️ Scheduled analysis for brand %d %s: %s", brand.ID, status, message)
+	}
+
+	// Check alerts against the snapshot the run just stored
+	if status != models.RunSucceeded && status != models.RunPartial {
+		return
+	}
+	emailSvc := GetEmailService()
+	if emailSvc == nil || !emailSvc.IsEnabled() {
+		return
+	}
+	current, err := db.NewBrandRepository().GetScheduleByID(brand.ID)
+	if err != nil {
+		log.Printf("Error loading brand %d for alerts: %v", brand.ID, err)
+		return
+	}
+	emailSvc.CheckBrandAlerts(*current)
+}
+
+// jitter is the brand's start delay within [0, maxJitter). It is stable per brand, so brands
+// due at the same time always start in the same spread-out order.
+func (s *Scheduler) jitter(brandID int) time.Duration {
+	if s.maxJitter <= 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strconv.Itoa(brandID)))
+	return time.Duration(uint64(h.Sum32()) % uint64(s.maxJitter))
+}
+
+// scheduledRunOutcome maps an analysis result onto a run status, response count and error message
+func scheduledRunOutcome(result *RunAnalysisResult, err error) (string, int, string) {
+	switch {
+	case errors.Is(err, ai.ErrRequestInFlight):
+		return models.RunSkipped, 0, "another analysis of the brand was in progress"
+	case err != nil:
+		return models.RunFailed, 0, err.Error()
+	case !result.Success:
+		return models.RunFailed, result.ResponsesRun, strings.Join(result.Errors, "; ")
+	case len(result.Errors) > 0:
+		return models.RunPartial, result.ResponsesRun, strings.Join(result.Errors, "; ")
+	default:
+		return models.RunSucceeded, result.ResponsesRun, ""
+	}
+}
+
+// GetScheduleOverview lists the next scheduled runs and the latest ones, with the scheduler's state
+func GetScheduleOverview(limit int) (*models.ScheduleOverview, error) {
+	brands, err := db.NewBrandRepository().GetUpcomingRuns(limit)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := db.NewScheduledRunRepository().GetRecent(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &models.ScheduleOverview{
+		InFlight: []int{},
+		Upcoming: []models.UpcomingRun{},
+		Recent:   recent,
+	}
+	if overview.Recent == nil {
+		overview.Recent = []models.ScheduledRun{}
+	}
+	for _, b := range brands {
+		overview.Upcoming = append(overview.Upcoming, models.UpcomingRun{
+			BrandID:           b.ID,
+			BrandName:         b.Name,
+			ScheduleFrequency: b.ScheduleFrequency,
+			ScheduleCron:      b.ScheduleCron,
+			ScheduleTimezone:  b.ScheduleTimezone,
+			NextRunAt:         *b.NextRunAt,
+		})
+	}
+	if s := GetScheduler(); s != nil {
+		overview.SchedulerRunning = s.Running()
+		overview.Concurrency = s.concurrency
+		overview.MaxJitterSeconds = int(s.maxJitter.Seconds())
+		overview.InFlight = s.InFlight()
+	}
+	return overview, nil
+}
+
+// NormalizeSchedule validates a brand's schedule settings in place. A time zone may trail the
+// cron expression ("0 6 * * MON Europe/Berlin"); a cron expression without a frequency
+// implies "cron". Non-cron frequencies drop the expression.
+func NormalizeSchedule(settings *models.AlertSettings) error {
+	expression, timezone := SplitCronTimezone(settings.ScheduleCron)
+	if timezone != "" {
+		if settings.ScheduleTimezone != "" && settings.ScheduleTimezone != timezone {
+			return fmt.Errorf("%w: time zone given twice (%q and %q)", ErrInvalidSchedule, timezone, settings.ScheduleTimezone)
+		}
+		settings.ScheduleTimezone = timezone
+	}
+	settings.ScheduleCron = expression
+	settings.ScheduleTimezone = strings.TrimSpace(settings.ScheduleTimezone)
+
+	frequency := strings.ToLower(strings.TrimSpace(settings.ScheduleFrequency))
+	if frequency == "" {
+		frequency = models.ScheduleDisabled
+		if expression != "" {
+			frequency = models.ScheduleCron
+		}
+	}
+	settings.ScheduleFrequency = frequency
+
+	switch frequency {
+	case models.ScheduleCron:
+		if expression == "" {
+			return fmt.Errorf("%w: frequency \"cron\" needs a cron expression", ErrInvalidSchedule)
+		}
+		if settings.ScheduleTimezone == "" {
+			settings.ScheduleTimezone = "UTC"
+		}
+		_, err := ParseCron(expression, settings.ScheduleTimezone)
+		return err
+	case models.ScheduleDisabled, models.ScheduleDaily, models.ScheduleWeekly:
+		settings.ScheduleCron, settings.ScheduleTimezone = "", ""
+		return nil
+	default:
+		return fmt.Errorf("%w: frequency must be disabled, daily, weekly or cron", ErrInvalidSchedule)
+	}
+}
+
+// NextScheduledRun returns when a brand's next scheduled analysis is due after a time, or nil
+// when its schedule is disabled. Daily and weekly schedules count from the last scheduled run
+// (due straight away when overdue); cron schedules fire at the expression's next match.
+func NextScheduledRun(brand models.Brand, after time.Time) (*time.Time, error) {
+	var next time.Time
+	switch brand.ScheduleFrequency {
+	case models.ScheduleDaily:
+		next = brand.LastScheduledRun.Add(24 * time.Hour)
+	case models.ScheduleWeekly:
+		next = brand.LastScheduledRun.Add(7 * 24 * time.Hour)
+	case models.ScheduleCron:
+		schedule, err := ParseCron(brand.ScheduleCron, brand.ScheduleTimezone)
+		if err != nil {
+			return nil, err
+		}
+		next = schedule.Next(after)
+	default:
+		return nil, nil
+	}
+	if next.Before(after) {
+		next = after
+	}
+	return &next, nil
+}
+
+// runScheduledAnalysis runs analysis for a brand on its first three prompts
+func (s *Scheduler) runScheduledAnalysis(brandID int) (*RunAnalysisResult, error) {
 	analysisSvc := GetAnalysisService()
 	if analysisSvc == nil {
-		log.Println("Analysis service not initialized")
-		return
+		return nil, errors.New("analysis service not initialized")
 	}
 
 	// Get default prompts
 	promptRepo := db.NewPromptRepository()
 	prompts, err := promptRepo.GetAll()
-	if err != nil || len(prompts) == 0 {
-		log.Printf("No prompts available for scheduled analysis")
-		return
+	if err != nil {
+		return nil, err
+	}
+	if len(prompts) == 0 {
+		return nil, errors.New("no prompts available for scheduled analysis")
 	}
 
 	// Use first 3 prompts
@@ -141,11 +431,6 @@ func (s *Scheduler) runScheduledAnalysis(brandID int) {
 		promptIDs = append(promptIDs, p.ID)
 	}
 
-	// Run analysis
-	_, err = analysisSvc.RunAnalysis(context.Background(), brandID, promptIDs)
-	if err != nil {
-		log.Printf("Scheduled analysis failed for brand %d: %v", brandID, err)
-	} else {
-		log.Printf("✅ Scheduled analysis completed for brand %d", brandID)
-	}
+	// Not tied to the scheduler's context: a started run finishes even during shutdown
+	return analysisSvc.RunAnalysis(context.Background(), brandID, promptIDs)
 }
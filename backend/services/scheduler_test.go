@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/ai"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestScheduledRunOutcome(t *testing.T) {
+	cases := []struct {
+		name       string
+		result     *RunAnalysisResult
+		err        error
+		wantStatus string
+		wantRun    int
+	}{
+		{"succeeded", &RunAnalysisResult{Success: true, ResponsesRun: 6}, nil, models.RunSucceeded, 6},
+		{"partial", &RunAnalysisResult{Success: true, ResponsesRun: 4, Errors: []string{"timeout"}}, nil, models.RunPartial, 4},
+		{"all responses failed", &RunAnalysisResult{Errors: []string{"timeout"}}, nil, models.RunFailed, 0},
+		{"brand busy", nil, ai.ErrRequestInFlight, models.RunSkipped, 0},
+		{"error", nil, errors.New("no prompts"), models.RunFailed, 0},
+	}
+	for _, tc := range cases {
+		status, responses, _ := scheduledRunOutcome(tc.result, tc.err)
+		if status != tc.wantStatus || responses != tc.wantRun {
+			t.Errorf("%s: got %s/%d, want %s/%d", tc.name, status, responses, tc.wantStatus, tc.wantRun)
+		}
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	s := &Scheduler{maxJitter: 2 * time.Minute}
+	for id := 1; id <= 100; id++ {
+		d := s.jitter(id)
+		if d < 0 || d >= s.maxJitter {
+			t.Fatalf("jitter(%d) = %s, want within [0, %s)", id, d, s.maxJitter)
+		}
+		if d != s.jitter(id) {
+			t.Fatalf("jitter(%d) is not stable", id)
+		}
+	}
+	if d := (&Scheduler{}).jitter(1); d != 0 {
+		t.Errorf("jitter without max = %s, want 0", d)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// scoringWeightTolerance absorbs rounding when checking that weights sum to 1
+const scoringWeightTolerance = 0.001
+
+// ErrInvalidScoringProfile wraps every profile validation failure
+var ErrInvalidScoringProfile = errors.New("invalid scoring profile")
+
+// DefaultScoringProfile returns the built-in weights (version 0) used until a brand saves a profile
+func DefaultScoringProfile(brandID int) *models.ScoringProfile {
+	return scoring.DefaultProfile(brandID)
+}
+
+// GetScoringProfile returns the brand's active profile, or the built-in one if it has none
+func GetScoringProfile(brandID int) (*models.ScoringProfile, error) {
+	profile, err := db.NewScoringProfileRepository().GetActive(brandID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultScoringProfile(brandID), nil
+	}
+	return profile, err
+}
+
+// loadScoringProfile is GetScoringProfile for metric calculation, falling back to
+// the built-in weights rather than failing a run
+func loadScoringProfile(brandID int) *models.ScoringProfile {
+	profile, err := GetScoringProfile(brandID)
+	if err != nil {
+		log.Printf("Warning: failed to load scoring profile for brand %d, using defaults: %v", brandID, err)
+		return DefaultScoringProfile(brandID)
+	}
+	return profile
+}
+
+// ValidateScoringProfile checks that weights are in [0, 1], component weights sum to 1,
+// and position weights do not increase further down the list
+func ValidateScoringProfile(p *models.ScoringProfile) error {
+	weights := []struct {
+		name  string
+		value float64
+	}{
+		{"weight_mention_rate", p.WeightMentionRate},
+		{"weight_position", p.WeightPosition},
+		{"weight_recommend", p.WeightRecommend},
+		{"weight_sentiment", p.WeightSentiment},
+		{"position_first", p.PositionFirst},
+		{"position_second", p.PositionSecond},
+		{"position_later", p.PositionLater},
+	}
+	for _, w := range weights {
+		if w.value < 0 || w.value > 1 {
+			return fmt.Errorf("%w: %s must be between 0 and 1", ErrInvalidScoringProfile, w.name)
+		}
+	}
+
+	sum := p.WeightMentionRate + p.WeightPosition + p.WeightRecommend + p.WeightSentiment
+	if math.Abs(sum-1) > scoringWeightTolerance {
+		return fmt.Errorf("%w: component weights must sum to 1 (got %.4f)", ErrInvalidScoringProfile, sum)
+	}
+
+	if p.PositionSecond > p.PositionFirst || p.PositionLater > p.PositionSecond {
+		return fmt.Errorf("%w: position weights must not increase (first >= second >= later)", ErrInvalidScoringProfile)
+	}
+	return nil
+}
+
+// SaveScoringProfile validates the request and stores it as the brand's next profile version
+func SaveScoringProfile(brandID int, req models.ScoringProfileRequest) (*models.ScoringProfile, error) {
+	current, err := GetScoringProfile(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.ScoringProfile{
+		BrandID:           brandID,
+		WeightMentionRate: req.WeightMentionRate,
+		WeightPosition:    req.WeightPosition,
+		WeightRecommend:   req.WeightRecommend,
+		WeightSentiment:   req.WeightSentiment,
+		PositionFirst:     current.PositionFirst,
+		PositionSecond:    current.PositionSecond,
+		PositionLater:     current.PositionLater,
+	}
+	if req.PositionFirst != nil {
+		profile.PositionFirst = *req.PositionFirst
+	}
+	if req.PositionSecond != nil {
+		profile.PositionSecond = *req.PositionSecond
+	}
+	if req.PositionLater != nil {
+		profile.PositionLater = *req.PositionLater
+	}
+
+	if err := ValidateScoringProfile(profile); err != nil {
+		return nil, err
+	}
+	return db.NewScoringProfileRepository().Create(profile)
+}
+
+// RescoreHistory recomputes every stored snapshot of a brand under its active profile.
+// Component scores are kept; the position score is recomputed from rank counts
+// where the snapshot has them. Every snapshot is written in one transaction.
+func (m *MetricsCalculator) RescoreHistory(brandID int) (*models.RescoreReport, error) {
+	profile, err := GetScoringProfile(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	metricRepo := db.NewMetricRepository()
+	snapshots, err := metricRepo.GetComponentsByBrandID(brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.RescoreReport{BrandID: brandID, ProfileVersion: profile.Version}
+	for i := range snapshots {
+		s := &snapshots[i]
+		before := s.VisibilityScore
+
+		if s.RankFirstCount != nil && s.RankSecondCount != nil && s.ResponseCount > 0 {
+			responsesWithBrand := int(math.Round(s.NormalizedMentionRate * float64(s.ResponseCount)))
+			later := responsesWithBrand - *s.RankFirstCount - *s.RankSecondCount
+			if later < 0 {
+				later = 0
+			}
+			s.WeightedPositionScore = (float64(*s.RankFirstCount)*profile.PositionFirst +
+				float64(*s.RankSecondCount)*profile.PositionSecond +
+				float64(later)*profile.PositionLater) / float64(s.ResponseCount)
+			report.PositionRecomputed++
+		}
+
+		s.VisibilityScore = scoring.Score(profile, scoring.Components{
+			MentionRate:        s.NormalizedMentionRate,
+			PositionScore:      s.WeightedPositionScore,
+			RecommendationRate: s.RecommendationRate,
+			RelativeSentiment:  s.RelativeSentimentIndex,
+		})
+		s.ScoringProfileVersion = profile.Version
+
+		report.SnapshotsRescored++
+		report.LatestScoreBefore = before
+		report.LatestScoreAfter = s.VisibilityScore
+	}
+
+	counts, err := metricRepo.Rescore(brandID, snapshots, profile)
+	if err != nil {
+		return nil, err
+	}
+	report.ProductSnapshots = counts.Products
+	report.CompetitorSnapshots = counts.Competitors
+	report.ModelSnapshots = counts.Models
+	report.CategorySnapshots = counts.Categories
+
+	log.Printf("⚖️ Rescored %d snapshots of brand %d under scoring profile v%d", report.SnapshotsRescored, brandID, profile.Version)
+	return report, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+func TestValidateScoringProfile(t *testing.T) {
+	cases := []struct {
+		name    string
+		edit    func(p *models.ScoringProfile)
+		wantErr bool
+	}{
+		{"defaults", func(p *models.ScoringProfile) {}, false},
+		{"single component", func(p *models.ScoringProfile) {
+			p.WeightMentionRate, p.WeightPosition, p.WeightRecommend, p.WeightSentiment = 1, 0, 0, 0
+		}, false},
+		{"sum within tolerance", func(p *models.ScoringProfile) {
+			p.WeightMentionRate, p.WeightPosition, p.WeightRecommend, p.WeightSentiment = 0.3333, 0.3333, 0.3334, 0.0005
+		}, false},
+		{"equal position weights", func(p *models.ScoringProfile) {
+			p.PositionFirst, p.PositionSecond, p.PositionLater = 0.5, 0.5, 0.5
+		}, false},
+		{"negative weight", func(p *models.ScoringProfile) {
+			p.WeightMentionRate, p.WeightPosition = -0.1, p.WeightPosition+p.WeightMentionRate+0.1
+		}, true},
+		{"weight above one", func(p *models.ScoringProfile) {
+			p.WeightMentionRate, p.WeightPosition, p.WeightRecommend, p.WeightSentiment = 1.2, -0.2, 0, 0
+		}, true},
+		{"position weight above one", func(p *models.ScoringProfile) { p.PositionFirst = 1.5 }, true},
+		{"negative position weight", func(p *models.ScoringProfile) { p.PositionLater = -0.1 }, true},
+		{"sum below one", func(p *models.ScoringProfile) {
+			p.WeightMentionRate, p.WeightPosition, p.WeightRecommend, p.WeightSentiment = 0.2, 0.2, 0.2, 0.2
+		}, true},
+		{"sum above one", func(p *models.ScoringProfile) {
+			p.WeightMentionRate, p.WeightPosition, p.WeightRecommend, p.WeightSentiment = 0.4, 0.4, 0.4, 0.4
+		}, true},
+		{"second above first", func(p *models.ScoringProfile) {
+			p.PositionFirst, p.PositionSecond, p.PositionLater = 0.5, 0.8, 0.2
+		}, true},
+		{"later above second", func(p *models.ScoringProfile) {
+			p.PositionFirst, p.PositionSecond, p.PositionLater = 1, 0.3, 0.6
+		}, true},
+	}
+
+	for _, tc := range cases {
+		p := DefaultScoringProfile(1)
+		tc.edit(p)
+		err := ValidateScoringProfile(p)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: ValidateScoringProfile() error = %v, want error %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidScoringProfile) {
+			t.Errorf("%s: error %v does not wrap ErrInvalidScoringProfile", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/ai"
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// Segment names for responses stored without a model or prompt category
+const (
+	unknownModel         = "Unknown"
+	uncategorizedPrompts = "Uncategorized"
+)
+
+// segmentRuns scores the brand separately within each segment of a run's responses
+// (an AI model or a prompt category), keyed by the segment name
+type segmentRuns map[string]*scoring.Run
+
+// addResponse counts one response under its segment
+func (r segmentRuns) addResponse(segment string, mentions []models.Mention, profile *models.ScoringProfile, sentimentSource string) {
+	run, ok := r[segment]
+	if !ok {
+		run = scoring.NewRun(profile, sentimentSource)
+		r[segment] = run
+	}
+	run.AddResponse(mentions)
+}
+
+// promptRun is the brand's run within one prompt's responses
+type promptRun struct {
+	category string
+	run      *scoring.Run
+}
+
+// promptRuns accumulates the brand separately within each prompt's responses, keyed by prompt ID
+type promptRuns map[int]*promptRun
+
+// addResponse counts one response under its prompt
+func (r promptRuns) addResponse(promptID int, category string, mentions []models.Mention, profile *models.ScoringProfile, sentimentSource string) {
+	p, ok := r[promptID]
+	if !ok {
+		p = &promptRun{category: category, run: scoring.NewRun(profile, sentimentSource)}
+		r[promptID] = p
+	}
+	p.run.AddResponse(mentions)
+}
+
+// modelSnapshots scores each model, linked to the brand snapshot of the same run
+func (m *MetricsCalculator) modelSnapshots(brandID, metricSnapshotID int, runs segmentRuns, profile *models.ScoringProfile, snapshotDate time.Time) []models.ModelSnapshot {
+	var snapshots []models.ModelSnapshot
+	for modelName, run := range runs {
+		components := run.Components()
+		snapshots = append(snapshots, models.ModelSnapshot{
+			BrandID:                brandID,
+			MetricSnapshotID:       metricSnapshotID,
+			ModelName:              modelName,
+			VisibilityScore:        run.Score(),
+			MentionCount:           run.Brand.Mentions,
+			ResponsesWithBrand:     run.Brand.Responses,
+			SnapshotDate:           snapshotDate,
+			NormalizedMentionRate:  components.MentionRate,
+			WeightedPositionScore:  components.PositionScore,
+			RecommendationRate:     components.RecommendationRate,
+			RelativeSentimentIndex: components.RelativeSentiment,
+			ResponseCount:          run.Responses,
+			ScoringProfileVersion:  profile.Version,
+		})
+	}
+	return snapshots
+}
+
+// storeModelSnapshots stores the model snapshots of a run
+func (m *MetricsCalculator) storeModelSnapshots(brandID, metricSnapshotID int, runs segmentRuns, profile *models.ScoringProfile, snapshotDate time.Time) error {
+	repo := db.NewModelSnapshotRepository()
+	snapshots := m.modelSnapshots(brandID, metricSnapshotID, runs, profile, snapshotDate)
+	for i := range snapshots {
+		if err := repo.Create(&snapshots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categorySnapshots scores each prompt category, linked to the brand snapshot of the same run
+func (m *MetricsCalculator) categorySnapshots(brandID, metricSnapshotID int, runs segmentRuns, profile *models.ScoringProfile, snapshotDate time.Time) []models.CategorySnapshot {
+	var snapshots []models.CategorySnapshot
+	for category, run := range runs {
+		components := run.Components()
+		snapshots = append(snapshots, models.CategorySnapshot{
+			BrandID:                brandID,
+			MetricSnapshotID:       metricSnapshotID,
+			Category:               category,
+			VisibilityScore:        run.Score(),
+			MentionCount:           run.Brand.Mentions,
+			ResponsesWithBrand:     run.Brand.Responses,
+			SnapshotDate:           snapshotDate,
+			NormalizedMentionRate:  components.MentionRate,
+			WeightedPositionScore:  components.PositionScore,
+			RecommendationRate:     components.RecommendationRate,
+			RelativeSentimentIndex: components.RelativeSentiment,
+			ResponseCount:          run.Responses,
+			ScoringProfileVersion:  profile.Version,
+		})
+	}
+	return snapshots
+}
+
+// storeCategorySnapshots stores the category snapshots of a run
+func (m *MetricsCalculator) storeCategorySnapshots(brandID, metricSnapshotID int, runs segmentRuns, profile *models.ScoringProfile, snapshotDate time.Time) error {
+	repo := db.NewCategorySnapshotRepository()
+	snapshots := m.categorySnapshots(brandID, metricSnapshotID, runs, profile, snapshotDate)
+	for i := range snapshots {
+		if err := repo.Create(&snapshots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promptSnapshots collects each prompt's raw counts, linked to the brand snapshot of the same run
+func (m *MetricsCalculator) promptSnapshots(brandID, metricSnapshotID int, runs promptRuns, snapshotDate time.Time) []models.PromptSnapshot {
+	var snapshots []models.PromptSnapshot
+	for promptID, p := range runs {
+		snapshots = append(snapshots, models.PromptSnapshot{
+			BrandID:              brandID,
+			MetricSnapshotID:     metricSnapshotID,
+			PromptID:             promptID,
+			Category:             p.category,
+			ResponseCount:        p.run.Responses,
+			ResponsesWithBrand:   p.run.Brand.Responses,
+			MentionCount:         p.run.Brand.Mentions,
+			RecommendedResponses: p.run.Brand.RecommendedResponses,
+			RankedResponses:      p.run.RankedResponses,
+			RankTotal:            p.run.RankTotal,
+			SentimentSum:         p.run.Brand.SentimentSum,
+			SnapshotDate:         snapshotDate,
+		})
+	}
+	return snapshots
+}
+
+// storePromptSnapshots stores the prompt snapshots of a run
+func (m *MetricsCalculator) storePromptSnapshots(brandID, metricSnapshotID int, runs promptRuns, snapshotDate time.Time) error {
+	repo := db.NewPromptSnapshotRepository()
+	snapshots := m.promptSnapshots(brandID, metricSnapshotID, runs, snapshotDate)
+	for i := range snapshots {
+		if err := repo.Create(&snapshots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetModelTrends returns each AI model's latest snapshot and its trend over the last N days,
+// by model identifier
+func (m *MetricsCalculator) GetModelTrends(brandID int, days int) ([]models.ModelTrend, error) {
+	snapshots, err := db.NewModelSnapshotRepository().GetSince(brandID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	byModel := map[string][]models.ModelSnapshot{}
+	var names []string
+	for _, s := range snapshots {
+		if _, ok := byModel[s.ModelName]; !ok {
+			names = append(names, s.ModelName)
+		}
+		byModel[s.ModelName] = append(byModel[s.ModelName], s)
+	}
+	sort.Strings(names)
+
+	trends := []models.ModelTrend{}
+	for _, name := range names {
+		history := byModel[name]
+		latest := history[len(history)-1]
+		info := ai.LookupModel(name)
+		trends = append(trends, models.ModelTrend{
+			ModelName:   name,
+			DisplayName: info.Name,
+			Color:       info.Color,
+			Latest:      &latest,
+			Trends:      history,
+		})
+	}
+	return trends, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+)
+
+// voiceBrand tracks Acme against Globex and Initech
+var voiceBrand = &models.Brand{
+	Name:        "Acme",
+	Competitors: []models.Competitor{{ID: 1, Name: "Globex"}, {ID: 2, Name: "Initech"}},
+}
+
+func voiceMention(entityType, name, sentiment, model, category string) db.ContextMention {
+	return db.ContextMention{
+		Mention:        models.Mention{EntityType: entityType, EntityName: name, Sentiment: sentiment},
+		ModelName:      model,
+		PromptCategory: category,
+	}
+}
+
+func TestCalculateCitationBreakdown(t *testing.T) {
+	cases := []struct {
+		name     string
+		mentions []db.ContextMention
+		want     []models.CitationBreakdown
+	}{
+		{
+			name: "shares of tracked mentions",
+			mentions: []db.ContextMention{
+				voiceMention("brand", "Acme", "positive", "", ""),
+				voiceMention("brand", "Acme Cloud", "neutral", "", ""), // Products count for the brand
+				voiceMention("competitor", "GLOBEX", "neutral", "", ""),
+				voiceMention("competitor", "Hooli", "positive", "", ""), // No longer tracked
+			},
+			want: []models.CitationBreakdown{
+				{Name: "Acme", Value: 66.7, Color: brandVoiceColor, Mentions: 2},
+				{Name: "Globex", Value: 33.3, Color: competitorVoiceColors[0], Mentions: 1},
+				{Name: "Initech", Value: 0, Color: competitorVoiceColors[1], Mentions: 0},
+			},
+		},
+		{
+			name: "no mentions",
+			want: []models.CitationBreakdown{
+				{Name: "Acme", Value: 0, Color: brandVoiceColor},
+				{Name: "Globex", Value: 0, Color: competitorVoiceColors[0]},
+				{Name: "Initech", Value: 0, Color: competitorVoiceColors[1]},
+			},
+		},
+	}
+
+	m := NewMetricsCalculator()
+	for _, tc := range cases {
+		got := m.calculateCitationBreakdown(voiceBrand, tc.mentions)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCalculateCompetitorMetrics(t *testing.T) {
+	judged := voiceMention("competitor", "Globex", "neutral", "", "")
+	judged.AISentiment = "negative"
+	mentions := []db.ContextMention{
+		voiceMention("brand", "Acme", "positive", "", ""),
+		voiceMention("brand", "Acme", "negative", "", ""),
+		judged,
+		voiceMention("competitor", "Initech", "positive", "", ""),
+	}
+
+	cases := []struct {
+		source string
+		want   []models.CompetitorMetrics
+	}{
+		{SentimentSourceRules, []models.CompetitorMetrics{
+			{Name: "Acme", Mentions: 2, Positive: 1, Negative: 1},
+			{Name: "Globex", Mentions: 1, Neutral: 1},
+			{Name: "Initech", Mentions: 1, Positive: 1},
+		}},
+		{SentimentSourceLLM, []models.CompetitorMetrics{
+			{Name: "Acme", Mentions: 2, Positive: 1, Negative: 1},
+			{Name: "Globex", Mentions: 1, Negative: 1},
+			{Name: "Initech", Mentions: 1, Positive: 1},
+		}},
+	}
+
+	m := NewMetricsCalculator()
+	for _, tc := range cases {
+		brand := *voiceBrand
+		brand.SentimentSource = tc.source
+		got := m.calculateCompetitorMetrics(&brand, mentions)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("source %s: got %+v, want %+v", tc.source, got, tc.want)
+		}
+	}
+}
+
+func TestCalculateShareOfVoiceGroups(t *testing.T) {
+	mentions := []db.ContextMention{
+		voiceMention("brand", "Acme", "positive", "gpt-4o", "pricing"),
+		voiceMention("competitor", "Globex", "neutral", "gpt-4o", "pricing"),
+		voiceMention("brand", "Acme", "positive", "claude", ""),
+		voiceMention("competitor", "Hooli", "neutral", "gemini", "support"), // Untracked only: no group
+	}
+
+	byModel, byCategory := NewMetricsCalculator().calculateShareOfVoiceGroups(voiceBrand, mentions)
+
+	groups := func(gs []models.ShareOfVoiceGroup) map[string][]float64 {
+		out := map[string][]float64{}
+		for _, g := range gs {
+			for _, b := range g.Breakdown {
+				out[g.Group] = append(out[g.Group], b.Value)
+			}
+		}
+		return out
+	}
+	names := func(gs []models.ShareOfVoiceGroup) []string {
+		var out []string
+		for _, g := range gs {
+			out = append(out, g.Group)
+		}
+		return out
+	}
+
+	if got, want := names(byModel), []string{"claude", "gpt-4o"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("model groups = %v, want %v", got, want)
+	}
+	if got, want := groups(byModel), map[string][]float64{"claude": {100, 0, 0}, "gpt-4o": {50, 50, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("model shares = %v, want %v", got, want)
+	}
+	if got, want := names(byCategory), []string{"Uncategorized", "pricing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("category groups = %v, want %v", got, want)
+	}
+	if byCategory[1].TotalMentions != 2 {
+		t.Errorf("pricing total = %d, want 2", byCategory[1].TotalMentions)
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Sneh16Shah/ai-visibility-tracker/db"
+	"github.com/Sneh16Shah/ai-visibility-tracker/models"
+	"github.com/Sneh16Shah/ai-visibility-tracker/scoring"
+)
+
+// ErrSnapshotNotFound is returned when a snapshot to compare does not exist for the brand
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// CompareSnapshots tests whether the score change between two snapshots of a brand is
+// statistically meaningful. With zero IDs the latest snapshot is compared with the one before it.
+func (m *MetricsCalculator) CompareSnapshots(brandID, fromID, toID int) (*models.ScoreChange, error) {
+	metricRepo := db.NewMetricRepository()
+
+	var from, to *models.MetricSnapshot
+	if fromID == 0 && toID == 0 {
+		recent, err := metricRepo.GetTrendsByBrandID(brandID, 2)
+		if err != nil {
+			return nil, err
+		}
+		if len(recent) < 2 {
+			return nil, ErrSnapshotNotFound
+		}
+		to, from = &recent[0], &recent[1]
+	} else {
+		var err error
+		if from, err = brandSnapshot(metricRepo, brandID, fromID); err != nil {
+			return nil, err
+		}
+		if to, err = brandSnapshot(metricRepo, brandID, toID); err != nil {
+			return nil, err
+		}
+	}
+
+	return snapshotChange(loadScoringProfile(brandID), from, to), nil
+}
+
+// brandSnapshot loads a snapshot, checking it belongs to the brand
+func brandSnapshot(metricRepo *db.MetricRepository, brandID, snapshotID int) (*models.MetricSnapshot, error) {
+	snapshot, err := metricRepo.GetByID(snapshotID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && snapshot.BrandID != brandID) {
+		return nil, ErrSnapshotNotFound
+	}
+	return snapshot, err
+}
+
+// snapshotChange compares two stored snapshots. The score's spread is estimated with the
+// brand's active profile, which matches the stored scores unless history was not rescored.
+func snapshotChange(profile *models.ScoringProfile, from, to *models.MetricSnapshot) *models.ScoreChange {
+	change := scoring.CompareRuns(profile, snapshotSample(from), snapshotSample(to))
+	change.FromSnapshotID, change.ToSnapshotID = from.ID, to.ID
+	change.FromDate, change.ToDate = from.SnapshotDate, to.SnapshotDate
+	return &change
+}
+
+// snapshotSample converts a stored snapshot into the inputs of a significance test
+func snapshotSample(s *models.MetricSnapshot) scoring.Sample {
+	return scoring.Sample{
+		Score: s.VisibilityScore,
+		Components: scoring.Components{
+			MentionRate:        s.NormalizedMentionRate,
+			PositionScore:      s.WeightedPositionScore,
+			RecommendationRate: s.RecommendationRate,
+			RelativeSentiment:  s.RelativeSentimentIndex,
+		},
+		Responses: s.ResponseCount,
+	}
+}
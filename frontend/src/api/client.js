@@ -119,12 +119,15 @@ export async function deleteBrand(id) {
     });
 }
 
-export async function updateAlertSettings(id, alertThreshold, scheduleFrequency) {
+export async function updateAlertSettings(id, alertThreshold, scheduleFrequency, forecastAlertWeeks = 0, scheduleCron = '', scheduleTimezone = '') {
     return apiCall(`/brands/${id}/alerts`, {
         method: 'PUT',
         body: JSON.stringify({
             alert_threshold: alertThreshold,
+            forecast_alert_weeks: forecastAlertWeeks,
             schedule_frequency: scheduleFrequency,
+            schedule_cron: scheduleCron,
+            schedule_timezone: scheduleTimezone,
         }),
     });
 }
@@ -168,6 +171,11 @@ export async function removeCompetitor(brandId, competitorId) {
     });
 }
 
+// Untracked names AI responses list next to the brand; accept one with addCompetitor
+export async function getSuggestedCompetitors(brandId, limit = 5) {
+    return apiCall(`/brands/${brandId}/competitors/suggestions?limit=${limit}`);
+}
+
 // ============================================
 // Alias APIs
 // ============================================
@@ -278,6 +286,41 @@ export async function getDashboardData(brandId) {
     return apiCall(`/metrics/dashboard?brand_id=${brandId}`);
 }
 
+// Drill-down: brand metrics per prompt category, per prompt, and a prompt's responses
+export async function getCategoryDrilldown(brandId, days = 30) {
+    return apiCall(`/brands/${brandId}/drilldown/categories?days=${days}`);
+}
+
+export async function getPromptDrilldown(brandId, category = '', days = 30) {
+    return apiCall(`/brands/${brandId}/drilldown/prompts?days=${days}&category=${encodeURIComponent(category)}`);
+}
+
+// Timeline annotations (marketing events on the visibility chart)
+export async function getAnnotations(brandId) {
+    return apiCall(`/brands/${brandId}/annotations`);
+}
+
+export async function createAnnotation(brandId, annotation) {
+    return apiCall(`/brands/${brandId}/annotations`, {
+        method: 'POST',
+        body: JSON.stringify(annotation),
+    });
+}
+
+export async function deleteAnnotation(brandId, annotationId) {
+    return apiCall(`/brands/${brandId}/annotations/${annotationId}`, {
+        method: 'DELETE',
+    });
+}
+
+export async function getAnnotationImpact(brandId, windowDays = 14) {
+    return apiCall(`/brands/${brandId}/annotations/impact?window=${windowDays}`);
+}
+
+export async function getPromptResponses(brandId, promptId) {
+    return apiCall(`/brands/${brandId}/drilldown/prompts/${promptId}/responses`);
+}
+
 // ============================================
 // Export APIs
 // ============================================
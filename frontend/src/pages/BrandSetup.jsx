@@ -19,6 +19,12 @@ export default function BrandSetup() {
     const [editForm, setEditForm] = useState({ name: '', industry: '' })
     const [deleteModalBrand, setDeleteModalBrand] = useState(null)
 
+    // Suggested competitors per brand ID, discovered from stored AI responses.
+    // Loaded on request for one brand at a time, since each scan reads all its responses.
+    const [suggestions, setSuggestions] = useState({})
+    const [loadingSuggestions, setLoadingSuggestions] = useState(null)
+    const [addingSuggestion, setAddingSuggestion] = useState(null)
+
     // Fetch brands on mount
     useEffect(() => {
         fetchBrands()
@@ -46,6 +52,38 @@ export default function BrandSetup() {
         }
     }
 
+    const fetchSuggestions = async (brandId) => {
+        setLoadingSuggestions(brandId)
+        try {
+            const data = await api.getSuggestedCompetitors(brandId)
+            setSuggestions(prev => ({ ...prev, [brandId]: data.suggestions || [] }))
+        } catch (err) {
+            console.log('Error fetching competitor suggestions:', err)
+        } finally {
+            setLoadingSuggestions(null)
+        }
+    }
+
+    // One-click add of a suggested competitor
+    const addSuggestedCompetitor = async (brandId, name) => {
+        setAddingSuggestion(`${brandId}:${name}`)
+        try {
+            const competitor = await api.addCompetitor(brandId, name)
+            setBrands(prev => prev.map(b => b.id === brandId
+                ? { ...b, competitors: [...(b.competitors || []), competitor] }
+                : b))
+            setSuggestions(prev => ({
+                ...prev,
+                [brandId]: (prev[brandId] || []).filter(s => s.name !== name),
+            }))
+        } catch (err) {
+            console.error('Error adding competitor:', err)
+            setError(err.message || 'Failed to add competitor')
+        } finally {
+            setAddingSuggestion(null)
+        }
+    }
+
     const handleSubmit = async (e) => {
         e.preventDefault()
         setSaving(true)
@@ -268,6 +306,38 @@ export default function BrandSetup() {
                                 )}
                             </div>
                         </div>
+
+                        {/* Suggested Competitors */}
+                        {!suggestions[brand.id] && (
+                            <button
+                                onClick={() => fetchSuggestions(brand.id)}
+                                disabled={loadingSuggestions === brand.id}
+                                className="mt-4 text-sm text-blue-400 hover:text-blue-300 transition-colors"
+                            >
+                                {loadingSuggestions === brand.id ? 'Finding competitors...' : '💡 Suggest competitors'}
+                            </button>
+                        )}
+                        {suggestions[brand.id] && (
+                            <div className="mt-4">
+                                <p className="text-sm font-medium text-[var(--text-muted)] mb-2">Suggested competitors</p>
+                                {suggestions[brand.id].length === 0 && (
+                                    <span className="text-[var(--text-muted)] text-sm">No new competitors found in AI responses</span>
+                                )}
+                                <div className="flex flex-wrap gap-2">
+                                    {suggestions[brand.id].map((s) => (
+                                        <button
+                                            key={s.name}
+                                            onClick={() => addSuggestedCompetitor(brand.id, s.name)}
+                                            disabled={addingSuggestion === `${brand.id}:${s.name}`}
+                                            className="badge badge-surface hover:opacity-80 transition-opacity"
+                                            title={`Listed in ${s.responses} responses, recommended in ${Math.round(s.recommendation_rate * 100)}%`}
+                                        >
+                                            + {s.name}
+                                        </button>
+                                    ))}
+                                </div>
+                            </div>
+                        )}
                     </div>
                 ))}
             </div>
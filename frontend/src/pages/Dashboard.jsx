@@ -1,6 +1,6 @@
-import { useState, useEffect } from 'react'
+import { useState, useEffect, Fragment } from 'react'
 import { useNavigate, useSearchParams } from 'react-router-dom'
-import { LineChart, Line, BarChart, Bar, XAxis, YAxis, CartesianGrid, Tooltip, ResponsiveContainer, PieChart, Pie, Cell } from 'recharts'
+import { LineChart, Line, BarChart, Bar, XAxis, YAxis, CartesianGrid, Tooltip, ResponsiveContainer, PieChart, Pie, Cell, ReferenceLine } from 'recharts'
 import * as api from '../api/client'
 
 const demoTrendData = [
@@ -53,7 +53,7 @@ const tooltipStyle = {
     labelStyle: { color: '#f1f5f9' }
 }
 
-function KPICard({ title, value, subtitle, trend, icon, loading }) {
+function KPICard({ title, value, subtitle, trend, change, icon, loading }) {
     return (
         <div className="card card-hover">
             <div className="flex items-start justify-between">
@@ -78,6 +78,159 @@ function KPICard({ title, value, subtitle, trend, icon, loading }) {
                     <span>{Math.abs(trend)}% from last week</span>
                 </div>
             )}
+            {change && (
+                change.significant ? (
+                    <div className={`mt-4 flex items-center gap-1 text-sm ${change.delta > 0 ? 'text-[var(--success)]' : 'text-[var(--error)]'}`}>
+                        <span>{change.delta > 0 ? '↑' : '↓'}</span>
+                        <span>{Math.abs(change.delta).toFixed(1)} pts since last run</span>
+                    </div>
+                ) : (
+                    <div className="mt-4 flex items-center gap-1 text-sm text-[var(--text-muted)]" title={`p = ${change.p_value.toFixed(2)} (${change.from_responses} vs ${change.to_responses} responses)`}>
+                        <span>→</span>
+                        <span>{change.delta > 0 ? '+' : ''}{change.delta.toFixed(1)} pts, {change.verdict === 'insufficient_data' ? 'not enough data' : 'not significant'}</span>
+                    </div>
+                )
+            )}
+        </div>
+    )
+}
+
+// Places each annotation on the first trend point on or after its day, the first run that can reflect it
+const annotationMarkers = (annotations, trend) => annotations.flatMap(a => {
+    const day = new Date(a.date).getTime()
+    const point = trend.find(t => t.time >= day)
+    return point ? [{ id: a.id, x: point.date, label: a.title.length > 18 ? `${a.title.slice(0, 17)}…` : a.title }] : []
+})
+
+// Rate (0-1) as a whole percent
+const formatRate = (rate) => `${Math.round(rate * 100)}%`
+
+// Small mention-rate trend line for a drill-down row
+function MentionRateSparkline({ trend }) {
+    if (!trend || trend.length < 2) {
+        return <span className="text-xs text-[var(--text-muted)]">—</span>
+    }
+    return (
+        <ResponsiveContainer width={80} height={24}>
+            <LineChart data={trend.map(p => ({ rate: p.mention_rate }))}>
+                <YAxis hide domain={[0, 1]} />
+                <Line type="monotone" dataKey="rate" stroke="var(--primary)" strokeWidth={2} dot={false} />
+            </LineChart>
+        </ResponsiveContainer>
+    )
+}
+
+// Metrics of one category or prompt: mention rate, average rank, recommendation rate, sentiment
+function DrilldownCells({ point }) {
+    return (
+        <>
+            <td className="py-2 px-2 text-right">{formatRate(point.mention_rate)}</td>
+            <td className="py-2 px-2 text-right">{point.avg_rank > 0 ? `#${point.avg_rank.toFixed(1)}` : '—'}</td>
+            <td className="py-2 px-2 text-right">{formatRate(point.recommendation_rate)}</td>
+            <td className="py-2 px-2 text-right">{point.mentions > 0 ? point.avg_sentiment.toFixed(1) : '—'}</td>
+        </>
+    )
+}
+
+// Per-category and per-prompt drill-down: a category lists its prompts, a prompt its latest responses
+function PromptDrilldown({ brandId }) {
+    const [categories, setCategories] = useState([])
+    const [openCategory, setOpenCategory] = useState(null)
+    const [prompts, setPrompts] = useState([])
+    const [openPrompt, setOpenPrompt] = useState(null)
+    const [responses, setResponses] = useState([])
+
+    useEffect(() => {
+        if (!brandId) return
+        setOpenCategory(null)
+        setOpenPrompt(null)
+        api.getCategoryDrilldown(brandId)
+            .then(data => setCategories(data.categories || []))
+            .catch(err => console.log('Error fetching category drill-down:', err))
+    }, [brandId])
+
+    const toggleCategory = async (category) => {
+        setOpenPrompt(null)
+        if (openCategory === category) {
+            setOpenCategory(null)
+            return
+        }
+        setOpenCategory(category)
+        try {
+            const data = await api.getPromptDrilldown(brandId, category)
+            setPrompts(data.prompts || [])
+        } catch (err) {
+            console.log('Error fetching prompt drill-down:', err)
+        }
+    }
+
+    const togglePrompt = async (promptId) => {
+        if (openPrompt === promptId) {
+            setOpenPrompt(null)
+            return
+        }
+        setOpenPrompt(promptId)
+        try {
+            const data = await api.getPromptResponses(brandId, promptId)
+            setResponses(data.responses || [])
+        } catch (err) {
+            console.log('Error fetching prompt responses:', err)
+        }
+    }
+
+    if (categories.length === 0) return null
+
+    return (
+        <div className="card">
+            <h3 className="text-base font-semibold text-[var(--text)] mb-3">Prompt Categories</h3>
+            <table className="w-full text-sm text-[var(--text)]">
+                <thead>
+                    <tr className="text-[var(--text-muted)] text-xs">
+                        <th className="py-2 px-2 text-left">Category / Prompt</th>
+                        <th className="py-2 px-2 text-right">Mention Rate</th>
+                        <th className="py-2 px-2 text-right">Avg Rank</th>
+                        <th className="py-2 px-2 text-right">Recommended</th>
+                        <th className="py-2 px-2 text-right">Sentiment</th>
+                        <th className="py-2 px-2">Trend</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {categories.map(cat => (
+                        <Fragment key={cat.category}>
+                            <tr onClick={() => toggleCategory(cat.category)} className="cursor-pointer border-t border-[var(--surface-light)] hover:bg-[var(--surface-light)]">
+                                <td className="py-2 px-2 font-medium">{openCategory === cat.category ? '▾' : '▸'} {cat.category} <span className="text-xs text-[var(--text-muted)]">({cat.prompts})</span></td>
+                                <DrilldownCells point={cat.latest} />
+                                <td className="py-2 px-2"><MentionRateSparkline trend={cat.trend} /></td>
+                            </tr>
+                            {openCategory === cat.category && prompts.map(p => (
+                                <Fragment key={p.prompt_id}>
+                                    <tr onClick={() => togglePrompt(p.prompt_id)} className="cursor-pointer text-[var(--text-muted)] hover:bg-[var(--surface-light)]">
+                                        <td className="py-2 px-2 pl-6 truncate max-w-xs" title={p.prompt}>{p.prompt || `Prompt #${p.prompt_id}`}</td>
+                                        <DrilldownCells point={p.latest} />
+                                        <td className="py-2 px-2"><MentionRateSparkline trend={p.trend} /></td>
+                                    </tr>
+                                    {openPrompt === p.prompt_id && (
+                                        <tr>
+                                            <td colSpan={6} className="px-6 pb-3">
+                                                {responses.length === 0 ? (
+                                                    <p className="text-xs text-[var(--text-muted)]">No stored responses (only the latest run is kept)</p>
+                                                ) : responses.map(r => (
+                                                    <div key={r.id} className="mt-2 p-3 rounded-lg bg-[var(--background)] border border-[var(--surface-light)]">
+                                                        <p className="text-xs text-[var(--text-muted)] mb-1">
+                                                            {r.model_name} · {(r.mentions || []).filter(m => m.entity_type === 'brand').length} brand mentions
+                                                        </p>
+                                                        <p className="text-xs whitespace-pre-wrap max-h-40 overflow-y-auto">{r.response_text}</p>
+                                                    </div>
+                                                ))}
+                                            </td>
+                                        </tr>
+                                    )}
+                                </Fragment>
+                            ))}
+                        </Fragment>
+                    ))}
+                </tbody>
+            </table>
         </div>
     )
 }
@@ -88,6 +241,7 @@ export default function Dashboard() {
     const [loading, setLoading] = useState(true)
     const [dashboardData, setDashboardData] = useState(null)
     const [trendData, setTrendData] = useState(demoTrendData)
+    const [annotations, setAnnotations] = useState([])
     const [citationShareData, setCitationShareData] = useState([])
     const [competitorData, setCompetitorData] = useState([])
     const [error, setError] = useState(null)
@@ -107,6 +261,10 @@ export default function Dashboard() {
     // Alert settings state (controlled inputs)
     const [alertThreshold, setAlertThreshold] = useState(0)
     const [scheduleFrequency, setScheduleFrequency] = useState('disabled')
+    const [forecastAlertWeeks, setForecastAlertWeeks] = useState(0)
+    const [scheduleCron, setScheduleCron] = useState('')
+    const [scheduleTimezone, setScheduleTimezone] = useState(Intl.DateTimeFormat().resolvedOptions().timeZone || 'UTC')
+    const [nextRunAt, setNextRunAt] = useState(null)
 
     // Fetch brands on mount
     useEffect(() => {
@@ -144,6 +302,10 @@ export default function Dashboard() {
         if (selectedBrand) {
             setAlertThreshold(selectedBrand.alert_threshold || 0)
             setScheduleFrequency(selectedBrand.schedule_frequency || 'disabled')
+            setForecastAlertWeeks(selectedBrand.forecast_alert_weeks || 0)
+            setScheduleCron(selectedBrand.schedule_cron || '')
+            if (selectedBrand.schedule_timezone) setScheduleTimezone(selectedBrand.schedule_timezone)
+            setNextRunAt(selectedBrand.next_run_at || null)
 
             // Load saved competitor insights from brand if available
             if (selectedBrand.competitor_insights) {
@@ -206,12 +368,14 @@ export default function Dashboard() {
                     if (data.trends && data.trends.length > 0) {
                         setTrendData(data.trends.map(t => ({
                             date: new Date(t.snapshot_date).toLocaleDateString('en-US', { month: 'short', day: 'numeric' }),
+                            time: new Date(t.snapshot_date).getTime(),
                             visibility: t.visibility_score,
                             mentions: t.mention_count,
                         })))
                     } else {
                         setTrendData(demoTrendData)
                     }
+                    setAnnotations(data.annotations || [])
 
                     // Use API data for charts, or generate brand-specific fallback
                     if (data.citation_breakdown && data.citation_breakdown.length > 0) {
@@ -368,6 +532,7 @@ export default function Dashboard() {
                             title="Visibility Score"
                             value={dashboardData?.visibility_score?.toFixed(0) || '0'}
                             subtitle="out of 100"
+                            change={dashboardData?.score_change}
                             icon="📊"
                             loading={loading}
                         />
@@ -648,6 +813,15 @@ export default function Dashboard() {
                                                 dot={{ fill: 'var(--primary)', strokeWidth: 2, r: 4 }}
                                                 activeDot={{ r: 6, fill: 'var(--primary-light)' }}
                                             />
+                                            {annotationMarkers(annotations, trendData).map(marker => (
+                                                <ReferenceLine
+                                                    key={marker.id}
+                                                    x={marker.x}
+                                                    stroke="var(--warning)"
+                                                    strokeDasharray="4 4"
+                                                    label={{ value: marker.label, position: 'insideTopLeft', fill: 'var(--warning)', fontSize: 10 }}
+                                                />
+                                            ))}
                                         </LineChart>
                                     </ResponsiveContainer>
                                 </div>
@@ -700,6 +874,9 @@ export default function Dashboard() {
                                     </BarChart>
                                 </ResponsiveContainer>
                             </div>
+
+                            {/* Prompt Category / Prompt Drill-down */}
+                            {hasRealData && <PromptDrilldown brandId={selectedBrandId} />}
                         </div>
                         {/* End Left Column */}
 
@@ -801,6 +978,20 @@ export default function Dashboard() {
                                         </div>
                                     </div>
 
+                                    <div>
+                                        <label className="label">Forecast Alert</label>
+                                        <select
+                                            value={forecastAlertWeeks}
+                                            onChange={(e) => setForecastAlertWeeks(Number(e.target.value))}
+                                            className="select w-full"
+                                        >
+                                            <option value={0}>Off</option>
+                                            <option value={2}>Projected below threshold within 2 weeks</option>
+                                            <option value={4}>Projected below threshold within 4 weeks</option>
+                                            <option value={8}>Projected below threshold within 8 weeks</option>
+                                        </select>
+                                    </div>
+
                                     <div>
                                         <label className="label">Auto-Run</label>
                                         <select
@@ -811,16 +1002,43 @@ export default function Dashboard() {
                                             <option value="disabled">Disabled</option>
                                             <option value="daily">Daily</option>
                                             <option value="weekly">Weekly</option>
+                                            <option value="cron">Custom (cron)</option>
                                         </select>
+                                        {scheduleFrequency === 'cron' && (
+                                            <div className="mt-2 space-y-2">
+                                                <input
+                                                    type="text"
+                                                    value={scheduleCron}
+                                                    onChange={(e) => setScheduleCron(e.target.value)}
+                                                    placeholder="0 6 * * MON"
+                                                    className="input w-full font-mono"
+                                                />
+                                                <input
+                                                    type="text"
+                                                    value={scheduleTimezone}
+                                                    onChange={(e) => setScheduleTimezone(e.target.value)}
+                                                    placeholder="Europe/Berlin"
+                                                    className="input w-full"
+                                                />
+                                            </div>
+                                        )}
+                                        {nextRunAt && scheduleFrequency !== 'disabled' && (
+                                            <p className="text-xs text-[var(--text-muted)] mt-1">
+                                                Next run: {new Date(nextRunAt).toLocaleString()}
+                                            </p>
+                                        )}
                                     </div>
 
                                     <button
                                         onClick={async () => {
                                             try {
-                                                await api.updateAlertSettings(selectedBrandId, alertThreshold, scheduleFrequency)
+                                                const result = await api.updateAlertSettings(selectedBrandId, alertThreshold, scheduleFrequency, forecastAlertWeeks,
+                                                    scheduleFrequency === 'cron' ? scheduleCron : '', scheduleFrequency === 'cron' ? scheduleTimezone : '')
+                                                setNextRunAt(result.next_run_at || null)
                                                 alert('Settings saved!')
                                             } catch (err) {
                                                 console.error('Failed to save:', err)
+                                                if (err.details) alert(`${err.error}: ${err.details}`)
                                             }
                                         }}
                                         className="btn w-full bg-[var(--info)]/20 text-[var(--info)] hover:bg-[var(--info)]/30"